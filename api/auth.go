@@ -51,7 +51,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -69,7 +69,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -80,7 +80,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -95,14 +95,14 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	// Get Permissions
 	permissions, err := server.store.GetPermissionByUserId(ctx, user.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -141,7 +141,7 @@ func (server *Server) forgotPassword(ctx *gin.Context) {
 
 	config, err := util.LoadConfig(".")
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -152,7 +152,7 @@ func (server *Server) forgotPassword(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -165,7 +165,7 @@ func (server *Server) forgotPassword(ctx *gin.Context) {
 	})
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -208,7 +208,7 @@ func (server *Server) forgotPassword(ctx *gin.Context) {
 
 	err = sender.SendEmail(subject, content, to, nil, nil, nil)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -246,7 +246,7 @@ func (server *Server) resetPasswordDetail(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -259,7 +259,7 @@ func (server *Server) resetPasswordDetail(ctx *gin.Context) {
 	// Get User by ID
 	user, err := server.store.GetUser(ctx, resetPassword.UserID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -296,7 +296,7 @@ func (server *Server) resetPassword(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -309,14 +309,14 @@ func (server *Server) resetPassword(ctx *gin.Context) {
 	// Get User by ID
 	user, err := server.store.GetUser(ctx, resetPassword.UserID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	// Hash Password
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -326,14 +326,14 @@ func (server *Server) resetPassword(ctx *gin.Context) {
 		HashedPassword: hashedPassword,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	// Set Used for Reset Password
 	err = server.store.UseResetPassword(ctx, req.Token)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -1,39 +1,404 @@
 package api
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"blog-go-api/constants"
+	"blog-go-api/content"
 	db "blog-go-api/db/sqlc"
+	"blog-go-api/search"
+	"blog-go-api/token"
 	"blog-go-api/util"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
 )
 
+// visibleBlogStatuses returns the statuses the caller is allowed to read.
+// Anonymous readers only see published posts while holders of view_blog or review_blog see every status,
+// optionally narrowed down to the requested one.
+func visibleBlogStatuses(server Server, ctx *gin.Context, status string) ([]string, error) {
+	statuses := []string{constants.BlogStatusPublished}
+
+	payload := getOptionalAuthPayload(server, ctx)
+	if payload != nil {
+		canViewAll, err := hasPermission(server, ctx, payload.UserId, constants.PermissionViewBlog.Code, constants.PermissionReviewBlog.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		if canViewAll {
+			statuses = constants.BlogStatuses
+		}
+	}
+
+	if status == "" {
+		return statuses, nil
+	}
+
+	for _, s := range statuses {
+		if s == status {
+			return []string{status}, nil
+		}
+	}
+
+	return []string{}, nil
+}
+
+// resolveBlogSchedule validates the publish_at of a create or update request.
+// publish_at records the planned launch time of a post; once the post is approved and
+// scheduled, the scheduler publishes it when that time arrives.
+func resolveBlogSchedule(status string, publishAt *time.Time, currentPublishAt pgtype.Timestamptz) (pgtype.Timestamptz, error) {
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		return pgtype.Timestamptz{}, errors.New("publish_at must be in the future")
+	}
+
+	if status == constants.BlogStatusScheduled {
+		if publishAt == nil && (!currentPublishAt.Valid || !currentPublishAt.Time.After(time.Now())) {
+			return pgtype.Timestamptz{}, errors.New("publish_at in the future is required for scheduled posts")
+		}
+	}
+
+	if publishAt == nil {
+		return pgtype.Timestamptz{}, nil
+	}
+
+	return pgtype.Timestamptz{Time: *publishAt, Valid: true}, nil
+}
+
+// blogStatusTransitions lists the status changes editors may make through UpdateBlog.
+// Submitting a post for review, approving it or sending it back goes through the review endpoints.
+var blogStatusTransitions = map[string][]string{
+	constants.BlogStatusDraft:     {constants.BlogStatusArchived},
+	constants.BlogStatusInReview:  {constants.BlogStatusDraft},
+	constants.BlogStatusApproved:  {constants.BlogStatusDraft, constants.BlogStatusScheduled, constants.BlogStatusPublished},
+	constants.BlogStatusScheduled: {constants.BlogStatusDraft, constants.BlogStatusApproved, constants.BlogStatusPublished},
+	constants.BlogStatusPublished: {constants.BlogStatusDraft, constants.BlogStatusArchived},
+	constants.BlogStatusArchived:  {constants.BlogStatusDraft},
+}
+
+// validateBlogStatusTransition checks that an editor may move a post from currentStatus to newStatus
+func validateBlogStatusTransition(currentStatus string, newStatus string) error {
+	if newStatus == "" || newStatus == currentStatus {
+		return nil
+	}
+
+	for _, s := range blogStatusTransitions[currentStatus] {
+		if s == newStatus {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot change status from %s to %s", currentStatus, newStatus)
+}
+
+// isLiveBlogStatus reports whether a status makes a post public now or at its publish_at
+func isLiveBlogStatus(status string) bool {
+	return status == constants.BlogStatusPublished || status == constants.BlogStatusScheduled
+}
+
+// blogNotFound writes 410 Gone for a soft-deleted blog and 404 for one that never existed,
+// so crawlers can drop deleted posts instead of retrying them
+func blogNotFound(server Server, ctx *gin.Context, blogID int64) {
+	deleted, err := server.store.ExistsDeletedBlogById(ctx, blogID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	if deleted {
+		ctx.JSON(http.StatusGone, errorResponse(errBlogGone))
+		return
+	}
+	ctx.JSON(http.StatusNotFound, errorResponse(errBlogNotFound))
+}
+
+// authorizeBlogEdit loads the blog and checks that the caller may modify it.
+// Holders of edit_blog may modify any post, holders of edit_own_blog only the posts they authored.
+// On failure the error response has already been written and ok is false.
+func authorizeBlogEdit(server Server, ctx *gin.Context, blogID int64) (blog db.GetBlogByIdRow, ok bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	blog, err := server.store.GetBlogById(ctx, blogID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			blogNotFound(server, ctx, blogID)
+			return blog, false
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return blog, false
+	}
+
+	if blog.AuthorID == authPayload.UserId {
+		return blog, true
+	}
+
+	canEditAny, err := hasPermission(server, ctx, authPayload.UserId, constants.PermissionEditBlog.Code)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return blog, false
+	}
+
+	if !canEditAny {
+		err := errors.New("permission denied: you can only edit your own blogs")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return blog, false
+	}
+
+	return blog, true
+}
+
+// authorizeBlogPublish checks that the caller holds publish_blog when a status change
+// puts a post live or takes a live post down. On failure the error response has already been written.
+func authorizeBlogPublish(server Server, ctx *gin.Context, currentStatus string, newStatus string) bool {
+	if newStatus == "" || newStatus == currentStatus {
+		return true
+	}
+
+	if !isLiveBlogStatus(newStatus) && !isLiveBlogStatus(currentStatus) {
+		return true
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	canPublish, err := hasPermission(server, ctx, authPayload.UserId, constants.PermissionPublishBlog.Code)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return false
+	}
+
+	if !canPublish {
+		err := errors.New("permission denied: publishing requires the publish_blog permission")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// blogStatusAfterContentChange returns the status a blog is saved with when its content changes, status being the
+// requested one or empty to keep the current. An approved or scheduled post goes back to review. A published post
+// stays live, so only holders of publish_blog may change it, anyone else would put unreviewed content on the page.
+// On failure the error response has already been written.
+func blogStatusAfterContentChange(server Server, ctx *gin.Context, currentStatus string, status string) (string, bool) {
+	if status == constants.BlogStatusDraft {
+		return status, true
+	}
+
+	switch currentStatus {
+	case constants.BlogStatusApproved, constants.BlogStatusScheduled:
+		return constants.BlogStatusInReview, true
+	case constants.BlogStatusPublished:
+		if status != "" && status != constants.BlogStatusPublished {
+			return status, true
+		}
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		canPublish, err := hasPermission(server, ctx, authPayload.UserId, constants.PermissionPublishBlog.Code)
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return "", false
+		}
+
+		if !canPublish {
+			err := errors.New("permission denied: changing a published blog requires the publish_blog permission, unpublish it to draft first")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return "", false
+		}
+	}
+
+	return status, true
+}
+
+// blogFacetLimit caps how many tags are returned as facets of a blog listing
+const blogFacetLimit = 50
+
+// blogSearchInput returns the search text of a blog listing.
+// The older name and title filters are still accepted and searched the same way when q is not given.
+func blogSearchInput(q string, name string, title string) string {
+	if q != "" {
+		return q
+	}
+	if name != "" {
+		return name
+	}
+	return title
+}
+
+// blogSearchSuggestion returns the title or tag name closest to the search text,
+// or an empty string when nothing is similar enough to suggest.
+func blogSearchSuggestion(server Server, ctx *gin.Context, fuzzy string, statuses []string) (string, error) {
+	if fuzzy == "" {
+		return "", nil
+	}
+
+	suggestion, err := server.store.GetSearchSuggestion(ctx, db.GetSearchSuggestionParams{
+		Query:    fuzzy,
+		Statuses: statuses,
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return "", nil
+	}
+	return suggestion, err
+}
+
+// blogTagFilter normalizes the tag filter of a blog listing.
+// Tags may be repeated (tag=a&tag=b) or comma separated (tag=a,b) and are matched case-insensitively.
+func blogTagFilter(values []string) []string {
+	// Never nil: a NULL array would filter out every blog
+	tags := []string{}
+	seen := map[string]bool{}
+
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// blogDateFilter parses a date range bound given as YYYY-MM-DD in the server time zone or as RFC 3339.
+// A plain date used as the end of a range covers the whole day.
+func blogDateFilter(value string, endOfDay bool) (pgtype.Timestamptz, error) {
+	if value == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return pgtype.Timestamptz{Time: t, Valid: true}, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", value)
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+// listBlog runs a blog listing and counts every blog matching its filter
+func listBlog(server Server, ctx *gin.Context, arg db.ListBlogParams) ([]db.ListBlogRow, int64, error) {
+	blogs, err := server.store.ListBlog(ctx, arg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := server.store.CountBlog(ctx, blogCountParams(arg))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return blogs, count, nil
+}
+
+// blogCountParams counts every blog matching the filter of a listing
+func blogCountParams(arg db.ListBlogParams) db.CountBlogParams {
+	return db.CountBlogParams{
+		Query:         arg.Query,
+		Fuzzy:         arg.Fuzzy,
+		Statuses:      arg.Statuses,
+		AuthorCode:    arg.AuthorCode,
+		Tags:          arg.Tags,
+		MatchAllTags:  arg.MatchAllTags,
+		CreatedFrom:   arg.CreatedFrom,
+		CreatedTo:     arg.CreatedTo,
+		PublishedFrom: arg.PublishedFrom,
+		PublishedTo:   arg.PublishedTo,
+	}
+}
+
+// blogTagFacets counts the blogs matching the filter of a listing per tag
+func blogTagFacets(server Server, ctx *gin.Context, arg db.ListBlogParams) ([]db.GetBlogTagFacetRow, error) {
+	return server.store.GetBlogTagFacet(ctx, db.GetBlogTagFacetParams{
+		Query:         arg.Query,
+		Fuzzy:         arg.Fuzzy,
+		Statuses:      arg.Statuses,
+		AuthorCode:    arg.AuthorCode,
+		Tags:          arg.Tags,
+		MatchAllTags:  arg.MatchAllTags,
+		CreatedFrom:   arg.CreatedFrom,
+		CreatedTo:     arg.CreatedTo,
+		PublishedFrom: arg.PublishedFrom,
+		PublishedTo:   arg.PublishedTo,
+		LimitRows:     blogFacetLimit,
+	})
+}
+
 type GetAllBlogRequest struct {
-	Name     string `form:"name"`
-	PageID   int32  `form:"page_id" binding:"required,min=1"`
-	PageSize int32  `form:"page_size" binding:"required,min=1,max=10"`
+	Q             string   `form:"q"`
+	Name          string   `form:"name"`
+	Title         string   `form:"title"`
+	Tags          []string `form:"tag"`
+	TagMode       string   `form:"tag_mode" binding:"omitempty,oneof=any all"`
+	Author        string   `form:"author"`
+	Status        string   `form:"status" binding:"omitempty,oneof=draft in_review approved scheduled published archived"`
+	CreatedFrom   string   `form:"created_from"`
+	CreatedTo     string   `form:"created_to"`
+	PublishedFrom string   `form:"published_from"`
+	PublishedTo   string   `form:"published_to"`
+	Sort          string   `form:"sort" binding:"omitempty,oneof=relevance newest oldest title most_viewed"`
+	PageID        int32    `form:"page_id" binding:"omitempty,min=1"`
+	PageSize      int32    `form:"page_size" binding:"required,min=1,max=50"`
+	Cursor        string   `form:"cursor"`
+	WithCount     bool     `form:"with_count"`
+	Fields        string   `form:"fields"`
 }
 
 type GetAllBlogResponse struct {
-	db.GetAllBlogRow
+	db.ListBlogRow
 	BlogTags []db.GetBlogTagByBlogIdRow `json:"blog_tags"`
 }
 
+type GetAllBlogFacets struct {
+	Tags []db.GetBlogTagFacetRow `json:"tags"`
+}
+
 // GetAllBlog godoc
 //
 //	@Summary		Get All Blog
-//	@Description	Get All Blog
+//	@Description	List blogs with search, filters and sorting. facets.tags counts the matching blogs per tag.
+//	@Description	Send cursor instead of page_id to page by publish date with an opaque cursor, empty for the first page: the response carries
+//	@Description	next_cursor (empty on the last page) and total only when with_count is set, see jsonResponseWithCursorFacets.
+//	@Description	Posts that were never published are ordered by their creation date.
 //	@Tags			Blog
 //	@Accept			json
 //	@Produce		json
-//	@Param			name		query		string	false	"Blog Name"
-//	@Param			page_id		query		int		true	"Page ID"
-//	@Param			page_size	query		int		true	"Page Size"
-//	@Success		200			{object}	jsonResponseWithPaginate
+//	@Param			q				query		string		false	"Search title and content: \"exact phrase\", prefix*, -exclude, a OR b"
+//	@Param			name			query		string		false	"Blog Name (deprecated, use q)"
+//	@Param			tag				query		[]string	false	"Tag Names, repeated or comma separated"	collectionFormat(multi)
+//	@Param			tag_mode		query		string		false	"Match any (default) or all of the tags"	Enums(any, all)
+//	@Param			author			query		string		false	"Author Code"
+//	@Param			status			query		string		false	"Blog Status (requires view_blog for anything but published)"
+//	@Param			created_from	query		string		false	"Created on or after (YYYY-MM-DD or RFC 3339)"
+//	@Param			created_to		query		string		false	"Created on or before (YYYY-MM-DD or RFC 3339)"
+//	@Param			published_from	query		string		false	"Published on or after (YYYY-MM-DD or RFC 3339)"
+//	@Param			published_to	query		string		false	"Published on or before (YYYY-MM-DD or RFC 3339)"
+//	@Param			sort			query		string		false	"Sort order, relevance by default when searching and newest otherwise"	Enums(relevance, newest, oldest, title, most_viewed)
+//	@Param			page_id			query		int			false	"Page ID, required unless cursor is sent"
+//	@Param			page_size		query		int			true	"Page Size (max 50)"
+//	@Param			cursor			query		string		false	"next_cursor of the previous page, empty for the first page, ordered by publish date"
+//	@Param			with_count		query		bool		false	"Include total when paging with cursor"
+//	@Param			fields			query		string		false	"Comma separated fields to return per blog, e.g. id,title,url,excerpt,reading_time_minutes. Every field but content by default, content is only sent when listed"
+//	@Success		200				{object}	jsonResponseWithFacets
 //	@Router			/api/blog [get]
 func (server *Server) GetAllBlog(ctx *gin.Context) {
 
@@ -43,135 +408,335 @@ func (server *Server) GetAllBlog(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.GetAllBlogParams{
-		Lower:  req.Name,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	// The full body is only loaded for clients that ask for it, listings show the excerpt
+	// An empty cursor asks for the first page by cursor, the parameter has to be present to page that way
+	if _, cursor := ctx.GetQuery("cursor"); req.PageID == 0 && !cursor {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errBlogPageMissing))
+		return
 	}
 
-	var getAllBlogResponse []GetAllBlogResponse
+	fields, err := parseFields[GetAllBlogResponse](req.Fields, "content")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-	blogs, err := server.store.GetAllBlog(ctx, arg)
+	statuses, err := visibleBlogStatuses(*server, ctx, req.Status)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	for _, blog := range blogs {
-		blogTags, err := server.store.GetBlogTagByBlogId(ctx, blog.ID)
+	arg := db.ListBlogParams{
+		Statuses:     statuses,
+		AuthorCode:   req.Author,
+		Tags:         blogTagFilter(req.Tags),
+		MatchAllTags: req.TagMode == "all",
+		Sort:         req.Sort,
+		WithContent:  slices.Contains(fields, "content"),
+		LimitRows:    req.PageSize,
+	}
+
+	dateFilters := []struct {
+		value    string
+		endOfDay bool
+		target   *pgtype.Timestamptz
+	}{
+		{req.CreatedFrom, false, &arg.CreatedFrom},
+		{req.CreatedTo, true, &arg.CreatedTo},
+		{req.PublishedFrom, false, &arg.PublishedFrom},
+		{req.PublishedTo, true, &arg.PublishedTo},
+	}
+	for _, filter := range dateFilters {
+		*filter.target, err = blogDateFilter(filter.value, filter.endOfDay)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
 			return
 		}
+	}
 
-		getAllBlogResponse = append(getAllBlogResponse, GetAllBlogResponse{
-			GetAllBlogRow: blog,
-			BlogTags:      blogTags,
-		})
+	input := blogSearchInput(req.Q, req.Name, req.Title)
+	arg.Query = search.BuildQuery(input)
+
+	// Without page_id the listing is paged with a cursor instead of an offset
+	if req.PageID == 0 {
+		server.listBlogByCursor(ctx, req, arg, input, fields)
+		return
+	}
+
+	arg.OffsetRows = (req.PageID - 1) * req.PageSize
+
+	if arg.Sort == "" {
+		arg.Sort = "newest"
+		if arg.Query != "" {
+			arg.Sort = "relevance"
+		}
 	}
 
-	count, err := server.store.CountAllBlog(ctx, req.Name)
+	blogs, count, err := listBlog(*server, ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	payload := jsonResponseWithPaginate{
-		jsonResponse: jsonResponse{
-			Error:   false,
-			Message: "successfully",
-			Data:    getAllBlogResponse,
-		},
-		Total: count,
+	// Nothing matched the words exactly, retry with typo tolerant title matching
+	var didYouMean string
+	if count == 0 && arg.Query != "" {
+		arg.Query, arg.Fuzzy = "", search.FuzzyText(input)
+
+		blogs, count, err = listBlog(*server, ctx, arg)
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
+
+		didYouMean, err = blogSearchSuggestion(*server, ctx, arg.Fuzzy, statuses)
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
 	}
 
-	ctx.JSON(http.StatusOK, payload)
-}
+	facets, err := blogTagFacets(*server, ctx, arg)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
 
-type GetAllBlogWithTagRequest struct {
-	Title    string `form:"title"`
-	Tag      string `form:"tag"`
-	PageID   int32  `form:"page_id" binding:"required,min=1"`
-	PageSize int32  `form:"page_size" binding:"required,min=1,max=10"`
-}
+	getAllBlogResponse, err := blogListItems(*server, ctx, blogs)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
 
-type GetAllBlogWithTagResponse struct {
-	db.GetAllBlogWithTagRow
-	BlogTags []db.GetBlogTagByBlogIdRow `json:"blog_tags"`
-}
+	data, err := selectFields(getAllBlogResponse, fields)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
 
-// GetAllBlogWithTag godoc
-//
-//	@Summary		Get All Blog With Tag
-//	@Description	Get All Blog With Tag
-//	@Tags			Blog
-//	@Accept			json
-//	@Produce		json
-//	@Param			title		query		string	false	"Blog Title"
-//	@Param			tag			query		string	false	"Tag Name"
-//	@Param			page_id		query		int		true	"Page ID"
-//	@Param			page_size	query		int		true	"Page Size"
-//	@Success		200			{object}	jsonResponseWithPaginate
-//	@Router			/api/blog/tag [get]
-func (server *Server) GetAllBlogWithTag(ctx *gin.Context) {
+	payload := jsonResponseWithFacets{
+		jsonResponseWithSearch: jsonResponseWithSearch{
+			jsonResponseWithPaginate: jsonResponseWithPaginate{
+				jsonResponse: jsonResponse{
+					Error:   false,
+					Message: "successfully",
+					Data:    data,
+				},
+				Total: count,
+			},
+			DidYouMean: didYouMean,
+		},
+		Facets: GetAllBlogFacets{
+			Tags: facets,
+		},
+	}
 
-	var req GetAllBlogWithTagRequest
-	if err := ctx.ShouldBindQuery(&req); err != nil {
+	ctx.JSON(http.StatusOK, payload)
+}
+
+// listBlogByCursor serves a blog listing with keyset pagination on the publish date, or the creation date of posts
+// that were never published, and id. Pages stay stable while new posts are published because they always sort before the cursor.
+// It sees the same posts as the offset listing and falls back to typo tolerant matching the same way.
+func (server *Server) listBlogByCursor(ctx *gin.Context, req GetAllBlogRequest, arg db.ListBlogParams, input string, fields []string) {
+	if req.Sort != "" && req.Sort != "newest" {
+		err := fmt.Errorf("cursor pagination is ordered by publish date, use page_id to sort by %s", req.Sort)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	arg := db.GetAllBlogWithTagParams{
-		Lower:   req.Title,
-		Lower_2: req.Tag,
-		Limit:   req.PageSize,
-		Offset:  (req.PageID - 1) * req.PageSize,
+	var cursorListedAt pgtype.Timestamptz
+	var cursorID int64
+	if req.Cursor != "" {
+		listedAt, id, err := util.DecodeCursor(req.Cursor)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		cursorListedAt = pgtype.Timestamptz{Time: listedAt, Valid: true}
+		cursorID = id
 	}
 
-	var getAllBlogResponse []GetAllBlogWithTagResponse
+	listPage := func(arg db.ListBlogParams) ([]db.ListBlogByCursorRow, error) {
+		return server.store.ListBlogByCursor(ctx, db.ListBlogByCursorParams{
+			WithContent:    arg.WithContent,
+			Query:          arg.Query,
+			Fuzzy:          arg.Fuzzy,
+			Statuses:       arg.Statuses,
+			AuthorCode:     arg.AuthorCode,
+			Tags:           arg.Tags,
+			MatchAllTags:   arg.MatchAllTags,
+			CreatedFrom:    arg.CreatedFrom,
+			CreatedTo:      arg.CreatedTo,
+			PublishedFrom:  arg.PublishedFrom,
+			PublishedTo:    arg.PublishedTo,
+			CursorListedAt: cursorListedAt,
+			CursorID:       cursorID,
+			// One extra row tells whether there is a next page
+			LimitRows: arg.LimitRows + 1,
+		})
+	}
 
-	blogs, err := server.store.GetAllBlogWithTag(ctx, arg)
+	rows, err := listPage(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	for _, blog := range blogs {
-		blogTags, err := server.store.GetBlogTagByBlogId(ctx, blog.ID)
+	// An empty page is either past the last match or nothing matched the words exactly,
+	// only the latter retries with typo tolerant title matching
+	var didYouMean string
+	if len(rows) == 0 && arg.Query != "" {
+		count, err := server.store.CountBlog(ctx, blogCountParams(arg))
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			ctx.JSON(errorStatus(err), errorResponse(err))
 			return
 		}
 
-		getAllBlogResponse = append(getAllBlogResponse, GetAllBlogWithTagResponse{
-			GetAllBlogWithTagRow: blog,
-			BlogTags:             blogTags,
-		})
+		if count == 0 {
+			arg.Query, arg.Fuzzy = "", search.FuzzyText(input)
+
+			rows, err = listPage(arg)
+			if err != nil {
+				ctx.JSON(errorStatus(err), errorResponse(err))
+				return
+			}
+
+			didYouMean, err = blogSearchSuggestion(*server, ctx, arg.Fuzzy, arg.Statuses)
+			if err != nil {
+				ctx.JSON(errorStatus(err), errorResponse(err))
+				return
+			}
+		}
+	}
+
+	var nextCursor string
+	if len(rows) > int(arg.LimitRows) {
+		rows = rows[:arg.LimitRows]
+		last := rows[len(rows)-1]
+
+		listedAt := last.CreatedAt
+		if last.PublishedAt.Valid {
+			listedAt = last.PublishedAt.Time
+		}
+		nextCursor = util.EncodeCursor(listedAt, last.ID)
+	}
+
+	blogs := make([]db.ListBlogRow, 0, len(rows))
+	for _, row := range rows {
+		blogs = append(blogs, db.ListBlogRow(row))
 	}
 
-	argCount := db.CountAllBlogWithTagParams{
-		Lower:   req.Title,
-		Lower_2: req.Tag,
+	facets, err := blogTagFacets(*server, ctx, arg)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
 	}
 
-	count, err := server.store.CountAllBlogWithTag(ctx, argCount)
+	getAllBlogResponse, err := blogListItems(*server, ctx, blogs)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	payload := jsonResponseWithPaginate{
-		jsonResponse: jsonResponse{
-			Error:   false,
-			Message: "successfully",
-			Data:    getAllBlogResponse,
+	data, err := selectFields(getAllBlogResponse, fields)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	payload := jsonResponseWithCursorFacets{
+		jsonResponseWithCursor: jsonResponseWithCursor{
+			jsonResponse: jsonResponse{
+				Error:   false,
+				Message: "successfully",
+				Data:    data,
+			},
+			NextCursor: nextCursor,
+		},
+		DidYouMean: didYouMean,
+		Facets: GetAllBlogFacets{
+			Tags: facets,
 		},
-		Total: count,
+	}
+
+	// Counting is the expensive part of a listing, infinite scroll only asks for it once
+	if req.WithCount {
+		count, err := server.store.CountBlog(ctx, blogCountParams(arg))
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
+		payload.Total = &count
 	}
 
 	ctx.JSON(http.StatusOK, payload)
 }
 
+// blogListItems attaches the tags of every blog in a listing, loading them with a single query,
+// and makes the HTML of the items safe to serve
+func blogListItems(server Server, ctx *gin.Context, blogs []db.ListBlogRow) ([]GetAllBlogResponse, error) {
+	var items []GetAllBlogResponse
+	if len(blogs) == 0 {
+		return items, nil
+	}
+
+	blogIDs := make([]int64, 0, len(blogs))
+	for _, blog := range blogs {
+		blogIDs = append(blogIDs, blog.ID)
+	}
+
+	rows, err := server.store.GetBlogTagByBlogIds(ctx, blogIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	blogTags := make(map[int64][]db.GetBlogTagByBlogIdRow, len(blogs))
+	for _, row := range rows {
+		blogTags[row.BlogID] = append(blogTags[row.BlogID], db.GetBlogTagByBlogIdRow(row))
+	}
+
+	for _, blog := range blogs {
+		tags := blogTags[blog.ID]
+		if tags == nil {
+			tags = []db.GetBlogTagByBlogIdRow{}
+		}
+
+		// Like GetBlogByUrl, the cached HTML is sanitized on the way out: posts migrated from the
+		// old content column keep their unfiltered HTML until the reindex job renders them
+		if blog.Content != "" {
+			blog.Content = content.Sanitize(blog.Content)
+		}
+		blog.Snippet = search.HighlightSnippet(blog.Snippet)
+
+		items = append(items, GetAllBlogResponse{
+			ListBlogRow: blog,
+			BlogTags:    tags,
+		})
+	}
+
+	return items, nil
+}
+
+// GetAllBlogWithTag godoc
+//
+//	@Summary		Get All Blog With Tag
+//	@Description	Deprecated: use GET /api/blog, which accepts the same parameters including multiple tags
+//	@Tags			Blog
+//	@Accept			json
+//	@Produce		json
+//	@Param			title		query		string	false	"Blog Title (deprecated, use q)"
+//	@Param			tag			query		string	false	"Tag Name"
+//	@Param			page_id		query		int		true	"Page ID"
+//	@Param			page_size	query		int		true	"Page Size"
+//	@Success		200			{object}	jsonResponseWithFacets
+//	@Router			/api/blog/tag [get]
+//	@Deprecated
+func (server *Server) GetAllBlogWithTag(ctx *gin.Context) {
+	server.GetAllBlog(ctx)
+}
+
 type GetBlogByUrlRequest struct {
 	URL string `uri:"url" binding:"required,min=1"`
 }
@@ -179,6 +744,8 @@ type GetBlogByUrlRequest struct {
 type GetBlogByUrlResponse struct {
 	db.GetBlogByUrlRow
 	BlogTags []db.GetBlogTagByBlogIdRow `json:"blog_tags"`
+	// Toc lists the headings of the content in order, each linking to the anchor injected into it
+	Toc []content.TocEntry `json:"toc"`
 }
 
 // GetBlogByUrl godoc
@@ -190,6 +757,9 @@ type GetBlogByUrlResponse struct {
 //	@Produce		json
 //	@Param			url	path		string	true	"Blog URL"
 //	@Success		200	{object}	jsonResponse
+//	@Success		301	{object}	jsonResponse	"The blog moved to the URL in data.url, also sent as Location"
+//	@Failure		404	{object}	jsonResponse
+//	@Failure		410	{object}	jsonResponse	"The blog has been deleted"
 //	@Router			/api/blog/{url} [get]
 func (server *Server) GetBlogByUrl(ctx *gin.Context) {
 	var req GetBlogByUrlRequest
@@ -198,15 +768,69 @@ func (server *Server) GetBlogByUrl(ctx *gin.Context) {
 		return
 	}
 
+	statuses, err := visibleBlogStatuses(*server, ctx, "")
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
 	// Get Blog by URL
-	blog, err := server.store.GetBlogByUrl(ctx, req.URL)
+	blog, err := server.store.GetBlogByUrl(ctx, db.GetBlogByUrlParams{
+		Url:      req.URL,
+		Statuses: statuses,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			// The post may have moved to a new URL
+			redirected, err := redirectBlog(*server, ctx, req.URL, statuses)
+			if err != nil {
+				ctx.JSON(errorStatus(err), errorResponse(err))
+				return
+			}
+			if redirected {
+				return
+			}
+
+			deleted, err := server.store.ExistsDeletedBlogByUrl(ctx, db.ExistsDeletedBlogByUrlParams{
+				Url:      req.URL,
+				Statuses: statuses,
+			})
+			if err != nil {
+				ctx.JSON(errorStatus(err), errorResponse(err))
+				return
+			}
+			if deleted {
+				ctx.JSON(http.StatusGone, errorResponse(errBlogGone))
+				return
+			}
+
+			ctx.JSON(http.StatusNotFound, errorResponse(errBlogNotFound))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
+	// The cached HTML is sanitized again on the way out so posts rendered
+	// before the allowlist existed, or under an older one, never reach readers unfiltered
+	blog.Content = content.Sanitize(blog.Content)
+
+	var toc []content.TocEntry
+	blog.Content, toc = content.AddHeadingAnchors(blog.Content)
+
+	// Count a view of the public post, a failure here must not hide the post from the reader
+	if blog.Status == constants.BlogStatusPublished {
+		if err := server.store.IncrementBlogViewCount(ctx, blog.ID); err != nil {
+			log.Error().Err(err).Int64("blog_id", blog.ID).Msg("cannot count blog view")
+		}
+	}
+
 	// Get Blog Tags
 	blogTags, err := server.store.GetBlogTagByBlogId(ctx, blog.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
 
 	ctx.JSON(http.StatusOK, jsonResponse{
 		Error:   false,
@@ -214,6 +838,7 @@ func (server *Server) GetBlogByUrl(ctx *gin.Context) {
 		Data: GetBlogByUrlResponse{
 			GetBlogByUrlRow: blog,
 			BlogTags:        blogTags,
+			Toc:             toc,
 		},
 	})
 }
@@ -225,6 +850,8 @@ type GetBlogByIDRequest struct {
 type GetBlogByIDResponse struct {
 	db.GetBlogByIdRow
 	BlogTags []db.GetBlogTagByBlogIdRow `json:"blog_tags"`
+	// Lock shows who is editing the blog right now, null when nobody is
+	Lock *BlogLockResponse `json:"lock"`
 }
 
 // GetBlogByID godoc
@@ -236,6 +863,9 @@ type GetBlogByIDResponse struct {
 //	@Produce		json
 //	@Param			id	query		int	true	"Blog ID"
 //	@Success		200	{object}	jsonResponse
+//	@Header			200	{string}	ETag	"Version of the blog, send it back as If-Match when saving"
+//	@Failure		404	{object}	jsonResponse
+//	@Failure		410	{object}	jsonResponse	"The blog has been deleted"
 //	@Router			/api/blog/id [get]
 //	@Security		BearerAuth
 func (server *Server) GetBlogByID(ctx *gin.Context) {
@@ -248,27 +878,139 @@ func (server *Server) GetBlogByID(ctx *gin.Context) {
 	// Get Blog by ID
 	blog, err := server.store.GetBlogById(ctx, req.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			blogNotFound(*server, ctx, req.ID)
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
+	// Writers without view_blog may only open their own posts
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if blog.AuthorID != authPayload.UserId {
+		canViewAny, err := hasPermission(*server, ctx, authPayload.UserId, constants.PermissionViewBlog.Code)
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
+
+		if !canViewAny {
+			err := errors.New("permission denied")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
 	// Get Blog Tags
 	blogTags, err := server.store.GetBlogTagByBlogId(ctx, blog.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
+	lock, err := getBlogLock(*server, ctx, blog.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	setVersionETag(ctx, blog.Version)
 	ctx.JSON(http.StatusOK, jsonResponse{
 		Error:   false,
 		Message: "successfully",
 		Data: GetBlogByIDResponse{
 			GetBlogByIdRow: blog,
 			BlogTags:       blogTags,
+			Lock:           lock,
 		},
 	})
 }
 
+// blogVersionConflict answers a save based on an outdated version with the current copy of the blog
+func blogVersionConflict(server Server, ctx *gin.Context, blogID int64) {
+	blog, err := server.store.GetBlogById(ctx, blogID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			blogNotFound(server, ctx, blogID)
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	blogTags, err := server.store.GetBlogTagByBlogId(ctx, blog.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	lock, err := getBlogLock(server, ctx, blog.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	versionConflict(ctx, blog.Version, GetBlogByIDResponse{
+		GetBlogByIdRow: blog,
+		BlogTags:       blogTags,
+		Lock:           lock,
+	})
+}
+
+// resolveBlogImage returns the URL of the cover image of a blog. A media ID refers to a file uploaded through
+// POST /api/media. Otherwise an image URL is kept as is and a base64 data URL is uploaded, as older clients send it.
+// An uploaded image goes into the media library like any other upload, so blogMediaReferences finds it by its URL.
+// On failure the error response has already been written and ok is false.
+func resolveBlogImage(server Server, ctx *gin.Context, image string, mediaID int64) (string, bool) {
+	if mediaID != 0 {
+		media, err := server.store.GetMediaById(ctx, mediaID)
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				ctx.JSON(http.StatusBadRequest, errorResponse(errMediaNotFound))
+				return "", false
+			}
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return "", false
+		}
+		return media.Url, true
+	}
+
+	// The URL of a stored file is relative when the API serves the files itself
+	if util.IsValidURL(image) || strings.HasPrefix(image, server.storage.URL("")) {
+		return image, true
+	}
+
+	// Uploading image. The MIME type in the data URL is not trusted, the image is identified from its bytes.
+	_, fileBase64, found := strings.Cut(image, ";base64,")
+	if !found {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("invalid base64 string")))
+		return "", false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(fileBase64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return "", false
+	}
+
+	stored, err := storeImage(server, ctx, bytes.NewReader(data))
+	if err != nil {
+		ctx.JSON(mediaUploadError(err))
+		return "", false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	media, err := saveMedia(server, ctx, stored, "", authPayload.UserId)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return "", false
+	}
+
+	return media.Url, true
+}
+
 type BlogTagRequest struct {
 	ID      int64 `json:"id" binding:"required,min=1"`
 	BlogID  int64 `json:"blog_id" binding:"required,min=1"`
@@ -277,15 +1019,20 @@ type BlogTagRequest struct {
 }
 
 type CreateBlogRequest struct {
-	Title    string           `json:"title" binding:"required"`
-	Content  string           `json:"content" binding:"required"`
-	Image    string           `json:"image" binding:"required"`
-	URL      string           `json:"url" binding:"required"`
-	BlogTags []BlogTagRequest `json:"blog_tags"`
+	Title         string           `json:"title" binding:"required"`
+	Content       string           `json:"content" binding:"required"`
+	ContentFormat string           `json:"content_format" binding:"omitempty,oneof=markdown html"` // html when empty
+	Image         string           `json:"image" binding:"required_without=ImageMediaID"`          // base64 data URL, prefer image_media_id
+	ImageMediaID  int64            `json:"image_media_id" binding:"omitempty,min=1"`               // uploaded through POST /api/media
+	URL           string           `json:"url" binding:"max=255"`                                  // generated from the title when empty
+	Status        string           `json:"status" binding:"omitempty,oneof=draft"`
+	PublishAt     *time.Time       `json:"publish_at"`
+	Excerpt       string           `json:"excerpt" binding:"max=500"`
+	BlogTags      []BlogTagRequest `json:"blog_tags"`
 }
 
 type CreateBlogByIdResponse struct {
-	db.Blog
+	db.CreateBlogRow
 	BlogTags []db.GetBlogTagByBlogIdRow `json:"blog_tags"`
 }
 
@@ -307,90 +1054,110 @@ func (server *Server) CreateBlog(ctx *gin.Context) {
 		return
 	}
 
-	// Uploading image
-	// Get file extension
-	fileExtension, err := util.GetFileExtensionFromBase64(req.Image)
+	// New posts always start as drafts and go live through the review workflow
+	status := constants.BlogStatusDraft
+
+	publishAt, err := resolveBlogSchedule(status, req.PublishAt, pgtype.Timestamptz{})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	// Generate file name
-	fileName := uuid.New().String()
+	blogURL, ok := resolveBlogUrl(*server, ctx, 0, req.URL, req.Title)
+	if !ok {
+		return
+	}
 
-	// Convert image.ImageUrl (Base64) to image file
-	fileBase64 := util.GetBase64Data(req.Image)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	// Convert image.ImageUrl (Base64) to image file
-	image_location := fmt.Sprintf("./image/%s%s", fileName, fileExtension)
-	util.SaveBase64ToFile(fileBase64, image_location)
-	Image_url_result, err := util.UploadFileToMinio(ctx, image_location)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	imageURL, ok := resolveBlogImage(*server, ctx, req.Image, req.ImageMediaID)
+	if !ok {
 		return
 	}
 
-	// Delete image file
-	err = os.Remove(image_location)
+	contentFormat := req.ContentFormat
+	if contentFormat == "" {
+		contentFormat = constants.ContentFormatHTML
+	}
+
+	// Render once on save, readers are served the cached HTML
+	contentHTML, err := content.Render(req.Content, contentFormat)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	arg := db.CreateBlogParams{
-		Title:   req.Title,
-		Content: req.Content,
-		Image:   *Image_url_result,
-		Url:     req.URL,
-	}
+	summary := content.Summarize(contentHTML)
 
-	// Insert Blog
-	blog, err := server.store.CreateBlog(ctx, arg)
+	imageMediaID, mediaIDs, err := blogMediaReferences(*server, ctx, imageURL, contentHTML)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	// Insert Blog Tag
-	for _, tag := range req.BlogTags {
-		if tag.Deleted {
-			continue
-		}
+	arg := db.CreateBlogParams{
+		Title:              req.Title,
+		Content:            req.Content,
+		ContentFormat:      contentFormat,
+		ContentHtml:        contentHTML,
+		Image:              imageURL,
+		ImageMediaID:       imageMediaID,
+		Url:                blogURL,
+		Status:             status,
+		PublishAt:          publishAt,
+		AuthorID:           authPayload.UserId,
+		Excerpt:            req.Excerpt,
+		AutoExcerpt:        summary.Excerpt,
+		WordCount:          summary.WordCount,
+		ReadingTimeMinutes: summary.ReadingTimeMinutes,
+		SearchTitle:        search.IndexText(req.Title),
+		SearchContent:      search.IndexText(contentHTML),
+		SearchVersion:      search.IndexVersion(),
+	}
 
-		err := server.store.CreateBlogTag(ctx, db.CreateBlogTagParams{
-			BlogID: blog.ID,
-			TagID:  tag.TagId,
-		})
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
+	var tagIDs []int64
+	for _, tag := range req.BlogTags {
+		if !tag.Deleted {
+			tagIDs = append(tagIDs, tag.TagId)
 		}
 	}
 
-	// Get BlogTags by Blog ID
-	blogTags, err := server.store.GetBlogTagByBlogId(ctx, blog.ID)
+	// Insert Blog with its tags and first revision
+	result, err := server.store.CreateBlogTx(ctx, db.CreateBlogTxParams{
+		CreateBlogParams: arg,
+		TagIDs:           tagIDs,
+		MediaIDs:         mediaIDs,
+		EditedBy:         pgtype.Int8{Int64: authPayload.UserId, Valid: true},
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(blogSaveError(err))
 		return
 	}
 
+	setVersionETag(ctx, result.Blog.Version)
 	ctx.JSON(http.StatusOK, jsonResponse{
 		Error:   false,
 		Message: "successfully",
 		Data: CreateBlogByIdResponse{
-			Blog:     blog,
-			BlogTags: blogTags,
+			CreateBlogRow: result.Blog,
+			BlogTags:      result.BlogTags,
 		},
 	})
 }
 
 type UpdateBlogRequest struct {
-	ID       int64            `json:"id" binding:"required,min=1"`
-	Title    string           `json:"title" binding:"required"`
-	Content  string           `json:"content" binding:"required"`
-	Image    string           `json:"image" binding:"required"`
-	URL      string           `json:"url" binding:"required"`
-	BlogTags []BlogTagRequest `json:"blog_tags"`
+	ID            int64            `json:"id" binding:"required,min=1"`
+	Title         string           `json:"title" binding:"required"`
+	Content       string           `json:"content" binding:"required"`
+	ContentFormat string           `json:"content_format" binding:"omitempty,oneof=markdown html"` // current format when empty
+	Image         string           `json:"image" binding:"required_without=ImageMediaID"`          // image URL or base64 data URL, prefer image_media_id
+	ImageMediaID  int64            `json:"image_media_id" binding:"omitempty,min=1"`               // uploaded through POST /api/media
+	URL           string           `json:"url" binding:"max=255"`                                  // current URL when empty, an old URL redirects to the new one
+	Status        string           `json:"status" binding:"omitempty,oneof=draft in_review approved scheduled published archived"`
+	PublishAt     *time.Time       `json:"publish_at"`
+	Excerpt       string           `json:"excerpt" binding:"max=500"`
+	BlogTags      []BlogTagRequest `json:"blog_tags"`
+	Version       int32            `json:"version" binding:"omitempty,min=1"` // the version the changes are based on, required unless sent as If-Match
 }
 
 type UpdateBlogResponse struct {
@@ -405,8 +1172,12 @@ type UpdateBlogResponse struct {
 //	@Tags			Blog
 //	@Accept			json
 //	@Produce		json
-//	@Param			input	body		UpdateBlogRequest	true	"Update information"
-//	@Success		200		{object}	jsonResponse
+//	@Param			input		body		UpdateBlogRequest	true	"Update information"
+//	@Param			If-Match	header		string				false	"ETag of the version the changes are based on, instead of version in the body"
+//	@Success		200			{object}	jsonResponse
+//	@Header			200			{string}	ETag	"Version of the saved blog"
+//	@Failure		409			{object}	jsonResponse	"The blog was changed by someone else, data holds the current copy"
+//	@Failure		428			{object}	jsonResponse	"Neither version nor If-Match was sent"
 //	@Router			/api/blog [put]
 //	@Security		BearerAuth
 func (server *Server) UpdateBlog(ctx *gin.Context) {
@@ -416,108 +1187,140 @@ func (server *Server) UpdateBlog(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.UpdateBlogParams{
-		ID:      req.ID,
-		Title:   req.Title,
-		Content: req.Content,
-		Image:   req.Image,
-		Url:     req.URL,
-	}
-
-	// Check Image is link or base64
-	if !util.IsValidURL(req.Image) {
-		// Uploading image
-		// Get file extension
-		fileExtension, err := util.GetFileExtensionFromBase64(req.Image)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	currentBlog, ok := authorizeBlogEdit(*server, ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	// Reject a save based on an outdated copy before doing any work, the update itself checks again
+	version, ok := expectedVersion(ctx, req.Version, currentBlog.Version)
+	if !ok {
+		return
+	}
+	if version != currentBlog.Version {
+		blogVersionConflict(*server, ctx, currentBlog.ID)
+		return
+	}
 
-		// Generate file name
-		fileName := uuid.New().String()
+	status := req.Status
+	if err := validateBlogStatusTransition(currentBlog.Status, status); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-		// Convert image.ImageUrl (Base64) to image file
-		fileBase64 := util.GetBase64Data(req.Image)
+	publishAt, err := resolveBlogSchedule(status, req.PublishAt, currentBlog.PublishAt)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-		// Convert image.ImageUrl (Base64) to image file
-		image_location := fmt.Sprintf("./image/%s%s", fileName, fileExtension)
-		util.SaveBase64ToFile(fileBase64, image_location)
-		Image_url_result, err := util.UploadFileToMinio(ctx, image_location)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
+	if !authorizeBlogPublish(*server, ctx, currentBlog.Status, status) {
+		return
+	}
 
-		// Delete image file
-		err = os.Remove(image_location)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	blogURL := currentBlog.Url
+	if req.URL != "" {
+		blogURL, ok = resolveBlogUrl(*server, ctx, currentBlog.ID, req.URL, req.Title)
+		if !ok {
 			return
 		}
+	}
+
+	imageURL, ok := resolveBlogImage(*server, ctx, req.Image, req.ImageMediaID)
+	if !ok {
+		return
+	}
 
-		arg.Image = *Image_url_result
+	contentFormat := req.ContentFormat
+	if contentFormat == "" {
+		contentFormat = currentBlog.ContentFormat
 	}
 
-	// Update Blog
-	err := server.store.UpdateBlog(ctx, arg)
+	contentHTML, err := content.Render(req.Content, contentFormat)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	for _, bt := range req.BlogTags {
+	summary := content.Summarize(contentHTML)
 
-		argGetBlogTagByBlogIdAndTagId := db.GetBlogTagByBlogIdAndTagIdParams{
-			BlogID: bt.BlogID,
-			TagID:  bt.TagId,
-		}
+	imageMediaID, mediaIDs, err := blogMediaReferences(*server, ctx, imageURL, contentHTML)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
 
-		// Check exists blog_tag
-		blogTagExists, _ := server.store.GetBlogTagByBlogIdAndTagId(ctx, argGetBlogTagByBlogIdAndTagId)
-		if blogTagExists.ID == 0 && !bt.Deleted {
-			// Insert Blog Tag
-			err := server.store.CreateBlogTag(ctx, db.CreateBlogTagParams{
-				BlogID: req.ID,
-				TagID:  bt.TagId,
-			})
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-				return
-			}
-		} else if blogTagExists.ID != 0 && bt.Deleted {
-			// Delete Blog Tag
-			err := server.store.DeleteBlogTag(ctx, db.DeleteBlogTagParams{
-				BlogID: req.ID,
-				TagID:  bt.TagId,
-			})
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-				return
-			}
+	arg := db.UpdateBlogParams{
+		ID:                 req.ID,
+		Title:              req.Title,
+		Content:            req.Content,
+		ContentFormat:      contentFormat,
+		ContentHtml:        contentHTML,
+		Image:              imageURL,
+		ImageMediaID:       imageMediaID,
+		Url:                blogURL,
+		PublishAt:          publishAt,
+		UpdatedBy:          pgtype.Int8{Int64: authPayload.UserId, Valid: true},
+		Excerpt:            req.Excerpt,
+		AutoExcerpt:        summary.Excerpt,
+		WordCount:          summary.WordCount,
+		ReadingTimeMinutes: summary.ReadingTimeMinutes,
+		SearchTitle:        search.IndexText(req.Title),
+		SearchContent:      search.IndexText(contentHTML),
+		SearchVersion:      search.IndexVersion(),
+		Version:            version,
+	}
+
+	// Changing the content of a reviewed post needs a fresh approval
+	contentChanged := arg.Title != currentBlog.Title || arg.Content != currentBlog.Content ||
+		arg.ContentFormat != currentBlog.ContentFormat || arg.Image != currentBlog.Image
+	if contentChanged {
+		status, ok = blogStatusAfterContentChange(*server, ctx, currentBlog.Status, status)
+		if !ok {
+			return
 		}
 	}
 
-	// Get Blog by Id
-	blog, err := server.store.GetBlogById(ctx, req.ID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+	arg.Status = pgtype.Text{
+		String: status,
+		Valid:  status != "",
 	}
 
-	// Get Blog Tags
-	blogTags, err := server.store.GetBlogTagByBlogId(ctx, blog.ID)
+	tags := make([]db.BlogTagChange, 0, len(req.BlogTags))
+	for _, bt := range req.BlogTags {
+		tags = append(tags, db.BlogTagChange{
+			TagID:   bt.TagId,
+			Deleted: bt.Deleted,
+		})
+	}
+
+	// Update Blog, its tags and redirects, and snapshot the saved content
+	result, err := server.store.UpdateBlogTx(ctx, db.UpdateBlogTxParams{
+		UpdateBlogParams: arg,
+		// Keep inbound links to the old URL working
+		OldUrl:   currentBlog.Url,
+		Tags:     tags,
+		MediaIDs: mediaIDs,
+		EditedBy: pgtype.Int8{Int64: authPayload.UserId, Valid: true},
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrVersionConflict) {
+			blogVersionConflict(*server, ctx, currentBlog.ID)
+			return
+		}
+		ctx.JSON(blogSaveError(err))
 		return
 	}
 
+	setVersionETag(ctx, result.Blog.Version)
 	ctx.JSON(http.StatusOK, jsonResponse{
 		Error:   false,
 		Message: "successfully",
 		Data: UpdateBlogResponse{
-			GetBlogByIdRow: blog,
-			BlogTags:       blogTags,
+			GetBlogByIdRow: result.Blog,
+			BlogTags:       result.BlogTags,
 		},
 	})
 }
@@ -544,17 +1347,14 @@ func (server *Server) DeleteBlog(ctx *gin.Context) {
 		return
 	}
 
-	// Delete Blog
-	err := server.store.DeleteBlog(ctx, req.ID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if _, ok := authorizeBlogEdit(*server, ctx, req.ID); !ok {
 		return
 	}
 
-	// Delete Blog Tag by Blog ID
-	err = server.store.DeleteBlogTagByBlogId(ctx, req.ID)
+	// Delete Blog and its blog_tag rows
+	err := server.store.DeleteBlogTx(ctx, req.ID, time.Now())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
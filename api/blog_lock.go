@@ -0,0 +1,226 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"blog-go-api/constants"
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultBlogLockDuration applies when BLOG_LOCK_DURATION is not configured
+const defaultBlogLockDuration = 2 * time.Minute
+
+type BlogLockRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type BlogLockQueryRequest struct {
+	Force bool `form:"force"` // take over or break a lock held by someone else, needs edit_blog
+}
+
+type BlogLockResponse struct {
+	db.GetBlogLockRow
+	// Owned is true when the lock belongs to the caller, otherwise the blog is being edited by the user in the lock
+	Owned bool `json:"owned"`
+}
+
+// blogLockDuration is how long a lock lasts without a heartbeat
+func blogLockDuration(server Server) time.Duration {
+	if server.config.BlogLockDuration <= 0 {
+		return defaultBlogLockDuration
+	}
+	return server.config.BlogLockDuration
+}
+
+// getBlogLock loads the lock of a blog for the caller, nil when nobody is editing it
+func getBlogLock(server Server, ctx *gin.Context, blogID int64) (*BlogLockResponse, error) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	lock, err := server.store.GetBlogLock(ctx, blogID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &BlogLockResponse{
+		GetBlogLockRow: lock,
+		Owned:          lock.UserID == authPayload.UserId,
+	}, nil
+}
+
+// blogLocked answers with the lock someone else holds on a blog
+func blogLocked(ctx *gin.Context, lock *BlogLockResponse) {
+	message := "blog is being edited by someone else"
+	if lock != nil {
+		message = fmt.Sprintf("blog is being edited by %s %s", lock.UserFirstName, lock.UserLastName)
+	}
+
+	ctx.JSON(http.StatusConflict, jsonResponse{
+		Error:   true,
+		Message: message,
+		Data:    lock,
+	})
+}
+
+// authorizeBlogLockForce checks that the caller holds edit_blog before they take over or break
+// the lock of another user. On failure the error response has already been written.
+func authorizeBlogLockForce(server Server, ctx *gin.Context) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	canEditAny, err := hasPermission(server, ctx, authPayload.UserId, constants.PermissionEditBlog.Code)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return false
+	}
+
+	if !canEditAny {
+		err := errors.New("permission denied: breaking an edit lock requires " + constants.PermissionEditBlog.Code)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// AcquireBlogLock godoc
+//
+//	@Summary		Acquire Blog Lock
+//	@Description	Lock a blog for editing, or renew the caller's lock as a heartbeat. The lock expires when the heartbeats stop.
+//	@Tags			Blog Lock
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int		true	"Blog ID"
+//	@Param			force	query		bool	false	"Take over the lock from another user, needs edit_blog"
+//	@Success		200		{object}	jsonResponse
+//	@Failure		409		{object}	jsonResponse	"Someone else is editing the blog, data holds their lock"
+//	@Router			/api/blog/{id}/lock [post]
+//	@Security		BearerAuth
+func (server *Server) AcquireBlogLock(ctx *gin.Context) {
+	var req BlogLockRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var query BlogLockQueryRequest
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := authorizeBlogEdit(*server, ctx, req.ID); !ok {
+		return
+	}
+
+	if query.Force && !authorizeBlogLockForce(*server, ctx) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	_, err := server.store.AcquireBlogLock(ctx, db.AcquireBlogLockParams{
+		BlogID:     req.ID,
+		UserID:     authPayload.UserId,
+		TtlSeconds: int32(blogLockDuration(*server).Seconds()),
+		Force:      query.Force,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			lock, err := getBlogLock(*server, ctx, req.ID)
+			if err != nil {
+				ctx.JSON(errorStatus(err), errorResponse(err))
+				return
+			}
+			blogLocked(ctx, lock)
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	lock, err := getBlogLock(*server, ctx, req.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    lock,
+	})
+}
+
+// ReleaseBlogLock godoc
+//
+//	@Summary		Release Blog Lock
+//	@Description	Release the caller's lock on a blog. With force the lock of another user is broken, which needs edit_blog.
+//	@Tags			Blog Lock
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int		true	"Blog ID"
+//	@Param			force	query		bool	false	"Break the lock of another user, needs edit_blog"
+//	@Success		200		{object}	jsonResponse
+//	@Failure		409		{object}	jsonResponse	"Someone else holds the lock, data holds their lock"
+//	@Router			/api/blog/{id}/lock [delete]
+//	@Security		BearerAuth
+func (server *Server) ReleaseBlogLock(ctx *gin.Context) {
+	var req BlogLockRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var query BlogLockQueryRequest
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := authorizeBlogEdit(*server, ctx, req.ID); !ok {
+		return
+	}
+
+	if query.Force && !authorizeBlogLockForce(*server, ctx) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	released, err := server.store.ReleaseBlogLock(ctx, db.ReleaseBlogLockParams{
+		BlogID: req.ID,
+		UserID: authPayload.UserId,
+		Force:  query.Force,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	// Releasing a lock that already expired is not an error, only a lock someone else holds is
+	if released == 0 {
+		lock, err := getBlogLock(*server, ctx, req.ID)
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
+		if lock != nil && !lock.Owned {
+			blogLocked(ctx, lock)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    nil,
+	})
+}
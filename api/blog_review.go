@@ -0,0 +1,393 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"blog-go-api/constants"
+	db "blog-go-api/db/sqlc"
+	mail "blog-go-api/mail"
+	"blog-go-api/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// authorizeBlogReviewAccess loads the blog and checks that the caller may read and comment on its review thread.
+// Reviewers and holders of view_blog may access every post, writers only their own.
+// On failure the error response has already been written and ok is false.
+func authorizeBlogReviewAccess(server Server, ctx *gin.Context, blogID int64) (blog db.GetBlogByIdRow, ok bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	blog, err := server.store.GetBlogById(ctx, blogID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			blogNotFound(server, ctx, blogID)
+			return blog, false
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return blog, false
+	}
+
+	if blog.AuthorID == authPayload.UserId {
+		return blog, true
+	}
+
+	canAccess, err := hasPermission(server, ctx, authPayload.UserId, constants.PermissionReviewBlog.Code, constants.PermissionViewBlog.Code)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return blog, false
+	}
+
+	if !canAccess {
+		err := errors.New("permission denied")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return blog, false
+	}
+
+	return blog, true
+}
+
+// requireBlogStatus writes a conflict response when the blog is not in the expected status
+func requireBlogStatus(ctx *gin.Context, blog db.GetBlogByIdRow, status string) bool {
+	if blog.Status != status {
+		err := fmt.Errorf("blog must be %s but is %s", status, blog.Status)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// changeBlogStatus moves the blog to a new status and records the review action against its latest revision in one transaction.
+// The update only applies while the blog is still in the status it was loaded with,
+// so two reviewers acting at the same time cannot both succeed.
+func (server *Server) changeBlogStatus(ctx *gin.Context, blog db.GetBlogByIdRow, status string, publishAt pgtype.Timestamptz, action string, note string) (db.BlogReview, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	review, err := server.store.ChangeBlogStatusTx(ctx, db.ChangeBlogStatusTxParams{
+		UpdateBlogStatusParams: db.UpdateBlogStatusParams{
+			ID:            blog.ID,
+			Status:        status,
+			PublishAt:     publishAt,
+			UpdatedBy:     pgtype.Int8{Int64: authPayload.UserId, Valid: true},
+			CurrentStatus: blog.Status,
+		},
+		UserID: authPayload.UserId,
+		Action: action,
+		Note:   note,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err := errors.New("blog status was changed by someone else, please reload")
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return db.BlogReview{}, false
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return db.BlogReview{}, false
+	}
+
+	return review, true
+}
+
+// notifyBlogAuthor emails the author of the blog about a review decision.
+// Mail is sent in the background so a slow SMTP server does not hold up the reviewer.
+func (server *Server) notifyBlogAuthor(ctx *gin.Context, blog db.GetBlogByIdRow, subject string, decision string, note string) {
+	author, err := server.store.GetUser(ctx, blog.AuthorID)
+	if err != nil {
+		log.Error().Err(err).Int64("blog_id", blog.ID).Msg("cannot load blog author for review email")
+		return
+	}
+
+	noteHTML := ""
+	if note != "" {
+		noteHTML = fmt.Sprintf(`<p><strong>Reviewer notes:</strong></p>
+				<blockquote style="border-left: 4px solid #17A34A; margin: 0; padding-left: 12px; white-space: pre-wrap;">%s</blockquote>`, html.EscapeString(note))
+	}
+
+	content := fmt.Sprintf(`<!DOCTYPE html>
+		<html lang="en">
+		<head>
+			<meta charset="UTF-8">
+			<meta name="viewport" content="width=device-width, initial-scale=1.0">
+			<title>%s</title>
+		</head>
+		<body style="font-family: Arial, sans-serif; background-color: #f4f4f4; padding: 20px;">
+			<div style="max-width: 600px; margin: auto; background-color: #ffffff; padding: 20px; border-radius: 10px; box-shadow: 0 0 10px rgba(0, 0, 0, 0.1);">
+				<h2 style="color: #333333;">%s</h2>
+				<p>Dear %s,</p>
+				<p>Your blog post <strong>%s</strong> %s</p>
+				%s
+				<p>Best regards,</p>
+				<p>Automated System</p>
+			</div>
+		</body>
+		</html>`, html.EscapeString(subject), html.EscapeString(subject), html.EscapeString(author.FirstName), html.EscapeString(blog.Title), decision, noteHTML)
+
+	sender := mail.NewGmailSender(server.config.EmailSenderName, server.config.EmailSenderAddress, server.config.EmailSenderPassword)
+	to := []string{author.Email}
+
+	go func() {
+		if err := sender.SendEmail(subject, content, to, nil, nil, nil); err != nil {
+			log.Error().Err(err).Int64("blog_id", blog.ID).Msg("cannot send review email")
+		}
+	}()
+}
+
+type GetAllBlogReviewRequest struct {
+	BlogID int64 `form:"blog_id" binding:"required,min=1"`
+}
+
+// GetAllBlogReview godoc
+//
+//	@Summary		Get All Blog Review
+//	@Description	Get the review history and reviewer notes of a blog, newest first
+//	@Tags			Blog Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			blog_id	query		int	true	"Blog ID"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_review [get]
+//	@Security		BearerAuth
+func (server *Server) GetAllBlogReview(ctx *gin.Context) {
+	var req GetAllBlogReviewRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := authorizeBlogReviewAccess(*server, ctx, req.BlogID); !ok {
+		return
+	}
+
+	reviews, err := server.store.GetBlogReviewByBlogId(ctx, req.BlogID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    reviews,
+	})
+}
+
+type BlogReviewRequest struct {
+	BlogID int64  `json:"blog_id" binding:"required,min=1"`
+	Note   string `json:"note"`
+}
+
+// SubmitBlogReview godoc
+//
+//	@Summary		Submit Blog For Review
+//	@Description	Move a draft to in_review so a reviewer can approve it or request changes
+//	@Tags			Blog Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		BlogReviewRequest	true	"Blog and optional note for the reviewer"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_review/submit [post]
+//	@Security		BearerAuth
+func (server *Server) SubmitBlogReview(ctx *gin.Context) {
+	var req BlogReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	blog, ok := authorizeBlogEdit(*server, ctx, req.BlogID)
+	if !ok {
+		return
+	}
+
+	if !requireBlogStatus(ctx, blog, constants.BlogStatusDraft) {
+		return
+	}
+
+	review, ok := server.changeBlogStatus(ctx, blog, constants.BlogStatusInReview, pgtype.Timestamptz{}, constants.BlogReviewActionSubmit, req.Note)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, successResponse(review))
+}
+
+// ApproveBlogReview godoc
+//
+//	@Summary		Approve Blog
+//	@Description	Approve a blog in review so it can be published. Authors cannot approve their own posts.
+//	@Tags			Blog Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		BlogReviewRequest	true	"Blog and optional reviewer note"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_review/approve [post]
+//	@Security		BearerAuth
+func (server *Server) ApproveBlogReview(ctx *gin.Context) {
+	var req BlogReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	blog, ok := authorizeBlogReviewAccess(*server, ctx, req.BlogID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if blog.AuthorID == authPayload.UserId {
+		err := errors.New("a blog must be approved by someone other than its author")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	if !requireBlogStatus(ctx, blog, constants.BlogStatusInReview) {
+		return
+	}
+
+	review, ok := server.changeBlogStatus(ctx, blog, constants.BlogStatusApproved, pgtype.Timestamptz{}, constants.BlogReviewActionApprove, req.Note)
+	if !ok {
+		return
+	}
+
+	server.notifyBlogAuthor(ctx, blog, "Your blog post was approved", "has been approved and is ready to be published.", req.Note)
+
+	ctx.JSON(http.StatusOK, successResponse(review))
+}
+
+type RequestChangesBlogReviewRequest struct {
+	BlogID int64  `json:"blog_id" binding:"required,min=1"`
+	Note   string `json:"note" binding:"required"`
+}
+
+// RequestChangesBlogReview godoc
+//
+//	@Summary		Request Changes On Blog
+//	@Description	Send a blog in review back to its author as a draft together with the reviewer notes
+//	@Tags			Blog Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RequestChangesBlogReviewRequest	true	"Blog and reviewer notes"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_review/request_changes [post]
+//	@Security		BearerAuth
+func (server *Server) RequestChangesBlogReview(ctx *gin.Context) {
+	var req RequestChangesBlogReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	blog, ok := authorizeBlogReviewAccess(*server, ctx, req.BlogID)
+	if !ok {
+		return
+	}
+
+	if !requireBlogStatus(ctx, blog, constants.BlogStatusInReview) {
+		return
+	}
+
+	review, ok := server.changeBlogStatus(ctx, blog, constants.BlogStatusDraft, pgtype.Timestamptz{}, constants.BlogReviewActionRequestChanges, req.Note)
+	if !ok {
+		return
+	}
+
+	server.notifyBlogAuthor(ctx, blog, "Changes requested on your blog post", "was sent back to you with requested changes.", req.Note)
+
+	ctx.JSON(http.StatusOK, successResponse(review))
+}
+
+type PublishBlogReviewRequest struct {
+	BlogID    int64      `json:"blog_id" binding:"required,min=1"`
+	PublishAt *time.Time `json:"publish_at"`
+	Note      string     `json:"note"`
+}
+
+// PublishBlogReview godoc
+//
+//	@Summary		Publish Blog
+//	@Description	Publish an approved blog now, or schedule it when publish_at (from the request or saved on the blog) is in the future
+//	@Tags			Blog Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		PublishBlogReviewRequest	true	"Blog and optional publish time"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_review/publish [post]
+//	@Security		BearerAuth
+func (server *Server) PublishBlogReview(ctx *gin.Context) {
+	var req PublishBlogReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	blog, ok := authorizeBlogReviewAccess(*server, ctx, req.BlogID)
+	if !ok {
+		return
+	}
+
+	if !requireBlogStatus(ctx, blog, constants.BlogStatusApproved) {
+		return
+	}
+
+	publishAt, err := resolveBlogSchedule("", req.PublishAt, blog.PublishAt)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	status := constants.BlogStatusPublished
+	if publishAt.Valid || (blog.PublishAt.Valid && blog.PublishAt.Time.After(time.Now())) {
+		status = constants.BlogStatusScheduled
+	}
+
+	review, ok := server.changeBlogStatus(ctx, blog, status, publishAt, constants.BlogReviewActionPublish, req.Note)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, successResponse(review))
+}
+
+// CommentBlogReview godoc
+//
+//	@Summary		Comment On Blog
+//	@Description	Attach a note to the current version of a blog without changing its status
+//	@Tags			Blog Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RequestChangesBlogReviewRequest	true	"Blog and note"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_review/comment [post]
+//	@Security		BearerAuth
+func (server *Server) CommentBlogReview(ctx *gin.Context) {
+	var req RequestChangesBlogReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := authorizeBlogReviewAccess(*server, ctx, req.BlogID); !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	review, err := server.store.CreateBlogReviewTx(ctx, db.CreateBlogReviewParams{
+		BlogID: req.BlogID,
+		UserID: authPayload.UserId,
+		Action: constants.BlogReviewActionComment,
+		Note:   req.Note,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, successResponse(review))
+}
@@ -0,0 +1,316 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"blog-go-api/content"
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/search"
+	"blog-go-api/token"
+	"blog-go-api/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type GetAllBlogRevisionRequest struct {
+	BlogID   int64 `form:"blog_id" binding:"required,min=1"`
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+// GetAllBlogRevision godoc
+//
+//	@Summary		Get All Blog Revision
+//	@Description	Get the revision history of a blog, newest first
+//	@Tags			Blog Revision
+//	@Accept			json
+//	@Produce		json
+//	@Param			blog_id		query		int	true	"Blog ID"
+//	@Param			page_id		query		int	true	"Page ID"
+//	@Param			page_size	query		int	true	"Page Size"
+//	@Success		200			{object}	jsonResponseWithPaginate
+//	@Router			/api/blog_revision [get]
+//	@Security		BearerAuth
+func (server *Server) GetAllBlogRevision(ctx *gin.Context) {
+	var req GetAllBlogRevisionRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Revisions hold unpublished drafts, only those who may edit the blog can read its history
+	if _, ok := authorizeBlogEdit(*server, ctx, req.BlogID); !ok {
+		return
+	}
+
+	arg := db.GetBlogRevisionByBlogIdParams{
+		BlogID: req.BlogID,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	revisions, err := server.store.GetBlogRevisionByBlogId(ctx, arg)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	count, err := server.store.CountBlogRevisionByBlogId(ctx, req.BlogID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	payload := jsonResponseWithPaginate{
+		jsonResponse: jsonResponse{
+			Error:   false,
+			Message: "successfully",
+			Data:    revisions,
+		},
+		Total: count,
+	}
+
+	ctx.JSON(http.StatusOK, payload)
+}
+
+type GetBlogRevisionByIdRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// GetBlogRevisionById godoc
+//
+//	@Summary		Get Blog Revision By ID
+//	@Description	Get a single blog revision including its content
+//	@Tags			Blog Revision
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Blog Revision ID"
+//	@Success		200	{object}	jsonResponse
+//	@Router			/api/blog_revision/{id} [get]
+//	@Security		BearerAuth
+func (server *Server) GetBlogRevisionById(ctx *gin.Context) {
+	var req GetBlogRevisionByIdRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	revision, err := server.store.GetBlogRevisionById(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	if _, ok := authorizeBlogEdit(*server, ctx, revision.BlogID); !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    revision,
+	})
+}
+
+type DiffBlogRevisionRequest struct {
+	FromID int64 `form:"from_id" binding:"required,min=1"`
+	ToID   int64 `form:"to_id" binding:"required,min=1"`
+}
+
+type DiffBlogRevisionResponse struct {
+	BlogID       int64           `json:"blog_id"`
+	FromID       int64           `json:"from_id"`
+	ToID         int64           `json:"to_id"`
+	TitleChanged bool            `json:"title_changed"`
+	ImageChanged bool            `json:"image_changed"`
+	UrlChanged   bool            `json:"url_changed"`
+	Content      []util.DiffLine `json:"content"`
+}
+
+// DiffBlogRevision godoc
+//
+//	@Summary		Diff Blog Revision
+//	@Description	Line-level diff of the content of two revisions of the same blog. HTML is compared with every block on a line of its own. Only for those who may edit the blog.
+//	@Tags			Blog Revision
+//	@Accept			json
+//	@Produce		json
+//	@Param			from_id	query		int	true	"Old Blog Revision ID"
+//	@Param			to_id	query		int	true	"New Blog Revision ID"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_revision/diff [get]
+//	@Security		BearerAuth
+func (server *Server) DiffBlogRevision(ctx *gin.Context) {
+	var req DiffBlogRevisionRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	from, err := server.store.GetBlogRevisionById(ctx, req.FromID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	to, err := server.store.GetBlogRevisionById(ctx, req.ToID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	if from.BlogID != to.BlogID {
+		err := errors.New("revisions belong to different blogs")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := authorizeBlogEdit(*server, ctx, from.BlogID); !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data: DiffBlogRevisionResponse{
+			BlogID:       from.BlogID,
+			FromID:       from.ID,
+			ToID:         to.ID,
+			TitleChanged: from.Title != to.Title,
+			ImageChanged: from.Image != to.Image,
+			UrlChanged:   from.Url != to.Url,
+			Content:      util.DiffLines(content.BlockLines(from.Content, from.ContentFormat), content.BlockLines(to.Content, to.ContentFormat)),
+		},
+	})
+}
+
+type RestoreBlogRevisionRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+// RestoreBlogRevision godoc
+//
+//	@Summary		Restore Blog Revision
+//	@Description	Make a revision the current version of its blog. The restore itself is recorded as a new revision. An approved or scheduled blog goes back to review, restoring onto a published blog needs publish_blog.
+//	@Tags			Blog Revision
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RestoreBlogRevisionRequest	true	"Revision to restore"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/blog_revision/restore [post]
+//	@Security		BearerAuth
+func (server *Server) RestoreBlogRevision(ctx *gin.Context) {
+	var req RestoreBlogRevisionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	revision, err := server.store.GetBlogRevisionById(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	// Make sure the blog still exists and belongs to the caller unless they may edit any blog
+	currentBlog, ok := authorizeBlogEdit(*server, ctx, revision.BlogID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// Restoring is a content change like any other edit, a reviewed post is not changed without a new review
+	status, ok := blogStatusAfterContentChange(*server, ctx, currentBlog.Status, "")
+	if !ok {
+		return
+	}
+
+	// The revision's URL may have been taken by another blog since, the current URL is kept then
+	blogURL := revision.Url
+	taken, err := server.store.ExistsBlogUrl(ctx, db.ExistsBlogUrlParams{
+		Url:       blogURL,
+		ExcludeID: revision.BlogID,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	if taken {
+		blogURL = currentBlog.Url
+	}
+
+	contentHTML, err := content.Render(revision.Content, revision.ContentFormat)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	summary := content.Summarize(contentHTML)
+
+	imageMediaID, mediaIDs, err := blogMediaReferences(*server, ctx, revision.Image, contentHTML)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	// Update Blog with the content of the revision and record the restore as a new revision
+	result, err := server.store.UpdateBlogTx(ctx, db.UpdateBlogTxParams{
+		UpdateBlogParams: db.UpdateBlogParams{
+			ID:            revision.BlogID,
+			Title:         revision.Title,
+			Content:       revision.Content,
+			ContentFormat: revision.ContentFormat,
+			ContentHtml:   contentHTML,
+			Image:         revision.Image,
+			ImageMediaID:  imageMediaID,
+			Url:           blogURL,
+			Status:        pgtype.Text{String: status, Valid: status != ""},
+			UpdatedBy:     pgtype.Int8{Int64: authPayload.UserId, Valid: true},
+			// Revisions do not track the excerpt, the author's excerpt is kept
+			Excerpt:            currentBlog.Excerpt,
+			AutoExcerpt:        summary.Excerpt,
+			WordCount:          summary.WordCount,
+			ReadingTimeMinutes: summary.ReadingTimeMinutes,
+			SearchTitle:        search.IndexText(revision.Title),
+			SearchContent:      search.IndexText(contentHTML),
+			SearchVersion:      search.IndexVersion(),
+			Version:            currentBlog.Version,
+		},
+		OldUrl:   currentBlog.Url,
+		MediaIDs: mediaIDs,
+		EditedBy: pgtype.Int8{Int64: authPayload.UserId, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrVersionConflict) {
+			blogVersionConflict(*server, ctx, revision.BlogID)
+			return
+		}
+		ctx.JSON(blogSaveError(err))
+		return
+	}
+
+	setVersionETag(ctx, result.Blog.Version)
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    result.Blog,
+	})
+}
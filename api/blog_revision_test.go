@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blog-go-api/constants"
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// revisionStore holds one blog by authorID with two revisions, 1 and 2.
+// Any other query panics on the nil embedded Store.
+type revisionStore struct {
+	db.Store
+	authorID    int64
+	permissions []string
+}
+
+func (store *revisionStore) GetBlogById(ctx context.Context, id int64) (db.GetBlogByIdRow, error) {
+	return db.GetBlogByIdRow{ID: id, AuthorID: store.authorID}, nil
+}
+
+func (store *revisionStore) GetPermissionByUserId(ctx context.Context, id int64) ([]string, error) {
+	return store.permissions, nil
+}
+
+func (store *revisionStore) GetBlogRevisionByBlogId(ctx context.Context, arg db.GetBlogRevisionByBlogIdParams) ([]db.GetBlogRevisionByBlogIdRow, error) {
+	return []db.GetBlogRevisionByBlogIdRow{}, nil
+}
+
+func (store *revisionStore) CountBlogRevisionByBlogId(ctx context.Context, blogID int64) (int64, error) {
+	return 2, nil
+}
+
+func (store *revisionStore) GetBlogRevisionById(ctx context.Context, id int64) (db.GetBlogRevisionByIdRow, error) {
+	return db.GetBlogRevisionByIdRow{ID: id, BlogID: 1, Content: "draft", ContentFormat: "markdown"}, nil
+}
+
+func TestBlogRevisionsNeedEditAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := Server{}
+	handlers := []struct {
+		name    string
+		target  string
+		params  gin.Params
+		handler gin.HandlerFunc
+	}{
+		{"list", "/api/blog_revision?blog_id=1&page_id=1&page_size=10", nil, server.GetAllBlogRevision},
+		{"get", "/api/blog_revision/2", gin.Params{{Key: "id", Value: "2"}}, server.GetBlogRevisionById},
+		{"diff", "/api/blog_revision/diff?from_id=1&to_id=2", nil, server.DiffBlogRevision},
+	}
+
+	callers := []struct {
+		name        string
+		userID      int64
+		permissions []string
+		wantStatus  int
+	}{
+		{"author", 7, nil, http.StatusOK},
+		{"editor", 8, []string{constants.PermissionEditBlog.Code}, http.StatusOK},
+		{"other author", 9, []string{constants.PermissionViewBlog.Code, constants.PermissionEditOwnBlog.Code}, http.StatusForbidden},
+	}
+
+	for _, handler := range handlers {
+		for _, caller := range callers {
+			t.Run(handler.name+" by "+caller.name, func(t *testing.T) {
+				server.store = &revisionStore{authorID: 7, permissions: caller.permissions}
+
+				recorder := httptest.NewRecorder()
+				ctx, _ := gin.CreateTestContext(recorder)
+				ctx.Request = httptest.NewRequest(http.MethodGet, handler.target, nil)
+				ctx.Params = handler.params
+				ctx.Set(authorizationPayloadKey, &token.Payload{UserId: caller.userID})
+
+				handler.handler(ctx)
+
+				if recorder.Code != caller.wantStatus {
+					t.Errorf("answered %d: %s, want %d", recorder.Code, recorder.Body.String(), caller.wantStatus)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingStore is a fake store for the blog listing that counts the queries it is asked to run.
+// Any other query panics on the nil embedded Store.
+type countingStore struct {
+	db.Store
+	queries int
+	// content replaces the body of every listed blog when set
+	content string
+}
+
+// countingCreatedAt is when the newest blog of countingStore was created, the others follow an hour apart
+var countingCreatedAt = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+// ListBlog lists blogs that were never published. No blog matches the words of a search exactly.
+func (store *countingStore) ListBlog(ctx context.Context, arg db.ListBlogParams) ([]db.ListBlogRow, error) {
+	store.queries++
+
+	blogs := make([]db.ListBlogRow, 0, arg.LimitRows)
+	if arg.Query != "" {
+		return blogs, nil
+	}
+
+	for i := int64(1); i <= int64(arg.LimitRows); i++ {
+		blog := db.ListBlogRow{
+			ID:        i,
+			Title:     fmt.Sprintf("Blog %d", i),
+			Status:    "draft",
+			CreatedAt: countingCreatedAt.Add(-time.Duration(i) * time.Hour),
+		}
+		if arg.WithContent {
+			blog.Content = fmt.Sprintf("<p>Body of blog %d</p>", i)
+			if store.content != "" {
+				blog.Content = store.content
+			}
+		}
+		blogs = append(blogs, blog)
+	}
+	return blogs, nil
+}
+
+func (store *countingStore) ListBlogByCursor(ctx context.Context, arg db.ListBlogByCursorParams) ([]db.ListBlogByCursorRow, error) {
+	blogs, err := store.ListBlog(ctx, db.ListBlogParams{WithContent: arg.WithContent, Query: arg.Query, LimitRows: arg.LimitRows})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]db.ListBlogByCursorRow, 0, len(blogs))
+	for _, blog := range blogs {
+		rows = append(rows, db.ListBlogByCursorRow(blog))
+	}
+	return rows, nil
+}
+
+func (store *countingStore) CountBlog(ctx context.Context, arg db.CountBlogParams) (int64, error) {
+	store.queries++
+	if arg.Query != "" {
+		return 0, nil
+	}
+	return 1000, nil
+}
+
+func (store *countingStore) GetSearchSuggestion(ctx context.Context, arg db.GetSearchSuggestionParams) (string, error) {
+	store.queries++
+	return "golang", nil
+}
+
+func (store *countingStore) GetBlogTagFacet(ctx context.Context, arg db.GetBlogTagFacetParams) ([]db.GetBlogTagFacetRow, error) {
+	store.queries++
+	return []db.GetBlogTagFacetRow{{ID: 1, Name: "go", Count: 1000}}, nil
+}
+
+func (store *countingStore) GetBlogTagByBlogIds(ctx context.Context, blogIDs []int64) ([]db.GetBlogTagByBlogIdsRow, error) {
+	store.queries++
+
+	rows := make([]db.GetBlogTagByBlogIdsRow, 0, len(blogIDs)*2)
+	for _, blogID := range blogIDs {
+		rows = append(rows,
+			db.GetBlogTagByBlogIdsRow{ID: blogID * 2, BlogID: blogID, TagID: 1, Name: "go"},
+			db.GetBlogTagByBlogIdsRow{ID: blogID*2 + 1, BlogID: blogID, TagID: 2, Name: "sql"},
+		)
+	}
+	return rows, nil
+}
+
+// getAllBlog runs GetAllBlog for one page and returns how many queries it took
+func getAllBlog(tb testing.TB, store *countingStore, pageSize int) int {
+	server := &Server{store: store}
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/blog?page_id=1&page_size=%d", pageSize), nil)
+
+	before := store.queries
+	server.GetAllBlog(ctx)
+
+	if recorder.Code != http.StatusOK {
+		tb.Fatalf("GetAllBlog answered %d: %s", recorder.Code, recorder.Body.String())
+	}
+	return store.queries - before
+}
+
+// BenchmarkGetAllBlog shows that a page of blogs costs the same number of queries whatever its size,
+// the tags of every blog on the page are loaded at once
+func BenchmarkGetAllBlog(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	want := getAllBlog(b, &countingStore{}, 1)
+
+	for _, pageSize := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("page_size=%d", pageSize), func(b *testing.B) {
+			store := &countingStore{}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if got := getAllBlog(b, store, pageSize); got != want {
+					b.Fatalf("a page of %d blogs took %d queries, a page of 1 took %d", pageSize, got, want)
+				}
+			}
+			b.ReportMetric(float64(store.queries)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+// blogListResponse is the body of a blog listing in either pagination mode
+type blogListResponse struct {
+	Data       []map[string]json.RawMessage `json:"data"`
+	NextCursor string                       `json:"next_cursor"`
+	DidYouMean string                       `json:"did_you_mean"`
+	Facets     *GetAllBlogFacets            `json:"facets"`
+}
+
+// requestBlogList runs GetAllBlog with query and returns the status and body of its response
+func requestBlogList(t *testing.T, store db.Store, query string) (int, blogListResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{store: store}
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/api/blog?"+query, nil)
+
+	server.GetAllBlog(ctx)
+
+	var response blogListResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return recorder.Code, response
+}
+
+// listBlogItems runs GetAllBlog with query and returns the blogs of the page as JSON objects
+func listBlogItems(t *testing.T, store db.Store, query string) []map[string]json.RawMessage {
+	t.Helper()
+
+	code, response := requestBlogList(t, store, query)
+	if code != http.StatusOK {
+		t.Fatalf("GetAllBlog answered %d to %s", code, query)
+	}
+	return response.Data
+}
+
+func TestGetAllBlogContentIsOptIn(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantContent bool
+		wantFields  []string
+	}{
+		{"default", "page_id=1&page_size=2", false, []string{"id", "title", "excerpt", "blog_tags"}},
+		{"asked for", "page_id=1&page_size=2&fields=id,content", true, []string{"id"}},
+		{"other fields", "page_id=1&page_size=2&fields=id,title", false, []string{"id", "title"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, item := range listBlogItems(t, &countingStore{}, test.query) {
+				content, ok := item["content"]
+				if ok != test.wantContent {
+					t.Fatalf("content sent = %v, want %v in %v", ok, test.wantContent, item)
+				}
+				if ok && string(content) == `""` {
+					t.Errorf("content was asked for but not loaded")
+				}
+
+				for _, field := range test.wantFields {
+					if _, ok := item[field]; !ok {
+						t.Errorf("field %s missing from %v", field, item)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestGetAllBlogSanitizesContent covers posts whose content_html still holds the HTML copied
+// by the migration to content formats, before the reindex job rendered them
+func TestGetAllBlogSanitizesContent(t *testing.T) {
+	store := &countingStore{
+		content: `<p onclick="steal()">Hello<script>alert(1)</script><img src="x.png" onerror="steal()"></p>`,
+	}
+
+	for _, query := range []string{
+		"page_id=1&page_size=3&fields=id,content",
+		"cursor=&page_size=3&fields=content",
+	} {
+		for _, item := range listBlogItems(t, store, query) {
+			var content string
+			if err := json.Unmarshal(item["content"], &content); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := `<p>Hello<img src="x.png"></p>`; content != want {
+				t.Errorf("%s: content = %q, want %q", query, content, want)
+			}
+		}
+	}
+}
+
+func TestGetAllBlogByCursor(t *testing.T) {
+	if code, _ := requestBlogList(t, &countingStore{}, "page_size=2"); code != http.StatusBadRequest {
+		t.Errorf("a listing without page_id nor cursor answered %d, want %d", code, http.StatusBadRequest)
+	}
+
+	code, first := requestBlogList(t, &countingStore{}, "cursor=&page_size=2")
+	if code != http.StatusOK {
+		t.Fatalf("the first page by cursor answered %d", code)
+	}
+	if len(first.Data) != 2 || first.Facets == nil || len(first.Facets.Tags) == 0 {
+		t.Errorf("the first page by cursor has %d blogs and facets %+v, want 2 blogs and the tag facets", len(first.Data), first.Facets)
+	}
+
+	// The blogs were never published, they are listed by creation date
+	listedAt, id, err := util.DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("next_cursor %q: %v", first.NextCursor, err)
+	}
+	if want := countingCreatedAt.Add(-2 * time.Hour); !listedAt.Equal(want) || id != 2 {
+		t.Errorf("next_cursor points after (%v, %d), want (%v, 2)", listedAt, id, want)
+	}
+
+	code, fuzzy := requestBlogList(t, &countingStore{}, "cursor=&page_size=2&q=goland")
+	if code != http.StatusOK {
+		t.Fatalf("a search by cursor answered %d", code)
+	}
+	if len(fuzzy.Data) != 2 || fuzzy.DidYouMean != "golang" {
+		t.Errorf("a search matching nothing exactly has %d blogs and did_you_mean %q, want the typo tolerant matches and golang",
+			len(fuzzy.Data), fuzzy.DidYouMean)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"blog-go-api/content"
+	db "blog-go-api/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBlogSlugSuffix bounds the search for a free slug, past it the title is almost certainly spam
+const maxBlogSlugSuffix = 100
+
+var errBlogUrlTaken = errors.New("url is already used by another blog")
+
+// resolveBlogUrl returns the slug a blog is saved under. A URL given by the author is normalized and must be free,
+// otherwise the slug is generated from the title and numbered until it is free (go, go-2, go-3, ...).
+// Pass blogID 0 for a new blog. On failure the error response has already been written.
+func resolveBlogUrl(server Server, ctx *gin.Context, blogID int64, requestedURL string, title string) (string, bool) {
+	if requestedURL != "" {
+		slug := content.Slugify(requestedURL)
+
+		taken, err := server.store.ExistsBlogUrl(ctx, db.ExistsBlogUrlParams{
+			Url:       slug,
+			ExcludeID: blogID,
+		})
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return "", false
+		}
+		if taken {
+			ctx.JSON(http.StatusConflict, errorResponse(errBlogUrlTaken))
+			return "", false
+		}
+
+		return slug, true
+	}
+
+	slug := content.Slugify(title)
+	for n := 1; n <= maxBlogSlugSuffix; n++ {
+		candidate := slug
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", slug, n)
+		}
+
+		taken, err := server.store.ExistsBlogUrl(ctx, db.ExistsBlogUrlParams{
+			Url:       candidate,
+			ExcludeID: blogID,
+		})
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return "", false
+		}
+		if !taken {
+			return candidate, true
+		}
+	}
+
+	ctx.JSON(http.StatusConflict, errorResponse(errBlogUrlTaken))
+	return "", false
+}
+
+// blogSaveError maps a failed blog insert or update to an error response,
+// a concurrent save taking the same URL surfaces as a unique violation
+func blogSaveError(err error) (int, gin.H) {
+	if db.ErrorCode(err) == db.UniqueViolation {
+		return http.StatusConflict, errorResponse(errBlogUrlTaken)
+	}
+	return errorStatus(err), errorResponse(err)
+}
+
+type BlogRedirectResponse struct {
+	ID  int64  `json:"id"`
+	URL string `json:"url"`
+}
+
+// redirectBlog answers a request for an old blog URL with 301 and the current URL.
+// It reports false when the URL never belonged to a visible blog.
+func redirectBlog(server Server, ctx *gin.Context, oldURL string, statuses []string) (bool, error) {
+	redirect, err := server.store.GetBlogRedirect(ctx, db.GetBlogRedirectParams{
+		OldUrl:   oldURL,
+		Statuses: statuses,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	ctx.Header("Location", "/api/blog/"+url.PathEscape(redirect.Url))
+	ctx.JSON(http.StatusMovedPermanently, jsonResponse{
+		Error:   false,
+		Message: "moved permanently",
+		Data: BlogRedirectResponse{
+			ID:  redirect.ID,
+			URL: redirect.Url,
+		},
+	})
+	return true, nil
+}
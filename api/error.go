@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/storage"
+)
+
+var (
+	errBlogNotFound = errors.New("blog not found")
+	errBlogGone     = errors.New("blog has been deleted")
+
+	errBlogPageMissing = errors.New("page_id is required unless paging with cursor")
+)
+
+// errorStatus maps an error to the status of its response. Errors from the store are translated
+// from their PostgreSQL error code, so a handler never reports a missing or conflicting row as a server failure.
+func errorStatus(err error) int {
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, db.ErrVersionConflict) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, storage.ErrObjectNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return http.StatusNotFound
+	}
+
+	switch db.ErrorCode(err) {
+	case db.UniqueViolation, db.ForeignKeyViolation:
+		return http.StatusConflict
+	case db.CheckViolation, db.NotNullViolation:
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseFields validates the fields= parameter of a listing against the JSON fields of its item type.
+// Without fields= every field is returned but the optIn ones, which are only sent when asked for by name.
+// It returns nil when every field is wanted.
+func parseFields[T any](fields string, optIn ...string) ([]string, error) {
+	// The zero value lists every field an item can have
+	var zero T
+	allowed, err := jsonFields(zero)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(fields) == "" {
+		if len(optIn) == 0 {
+			return nil, nil
+		}
+
+		var selected []string
+		for field := range allowed {
+			if !slices.Contains(optIn, field) {
+				selected = append(selected, field)
+			}
+		}
+		slices.Sort(selected)
+		return selected, nil
+	}
+
+	var selected []string
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := allowed[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		selected = append(selected, field)
+	}
+
+	return selected, nil
+}
+
+// selectFields keeps only the selected JSON fields of every item.
+// Nil selected returns the items unchanged.
+func selectFields[T any](items []T, selected []string) (interface{}, error) {
+	if selected == nil {
+		return items, nil
+	}
+
+	result := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		values, err := jsonFields(item)
+		if err != nil {
+			return nil, err
+		}
+
+		projected := make(map[string]json.RawMessage, len(selected))
+		for _, field := range selected {
+			projected[field] = values[field]
+		}
+		result = append(result, projected)
+	}
+
+	return result, nil
+}
+
+// jsonFields encodes value as a JSON object and returns its fields
+func jsonFields(value interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,468 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"blog-go-api/content"
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/search"
+	"blog-go-api/storage"
+	"blog-go-api/token"
+	"blog-go-api/upload"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// maxMediaUploadSize caps a single upload request, the request is cut off as soon as it goes over.
+// The file itself is held to the limit of its type, this leaves room for the multipart framing around it.
+var maxMediaUploadSize = upload.MaxSize() + 1024*1024
+
+// mediaFileField is the multipart field holding the uploaded file
+const mediaFileField = "file"
+
+// mediaHeaderSize is how much of an upload is read up front to identify it and read the dimensions of the image from
+const mediaHeaderSize = 256 * 1024
+
+var (
+	errMediaNotFound    = errors.New("media not found")
+	errMediaFileMissing = errors.New("the upload has no " + mediaFileField + " field")
+	errMediaInUse       = errors.New("media is used by a blog, remove it from the blog first")
+)
+
+// storedImage is an upload that passed validation and was put in storage
+type storedImage struct {
+	upload.Image
+	Object   storage.ObjectInfo
+	Checksum string
+}
+
+// storeImage identifies an image from its content, whatever type the client declared, checks it against the
+// allowlist and the limits of its type and puts it in storage under a new key. SVGs are stored sanitized.
+func storeImage(server Server, ctx *gin.Context, r io.Reader) (storedImage, error) {
+	header := make([]byte, mediaHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return storedImage{}, err
+	}
+	header = header[:n]
+
+	img, err := upload.Inspect(header)
+	if err != nil {
+		return storedImage{}, err
+	}
+
+	body := upload.LimitReader(io.MultiReader(bytes.NewReader(header), r), img.Type.MaxSize)
+	size := int64(-1)
+
+	if img.Type == upload.SVG {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return storedImage{}, err
+		}
+		data, err = upload.SanitizeSVG(data)
+		if err != nil {
+			return storedImage{}, err
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	// The checksum is taken while the file streams through
+	hash := sha256.New()
+
+	object, err := server.storage.Put(ctx, uuid.New().String()+img.Type.Extension, io.TeeReader(body, hash), size, img.Type.ContentType)
+	if err != nil {
+		return storedImage{}, err
+	}
+
+	return storedImage{
+		Image:    img,
+		Object:   object,
+		Checksum: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// mediaUploadError maps an error reading, validating or storing an upload to its response
+func mediaUploadError(err error) (int, gin.H) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || errors.Is(err, upload.ErrTooLarge) {
+		return http.StatusRequestEntityTooLarge, errorResponse(upload.ErrTooLarge)
+	}
+	if errors.Is(err, upload.ErrUnsupportedType) {
+		return http.StatusUnsupportedMediaType, errorResponse(err)
+	}
+	if errors.Is(err, upload.ErrInvalidImage) || errors.Is(err, upload.ErrTooManyPixels) {
+		return http.StatusUnprocessableEntity, errorResponse(err)
+	}
+	return errorStatus(err), errorResponse(err)
+}
+
+// optionalInt4 is null for a zero value
+func optionalInt4(value int) pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(value), Valid: value != 0}
+}
+
+// removeMediaObject deletes a stored file. The database no longer points at it, so a failure only leaves an orphan behind.
+func removeMediaObject(server Server, ctx *gin.Context, objectKey string) {
+	if err := server.storage.Delete(ctx, objectKey); err != nil {
+		log.Error().Err(err).Str("object_key", objectKey).Msg("cannot remove media object")
+	}
+}
+
+// saveMedia adds a stored image to the media library. When the same file is already in the library, also when it
+// was uploaded at the same moment, the existing media item is returned and the new copy is removed from storage.
+func saveMedia(server Server, ctx *gin.Context, stored storedImage, originalName string, uploadedBy int64) (db.Media, error) {
+	checksum := pgtype.Text{String: stored.Checksum, Valid: true}
+
+	media, err := server.store.CreateMedia(ctx, db.CreateMediaParams{
+		ObjectKey:    stored.Object.Key,
+		Url:          server.storage.URL(stored.Object.Key),
+		ContentType:  stored.Type.ContentType,
+		Size:         stored.Object.Size,
+		Width:        optionalInt4(stored.Width),
+		Height:       optionalInt4(stored.Height),
+		Checksum:     checksum,
+		OriginalName: originalName,
+		UploadedBy:   pgtype.Int8{Int64: uploadedBy, Valid: true},
+	})
+	if err != nil {
+		removeMediaObject(server, ctx, stored.Object.Key)
+
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			return db.Media{}, err
+		}
+		return server.store.GetMediaByChecksum(ctx, checksum)
+	}
+
+	return media, nil
+}
+
+// blogMediaReferences finds the media items a blog uses: its cover image and the images inline in its content
+func blogMediaReferences(server Server, ctx *gin.Context, image string, contentHTML string) (pgtype.Int8, []int64, error) {
+	var cover pgtype.Int8
+	mediaIDs := []int64{}
+
+	sources := content.ImageSources(contentHTML)
+	inline := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		inline[source] = true
+	}
+
+	rows, err := server.store.GetMediaByUrls(ctx, append(sources, image))
+	if err != nil {
+		return cover, nil, err
+	}
+
+	for _, row := range rows {
+		if row.Url == image {
+			cover = pgtype.Int8{Int64: row.ID, Valid: true}
+		}
+		if inline[row.Url] {
+			mediaIDs = append(mediaIDs, row.ID)
+		}
+	}
+
+	return cover, mediaIDs, nil
+}
+
+type GetAllMediaRequest struct {
+	Q           string `form:"q"`
+	ContentType string `form:"content_type"`
+	PageID      int32  `form:"page_id" binding:"required,min=1"`
+	PageSize    int32  `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+// GetAllMedia godoc
+//
+//	@Summary		Get All Media
+//	@Description	Search the media library by file name, alt text and caption, newest first
+//	@Tags			Media
+//	@Accept			json
+//	@Produce		json
+//	@Param			q				query		string	false	"Search text"
+//	@Param			content_type	query		string	false	"Content type, e.g. image/png"
+//	@Param			page_id			query		int		true	"Page ID"
+//	@Param			page_size		query		int		true	"Page Size"
+//	@Success		200				{object}	jsonResponseWithPaginate
+//	@Router			/api/media [get]
+//	@Security		BearerAuth
+func (server *Server) GetAllMedia(ctx *gin.Context) {
+	var req GetAllMediaRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	query := search.EscapeLike(strings.TrimSpace(req.Q))
+
+	media, err := server.store.ListMedia(ctx, db.ListMediaParams{
+		Query:       query,
+		ContentType: req.ContentType,
+		OffsetRows:  (req.PageID - 1) * req.PageSize,
+		LimitRows:   req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	count, err := server.store.CountMedia(ctx, db.CountMediaParams{
+		Query:       query,
+		ContentType: req.ContentType,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponseWithPaginate{
+		jsonResponse: jsonResponse{
+			Error:   false,
+			Message: "successfully",
+			Data:    media,
+		},
+		Total: count,
+	})
+}
+
+type GetMediaByIdRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type GetMediaByIdResponse struct {
+	db.GetMediaByIdRow
+	// Blogs use the media as their cover or inline, blogs in the trash included
+	Blogs []db.ListBlogByMediaIdRow `json:"blogs"`
+}
+
+// GetMediaById godoc
+//
+//	@Summary		Get Media By ID
+//	@Description	Get a media item and the blogs using it
+//	@Tags			Media
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Media ID"
+//	@Success		200	{object}	jsonResponse
+//	@Failure		404	{object}	jsonResponse
+//	@Router			/api/media/{id} [get]
+//	@Security		BearerAuth
+func (server *Server) GetMediaById(ctx *gin.Context) {
+	var req GetMediaByIdRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	media, err := server.store.GetMediaById(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errMediaNotFound))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	blogs, err := server.store.ListBlogByMediaId(ctx, media.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data: GetMediaByIdResponse{
+			GetMediaByIdRow: media,
+			Blogs:           blogs,
+		},
+	})
+}
+
+// UploadMedia godoc
+//
+//	@Summary		Upload Media
+//	@Description	Upload a JPEG, PNG, WebP, GIF, AVIF or SVG image as multipart/form-data. The type is detected from the content and SVGs are sanitized. The file is streamed to object storage and its id can be sent as image_media_id when saving a blog.
+//	@Description	Uploading a file that is already in the library returns the existing media item.
+//	@Tags			Media
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			file	formData	file	true	"Image"
+//	@Success		200		{object}	jsonResponse
+//	@Failure		413		{object}	jsonResponse	"The file is over the size limit of its type"
+//	@Failure		415		{object}	jsonResponse	"The file is not a JPEG, PNG, WebP, GIF, AVIF or SVG image"
+//	@Failure		422		{object}	jsonResponse	"The image is damaged or over the pixel limit of its type"
+//	@Router			/api/media [post]
+//	@Security		BearerAuth
+func (server *Server) UploadMedia(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxMediaUploadSize)
+
+	// Read the parts as they arrive instead of parsing the whole form, which would buffer the file on disk
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			ctx.JSON(http.StatusBadRequest, errorResponse(errMediaFileMissing))
+			return
+		}
+		if err != nil {
+			ctx.JSON(mediaUploadError(err))
+			return
+		}
+
+		if part.FormName() != mediaFileField {
+			part.Close()
+			continue
+		}
+
+		// The declared content type is not trusted, the file is identified from its bytes
+		stored, err := storeImage(*server, ctx, part)
+		if err != nil {
+			ctx.JSON(mediaUploadError(err))
+			return
+		}
+
+		var originalName string
+		if part.FileName() != "" {
+			originalName = filepath.Base(part.FileName())
+			if runes := []rune(originalName); len(runes) > 255 {
+				originalName = string(runes[:255])
+			}
+		}
+
+		media, err := saveMedia(*server, ctx, stored, originalName, authPayload.UserId)
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, jsonResponse{
+			Error:   false,
+			Message: "successfully",
+			Data:    media,
+		})
+		return
+	}
+}
+
+type UpdateMediaRequest struct {
+	ID      int64  `json:"id" binding:"required,min=1"`
+	AltText string `json:"alt_text" binding:"max=500"`
+	Caption string `json:"caption"`
+}
+
+// UpdateMedia godoc
+//
+//	@Summary		Update Media
+//	@Description	Update the alt text and caption of a media item
+//	@Tags			Media
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		UpdateMediaRequest	true	"Media information"
+//	@Success		200		{object}	jsonResponse
+//	@Failure		404		{object}	jsonResponse
+//	@Router			/api/media [put]
+//	@Security		BearerAuth
+func (server *Server) UpdateMedia(ctx *gin.Context) {
+	var req UpdateMediaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	media, err := server.store.UpdateMedia(ctx, db.UpdateMediaParams{
+		ID:      req.ID,
+		AltText: strings.TrimSpace(req.AltText),
+		Caption: strings.TrimSpace(req.Caption),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errMediaNotFound))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    media,
+	})
+}
+
+type DeleteMediaRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// DeleteMedia godoc
+//
+//	@Summary		Delete Media
+//	@Description	Delete a media item and its file. Media used by a blog, including blogs in the trash, cannot be deleted.
+//	@Tags			Media
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Media ID"
+//	@Success		200	{object}	jsonResponse
+//	@Failure		404	{object}	jsonResponse
+//	@Failure		409	{object}	jsonResponse	"The media is in use, data lists the blogs using it"
+//	@Router			/api/media/{id} [delete]
+//	@Security		BearerAuth
+func (server *Server) DeleteMedia(ctx *gin.Context) {
+	var req DeleteMediaRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	media, err := server.store.DeleteMedia(ctx, req.ID)
+	if err != nil {
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
+
+		// Nothing was deleted, either the media does not exist or a blog still uses it
+		blogs, err := server.store.ListBlogByMediaId(ctx, req.ID)
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(err))
+			return
+		}
+		if len(blogs) == 0 {
+			ctx.JSON(http.StatusNotFound, errorResponse(errMediaNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusConflict, jsonResponse{
+			Error:   true,
+			Message: errMediaInUse.Error(),
+			Data:    blogs,
+		})
+		return
+	}
+
+	removeMediaObject(*server, ctx, media.ObjectKey)
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    nil,
+	})
+}
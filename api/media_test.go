@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/storage"
+	"blog-go-api/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// mediaStore keeps the media library in memory, CreateMedia skips a checksum already in it like ON CONFLICT DO NOTHING.
+// Any other query panics on the nil embedded Store.
+type mediaStore struct {
+	db.Store
+	media []db.Media
+}
+
+func (store *mediaStore) CreateMedia(ctx context.Context, arg db.CreateMediaParams) (db.Media, error) {
+	if _, err := store.GetMediaByChecksum(ctx, arg.Checksum); err == nil {
+		return db.Media{}, db.ErrRecordNotFound
+	}
+
+	media := db.Media{
+		ID:          int64(len(store.media) + 1),
+		ObjectKey:   arg.ObjectKey,
+		Url:         arg.Url,
+		ContentType: arg.ContentType,
+		Size:        arg.Size,
+		Checksum:    arg.Checksum,
+		UploadedBy:  arg.UploadedBy,
+	}
+	store.media = append(store.media, media)
+	return media, nil
+}
+
+func (store *mediaStore) GetMediaByChecksum(ctx context.Context, checksum pgtype.Text) (db.Media, error) {
+	for _, media := range store.media {
+		if media.Checksum == checksum {
+			return media, nil
+		}
+	}
+	return db.Media{}, db.ErrRecordNotFound
+}
+
+// objectCounter counts the objects kept in the backend it wraps
+type objectCounter struct {
+	storage.Backend
+	objects int
+}
+
+func (backend *objectCounter) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (storage.ObjectInfo, error) {
+	backend.objects++
+	return backend.Backend.Put(ctx, key, r, size, contentType)
+}
+
+func (backend *objectCounter) Delete(ctx context.Context, key string) error {
+	backend.objects--
+	return backend.Backend.Delete(ctx, key)
+}
+
+func TestResolveBlogImageAddsBase64ImagesToTheMediaLibrary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+	dataURL := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	store := &mediaStore{}
+	backend := &objectCounter{Backend: storage.NewMemoryBackend("/api/storage/")}
+	server := Server{store: store, storage: backend}
+
+	var urls []string
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/api/blog", nil)
+		ctx.Set(authorizationPayloadKey, &token.Payload{UserId: 7})
+
+		url, ok := resolveBlogImage(server, ctx, dataURL, 0)
+		if !ok {
+			t.Fatalf("resolveBlogImage answered %d: %s", recorder.Code, recorder.Body.String())
+		}
+		urls = append(urls, url)
+	}
+
+	if len(store.media) != 1 {
+		t.Fatalf("the same image twice made %d media items, want 1", len(store.media))
+	}
+	media := store.media[0]
+	if urls[0] != media.Url || urls[1] != media.Url {
+		t.Errorf("resolveBlogImage returned %v, want the URL of the media item %s both times", urls, media.Url)
+	}
+	if media.ContentType != "image/png" || media.UploadedBy.Int64 != 7 {
+		t.Errorf("media item is %+v", media)
+	}
+	if backend.objects != 1 {
+		t.Errorf("%d objects left in storage, want 1", backend.objects)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 
+	"blog-go-api/token"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -53,7 +55,7 @@ func authMiddleware(server Server, permission_code_for_check *[]string) gin.Hand
 
 			permissions, err := server.store.GetPermissionByUserId(ctx, payload.UserId)
 			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				ctx.JSON(errorStatus(err), errorResponse(err))
 				return
 			}
 
@@ -112,3 +114,38 @@ func getUserFromContext(server Server, ctx *gin.Context) (*int64, error) {
 
 	return &payload.UserId, nil
 }
+
+// getOptionalAuthPayload returns the token payload when the request carries a
+// valid bearer token and nil otherwise. Unlike authMiddleware it never aborts,
+// so public endpoints can use it to widen what signed-in staff are allowed to see.
+func getOptionalAuthPayload(server Server, ctx *gin.Context) *token.Payload {
+	fields := strings.Fields(ctx.GetHeader(authorizationHeaderKey))
+	if len(fields) < 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+		return nil
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil
+	}
+
+	return payload
+}
+
+// hasPermission reports whether the user holds at least one of the given permission codes
+func hasPermission(server Server, ctx *gin.Context, userId int64, permission_codes ...string) (bool, error) {
+	permissions, err := server.store.GetPermissionByUserId(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, pc := range permission_codes {
+		for _, p := range permissions {
+			if p == pc {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
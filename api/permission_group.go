@@ -45,7 +45,7 @@ func (server *Server) GetAllPermissionGroup(ctx *gin.Context) {
 
 	permissionGroups, err := server.store.GetAllPermissionGroup(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -60,7 +60,7 @@ func (server *Server) GetAllPermissionGroup(ctx *gin.Context) {
 
 		permissions, err := server.store.GetPermissionByPermissionGroupIdAndRoleId(ctx, arg)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			ctx.JSON(errorStatus(err), errorResponse(err))
 			return
 		}
 
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // @Summary		Get Profile
@@ -15,6 +16,7 @@ import (
 // @Accept			json
 // @Produce		json
 // @Success		200	{object}	userResponse
+// @Header			200	{string}	ETag	"Version of the profile, send it back as If-Match when saving"
 // @Router			/api/profile [get]
 // @Security		BearerAuth
 func (server *Server) GetProfile(ctx *gin.Context) {
@@ -31,10 +33,11 @@ func (server *Server) GetProfile(ctx *gin.Context) {
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
+	setVersionETag(ctx, profile.Version)
 	payload := jsonResponse{
 		Error:   false,
 		Message: "Get profile successfully",
@@ -44,13 +47,26 @@ func (server *Server) GetProfile(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, payload)
 }
 
+type updateProfileRequest struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	Phone       string `json:"phone"`
+	Description string `json:"description"`
+	Version     int32  `json:"version" binding:"omitempty,min=1"` // the version the changes are based on, required unless sent as If-Match
+}
+
 // @Summary		Update Profile
 // @Description	Update Profile
 // @Tags			Profile
 // @Accept			json
 // @Produce		json
-// @Param			input	body		updateUserRequest	true	"Update information"
-// @Success		200		{object}	userResponse
+// @Param			input		body		updateProfileRequest	true	"Update information"
+// @Param			If-Match	header		string					false	"ETag of the version the changes are based on, instead of version in the body"
+// @Success		200			{object}	userResponse
+// @Header			200			{string}	ETag	"Version of the saved profile"
+// @Failure		409			{object}	jsonResponse	"The profile was changed in the meantime, data holds the current copy"
+// @Failure		428			{object}	jsonResponse	"Neither version nor If-Match was sent"
 // @Router			/api/profile [put]
 // @Security		BearerAuth
 func (server *Server) UpdateProfile(ctx *gin.Context) {
@@ -60,29 +76,57 @@ func (server *Server) UpdateProfile(ctx *gin.Context) {
 		return
 	}
 
-	var req db.UpdateUserParams
+	var req updateProfileRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	req.UserID = *userId
+	currentUser, err := server.store.GetUser(ctx, *userId)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	version, ok := expectedVersion(ctx, req.Version, currentUser.Version)
+	if !ok {
+		return
+	}
 
 	// Update User
-	_, err = server.store.UpdateUser(ctx, req)
+	_, err = server.store.UpdateUser(ctx, db.UpdateUserParams{
+		UserID:      *userId,
+		Version:     version,
+		FirstName:   pgtype.Text{String: req.FirstName, Valid: req.FirstName != ""},
+		LastName:    pgtype.Text{String: req.LastName, Valid: req.LastName != ""},
+		Email:       pgtype.Text{String: req.Email, Valid: req.Email != ""},
+		Phone:       pgtype.Text{String: req.Phone, Valid: req.Phone != ""},
+		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		// The user was loaded above, a missing row means the profile was saved in the meantime
+		if errors.Is(err, db.ErrRecordNotFound) {
+			user, err := server.store.GetUser(ctx, *userId)
+			if err != nil {
+				ctx.JSON(errorStatus(err), errorResponse(err))
+				return
+			}
+			versionConflict(ctx, user.Version, user)
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	// Get Updated User
 	user, err := server.store.GetUser(ctx, *userId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	// Response
+	setVersionETag(ctx, user.Version)
 	payload := jsonResponse{
 		Error:   false,
 		Message: "Update profile successfully",
@@ -122,7 +166,7 @@ func (server *Server) ChangePassword(ctx *gin.Context) {
 	// Get HashedPassword
 	hashedPassword, err := server.store.GetUserHashedPassword(ctx, *userId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -141,7 +185,7 @@ func (server *Server) ChangePassword(ctx *gin.Context) {
 	// Hash New Password
 	hashedPassword, err = util.HashPassword(req.NewPassword)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -152,7 +196,7 @@ func (server *Server) ChangePassword(ctx *gin.Context) {
 
 	err = server.store.UpdateUserPassword(ctx, updateUserPasswordParams)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/token"
+	"blog-go-api/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// profileStore holds a single user at version, UpdateUser only saves a change based on that version.
+// Any other query panics on the nil embedded Store.
+type profileStore struct {
+	db.Store
+	version int32
+	updates int
+}
+
+func (store *profileStore) GetUser(ctx context.Context, id int64) (db.GetUserRow, error) {
+	return db.GetUserRow{ID: id, Version: store.version}, nil
+}
+
+func (store *profileStore) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	if arg.Version != store.version {
+		return db.User{}, db.ErrRecordNotFound
+	}
+	store.updates++
+	store.version++
+	return db.User{ID: arg.UserID, Version: store.version}, nil
+}
+
+func TestUpdateProfileChecksVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessToken, _, err := tokenMaker.CreateToken(7, "user", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		ifMatch    string
+		wantStatus int
+		wantETag   string
+	}{
+		{"no version", `{"first_name":"Ann"}`, "", http.StatusPreconditionRequired, ""},
+		{"version in the body", `{"first_name":"Ann","version":5}`, "", http.StatusOK, `"6"`},
+		{"weak etag", `{"first_name":"Ann"}`, `W/"5"`, http.StatusOK, `"6"`},
+		{"stale version", `{"first_name":"Ann","version":4}`, "", http.StatusConflict, `"5"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &profileStore{version: 5}
+			server := &Server{store: store, tokenMaker: tokenMaker}
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPut, "/api/profile", strings.NewReader(test.body))
+			ctx.Request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+			if test.ifMatch != "" {
+				ctx.Request.Header.Set("If-Match", test.ifMatch)
+			}
+
+			server.UpdateProfile(ctx)
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("UpdateProfile answered %d: %s, want %d", recorder.Code, recorder.Body.String(), test.wantStatus)
+			}
+			if got := recorder.Header().Get("ETag"); got != test.wantETag {
+				t.Errorf("ETag = %q, want %q", got, test.wantETag)
+			}
+			wantUpdates := 0
+			if test.wantStatus == http.StatusOK {
+				wantUpdates = 1
+			}
+			if store.updates != wantUpdates {
+				t.Errorf("%d updates saved, want %d", store.updates, wantUpdates)
+			}
+		})
+	}
+}
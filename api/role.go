@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	db "blog-go-api/db/sqlc"
 
@@ -43,13 +45,13 @@ func (server *Server) GetAllRole(ctx *gin.Context) {
 
 	roles, err := server.store.GetAllRole(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	count, err := server.store.CountAllRole(ctx, req.Name)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -78,6 +80,7 @@ type GetRoleByIdRequest struct {
 //	@Produce		json
 //	@Param			id	path		int	true	"Role ID"
 //	@Success		200	{object}	jsonResponse
+//	@Header			200	{string}	ETag	"Version of the role, send it back as If-Match when saving"
 //	@Router			/api/role/{id} [get]
 //	@Security		BearerAuth
 func (server *Server) GetRoleById(ctx *gin.Context) {
@@ -90,10 +93,11 @@ func (server *Server) GetRoleById(ctx *gin.Context) {
 
 	role, err := server.store.GetRoleById(ctx, req.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
+	setVersionETag(ctx, role.Version)
 	ctx.JSON(http.StatusOK, jsonResponse{
 		Error:   false,
 		Message: "successfully",
@@ -125,33 +129,13 @@ func (server *Server) CreateRole(ctx *gin.Context) {
 		return
 	}
 
-	role, err := server.store.CreateRole(ctx, req.Name)
+	// Create the role together with its assigned permissions
+	role, err := server.store.CreateRoleTx(ctx, req.Name, rolePermissionChanges(req.PermissionGroups))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	// Insert Role Permission
-	for _, pg := range req.PermissionGroups {
-		for _, p := range pg.Permissions {
-
-			// Check exists role_permission
-			// if is_assigned == true, insert
-			if p.IsAssigned {
-				argCreateRolePermission := db.CreateRolePermissionParams{
-					RoleID:       role.ID,
-					PermissionID: p.ID,
-				}
-
-				_, err := server.store.CreateRolePermission(ctx, argCreateRolePermission)
-				if err != nil {
-					ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-					return
-				}
-			}
-		}
-	}
-
 	ctx.JSON(http.StatusOK, jsonResponse{
 		Error:   false,
 		Message: "successfully",
@@ -159,10 +143,36 @@ func (server *Server) CreateRole(ctx *gin.Context) {
 	})
 }
 
+// rolePermissionChanges flattens the permission groups of a role request
+func rolePermissionChanges(groups []getAllPermissionGroupData) []db.RolePermissionChange {
+	var changes []db.RolePermissionChange
+	for _, pg := range groups {
+		for _, p := range pg.Permissions {
+			changes = append(changes, db.RolePermissionChange{
+				PermissionID: p.ID,
+				Assigned:     p.IsAssigned,
+			})
+		}
+	}
+	return changes
+}
+
 type UpdateRoleRequest struct {
 	ID               int64                       `json:"id" binding:"required,min=1"`
 	Name             string                      `json:"name" binding:"required"`
 	PermissionGroups []getAllPermissionGroupData `json:"permission_groups"`
+	Version          int32                       `json:"version" binding:"omitempty,min=1"` // the version the changes are based on, required unless sent as If-Match
+}
+
+// roleVersionConflict answers a save based on an outdated version with the current copy of the role
+func roleVersionConflict(server Server, ctx *gin.Context, roleID int64) {
+	role, err := server.store.GetRoleById(ctx, roleID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	versionConflict(ctx, role.Version, role)
 }
 
 // UpdateRole godoc
@@ -172,8 +182,13 @@ type UpdateRoleRequest struct {
 //	@Tags			Role
 //	@Accept			json
 //	@Produce		json
-//	@Param			input	body		UpdateRoleRequest	true	"Role Information"
-//	@Success		200		{object}	jsonResponse
+//	@Param			input		body		UpdateRoleRequest	true	"Role Information"
+//	@Param			If-Match	header		string				false	"ETag of the version the changes are based on, instead of version in the body"
+//	@Success		200			{object}	jsonResponse
+//	@Header			200			{string}	ETag	"Version of the saved role"
+//	@Failure		404			{object}	jsonResponse
+//	@Failure		409			{object}	jsonResponse	"The role was changed by someone else, data holds the current copy"
+//	@Failure		428			{object}	jsonResponse	"Neither version nor If-Match was sent"
 //	@Router			/api/role [put]
 //	@Security		BearerAuth
 func (server *Server) UpdateRole(ctx *gin.Context) {
@@ -184,62 +199,39 @@ func (server *Server) UpdateRole(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.UpdateRoleParams{
-		ID:   req.ID,
-		Name: req.Name,
+	currentRole, err := server.store.GetRoleById(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
 	}
 
-	// Update Role
-	err := server.store.UpdateRole(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	version, ok := expectedVersion(ctx, req.Version, currentRole.Version)
+	if !ok {
 		return
 	}
 
-	// Insert and Delete Role Permission
-	for _, pg := range req.PermissionGroups {
-		for _, p := range pg.Permissions {
+	arg := db.UpdateRoleParams{
+		ID:      req.ID,
+		Name:    req.Name,
+		Version: version,
+	}
 
-			argGetRolePermissionByRoleIdAndPermissionId := db.GetRolePermissionByRoleIdAndPermissionIdParams{
-				RoleID:       req.ID,
-				PermissionID: p.ID,
-			}
-
-			// Check exists role_permission
-			// if exists and is_assigned == false, delete
-			// if not exists and is_assigned == true, insert
-			rolePermissionExists, _ := server.store.GetRolePermissionByRoleIdAndPermissionId(ctx, argGetRolePermissionByRoleIdAndPermissionId)
-
-			if rolePermissionExists.ID == 0 && p.IsAssigned {
-				argCreateRolePermission := db.CreateRolePermissionParams{
-					RoleID:       req.ID,
-					PermissionID: p.ID,
-				}
-
-				_, err := server.store.CreateRolePermission(ctx, argCreateRolePermission)
-				if err != nil {
-					ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-					return
-				}
-			} else if rolePermissionExists.ID != 0 && !p.IsAssigned {
-				argDeleteRolePermission := db.DeleteRolePermissionParams{
-					RoleID:       req.ID,
-					PermissionID: p.ID,
-				}
-
-				err := server.store.DeleteRolePermission(ctx, argDeleteRolePermission)
-				if err != nil {
-					ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-					return
-				}
-			}
+	// Update the role and grant or revoke its permissions
+	role, err := server.store.UpdateRoleTx(ctx, arg, rolePermissionChanges(req.PermissionGroups))
+	if err != nil {
+		if errors.Is(err, db.ErrVersionConflict) {
+			roleVersionConflict(*server, ctx, req.ID)
+			return
 		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
 	}
 
+	setVersionETag(ctx, role.Version)
 	ctx.JSON(http.StatusOK, jsonResponse{
 		Error:   false,
 		Message: "successfully",
-		Data:    nil,
+		Data:    role,
 	})
 }
 
@@ -266,17 +258,10 @@ func (server *Server) DeleteRole(ctx *gin.Context) {
 		return
 	}
 
-	// Delete role by id
-	err := server.store.DeleteRole(ctx, req.ID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	// Delete all role_permission by role_id
-	err = server.store.DeleteRolePermissionByRoleId(ctx, req.ID)
+	// Delete the role and its role_permission rows
+	err := server.store.DeleteRoleTx(ctx, req.ID, time.Now())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -301,7 +286,7 @@ func (Server *Server) GetRoleForDropDownList(ctx *gin.Context) {
 
 	roles, err := Server.store.GetRoleForDropDownList(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// suggestDefaultLimit is how many titles and tags the search box shows when no limit is given
+const suggestDefaultLimit = 5
+
+type SuggestSearchRequest struct {
+	Q     string `form:"q" binding:"required,max=100"`
+	Limit int32  `form:"limit" binding:"omitempty,min=1,max=10"`
+}
+
+type SuggestSearchResponse struct {
+	Blogs []db.SuggestBlogRow `json:"blogs"`
+	Tags  []db.SuggestTagRow  `json:"tags"`
+}
+
+// SuggestSearch godoc
+//
+//	@Summary		Search Suggest
+//	@Description	Autocomplete for the search box: blog titles and tag names starting with or similar to q, tolerant of typos
+//	@Tags			Search
+//	@Accept			json
+//	@Produce		json
+//	@Param			q		query		string	true	"Search text"
+//	@Param			limit	query		int		false	"Suggestions per kind (default 5, max 10)"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/search/suggest [get]
+func (server *Server) SuggestSearch(ctx *gin.Context) {
+	var req SuggestSearchRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = suggestDefaultLimit
+	}
+
+	response := SuggestSearchResponse{
+		Blogs: []db.SuggestBlogRow{},
+		Tags:  []db.SuggestTagRow{},
+	}
+
+	query := search.FuzzyText(req.Q)
+	if query == "" {
+		ctx.JSON(http.StatusOK, successResponse(response))
+		return
+	}
+
+	statuses, err := visibleBlogStatuses(*server, ctx, "")
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	response.Blogs, err = server.store.SuggestBlog(ctx, db.SuggestBlogParams{
+		QueryPattern: search.EscapeLike(query),
+		Query:        query,
+		Statuses:     statuses,
+		LimitRows:    req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	response.Tags, err = server.store.SuggestTag(ctx, db.SuggestTagParams{
+		QueryPattern: search.EscapeLike(query),
+		Query:        query,
+		LimitRows:    req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, successResponse(response))
+}
@@ -7,6 +7,7 @@ import (
 	"blog-go-api/constants"
 	db "blog-go-api/db/sqlc"
 	_ "blog-go-api/docs"
+	"blog-go-api/storage"
 	"blog-go-api/token"
 	"blog-go-api/util"
 
@@ -21,6 +22,7 @@ type Server struct {
 	config     util.Config
 	store      db.Store
 	tokenMaker token.Maker
+	storage    storage.Backend
 	router     *gin.Engine
 }
 
@@ -35,8 +37,30 @@ type jsonResponseWithPaginate struct {
 	Total int64 `json:"total"`
 }
 
-// NewServer creates a new HTTP server and set up routing.
-func NewServer(config util.Config, store db.Store) (*Server, error) {
+type jsonResponseWithSearch struct {
+	jsonResponseWithPaginate
+	DidYouMean string `json:"did_you_mean,omitempty"`
+}
+
+type jsonResponseWithCursor struct {
+	jsonResponse
+	NextCursor string `json:"next_cursor"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+type jsonResponseWithCursorFacets struct {
+	jsonResponseWithCursor
+	DidYouMean string      `json:"did_you_mean,omitempty"`
+	Facets     interface{} `json:"facets"`
+}
+
+type jsonResponseWithFacets struct {
+	jsonResponseWithSearch
+	Facets interface{} `json:"facets"`
+}
+
+// NewServer creates a new HTTP server and set up routing. Uploaded files are kept in backend.
+func NewServer(config util.Config, store db.Store, backend storage.Backend) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
@@ -46,6 +70,7 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		storage:    backend,
 	}
 
 	// if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -79,7 +104,8 @@ func (server *Server) setupRouter() {
 	config.AllowOrigins = []string{config_env.URL_LOCALHOST}
 	// config.AllowAllOrigins = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "If-Match"}
+	config.ExposeHeaders = []string{"ETag"}
 
 	gin.SetMode(config_env.GIN_MODE)
 
@@ -131,10 +157,53 @@ func (server *Server) setupRouter() {
 	routerGroup.GET("/api/blog", server.GetAllBlog)
 	routerGroup.GET("/api/blog/tag", server.GetAllBlogWithTag)
 	routerGroup.GET("/api/blog/:url", server.GetBlogByUrl)
-	routerGroup.GET("/api/blog/id", authMiddleware(*server, &[]string{constants.PermissionViewBlog.Code}), server.GetBlogByID)
-	routerGroup.POST("/api/blog", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code}), server.CreateBlog)
-	routerGroup.PUT("/api/blog", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code}), server.UpdateBlog)
-	routerGroup.DELETE("/api/blog/:id", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code}), server.DeleteBlog)
+	routerGroup.GET("/api/blog/id", authMiddleware(*server, &[]string{constants.PermissionViewBlog.Code, constants.PermissionEditOwnBlog.Code}), server.GetBlogByID)
+	routerGroup.POST("/api/blog", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code, constants.PermissionEditOwnBlog.Code}), server.CreateBlog)
+	routerGroup.PUT("/api/blog", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code, constants.PermissionEditOwnBlog.Code}), server.UpdateBlog)
+	routerGroup.DELETE("/api/blog/:id", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code, constants.PermissionEditOwnBlog.Code}), server.DeleteBlog)
+	routerGroup.POST("/api/blog/:id/lock", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code, constants.PermissionEditOwnBlog.Code}), server.AcquireBlogLock)
+	routerGroup.DELETE("/api/blog/:id/lock", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code, constants.PermissionEditOwnBlog.Code}), server.ReleaseBlogLock)
+
+	// Blog Revision
+	routerGroup.GET("/api/blog_revision", authMiddleware(*server, &[]string{constants.PermissionViewBlog.Code}), server.GetAllBlogRevision)
+	routerGroup.GET("/api/blog_revision/diff", authMiddleware(*server, &[]string{constants.PermissionViewBlog.Code}), server.DiffBlogRevision)
+	routerGroup.GET("/api/blog_revision/:id", authMiddleware(*server, &[]string{constants.PermissionViewBlog.Code}), server.GetBlogRevisionById)
+	routerGroup.POST("/api/blog_revision/restore", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code, constants.PermissionEditOwnBlog.Code}), server.RestoreBlogRevision)
+
+	// Blog Review
+	routerGroup.GET("/api/blog_review", authMiddleware(*server, &[]string{constants.PermissionViewBlog.Code, constants.PermissionReviewBlog.Code, constants.PermissionEditOwnBlog.Code}), server.GetAllBlogReview)
+	routerGroup.POST("/api/blog_review/submit", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code, constants.PermissionEditOwnBlog.Code}), server.SubmitBlogReview)
+	routerGroup.POST("/api/blog_review/approve", authMiddleware(*server, &[]string{constants.PermissionReviewBlog.Code}), server.ApproveBlogReview)
+	routerGroup.POST("/api/blog_review/request_changes", authMiddleware(*server, &[]string{constants.PermissionReviewBlog.Code}), server.RequestChangesBlogReview)
+	routerGroup.POST("/api/blog_review/publish", authMiddleware(*server, &[]string{constants.PermissionPublishBlog.Code}), server.PublishBlogReview)
+	routerGroup.POST("/api/blog_review/comment", authMiddleware(*server, &[]string{constants.PermissionViewBlog.Code, constants.PermissionReviewBlog.Code, constants.PermissionEditOwnBlog.Code}), server.CommentBlogReview)
+
+	// Media
+	routerGroup.GET("/api/media", authMiddleware(*server, &[]string{constants.PermissionViewMedia.Code}), server.GetAllMedia)
+	routerGroup.GET("/api/media/:id", authMiddleware(*server, &[]string{constants.PermissionViewMedia.Code}), server.GetMediaById)
+	routerGroup.POST("/api/media", authMiddleware(*server, &[]string{constants.PermissionEditMedia.Code}), server.UploadMedia)
+	routerGroup.PUT("/api/media", authMiddleware(*server, &[]string{constants.PermissionEditMedia.Code}), server.UpdateMedia)
+	routerGroup.DELETE("/api/media/:id", authMiddleware(*server, &[]string{constants.PermissionEditMedia.Code}), server.DeleteMedia)
+
+	// Storage
+	routerGroup.GET(storage.ServeRoute+"/*key", server.GetStorageObject)
+
+	// Search
+	routerGroup.GET("/api/search/suggest", server.SuggestSearch)
+
+	// Trash
+	routerGroup.GET("/api/trash/blog", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code}), server.GetAllTrashBlog)
+	routerGroup.POST("/api/trash/blog/restore", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code}), server.RestoreTrashBlog)
+	routerGroup.DELETE("/api/trash/blog/:id", authMiddleware(*server, &[]string{constants.PermissionEditBlog.Code}), server.PurgeTrashBlog)
+	routerGroup.GET("/api/trash/tag", authMiddleware(*server, &[]string{constants.PermissionEditTag.Code}), server.GetAllTrashTag)
+	routerGroup.POST("/api/trash/tag/restore", authMiddleware(*server, &[]string{constants.PermissionEditTag.Code}), server.RestoreTrashTag)
+	routerGroup.DELETE("/api/trash/tag/:id", authMiddleware(*server, &[]string{constants.PermissionEditTag.Code}), server.PurgeTrashTag)
+	routerGroup.GET("/api/trash/role", authMiddleware(*server, &[]string{constants.PermissionEditRole.Code}), server.GetAllTrashRole)
+	routerGroup.POST("/api/trash/role/restore", authMiddleware(*server, &[]string{constants.PermissionEditRole.Code}), server.RestoreTrashRole)
+	routerGroup.DELETE("/api/trash/role/:id", authMiddleware(*server, &[]string{constants.PermissionEditRole.Code}), server.PurgeTrashRole)
+	routerGroup.GET("/api/trash/user", authMiddleware(*server, &[]string{constants.PermissionEditUser.Code}), server.GetAllTrashUser)
+	routerGroup.POST("/api/trash/user/restore", authMiddleware(*server, &[]string{constants.PermissionEditUser.Code}), server.RestoreTrashUser)
+	routerGroup.DELETE("/api/trash/user/:id", authMiddleware(*server, &[]string{constants.PermissionEditUser.Code}), server.PurgeTrashUser)
 
 	// Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// storageCacheControl lets clients cache stored files for good, an object key is never reused for other content
+const storageCacheControl = "public, max-age=31536000, immutable"
+
+type GetStorageObjectRequest struct {
+	Key string `uri:"key" binding:"required"`
+}
+
+// GetStorageObject godoc
+//
+//	@Summary		Get Stored File
+//	@Description	Serve an uploaded file from the storage backend, used when the backend is not reachable by clients itself (filesystem and memory)
+//	@Tags			Storage
+//	@Produce		octet-stream
+//	@Param			key	path		string	true	"Object key"
+//	@Success		200	{file}		file
+//	@Failure		404	{object}	jsonResponse
+//	@Router			/api/storage/{key} [get]
+func (server *Server) GetStorageObject(ctx *gin.Context) {
+	var req GetStorageObjectRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	object, info, err := server.storage.Get(ctx, req.Key)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	defer object.Close()
+
+	ctx.DataFromReader(http.StatusOK, info.Size, info.ContentType, object, map[string]string{
+		"Cache-Control":          storageCacheControl,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	db "blog-go-api/db/sqlc"
+	"blog-go-api/search"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,7 +21,7 @@ type GetAllTagRequest struct {
 //	@Tags			Tag
 //	@Accept			json
 //	@Produce		json
-//	@Param			name		query		string	false	"Tag Name"
+//	@Param			name		query		string	false	"Tag Name (typo tolerant)"
 //	@Param			page_id		query		int		true	"Page ID"
 //	@Param			page_size	query		int		true	"Page Size"
 //	@Success		200			{object}	jsonResponseWithPaginate
@@ -34,21 +35,27 @@ func (server *Server) GetAllTag(ctx *gin.Context) {
 		return
 	}
 
+	namePattern := search.EscapeLike(req.Name)
+
 	arg := db.GetAllTagParams{
-		Lower:  req.Name,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		NamePattern: namePattern,
+		Name:        req.Name,
+		LimitRows:   req.PageSize,
+		OffsetRows:  (req.PageID - 1) * req.PageSize,
 	}
 
 	tags, err := server.store.GetAllTag(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
-	count, err := server.store.CountAllTag(ctx, req.Name)
+	count, err := server.store.CountAllTag(ctx, db.CountAllTagParams{
+		NamePattern: namePattern,
+		Name:        req.Name,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -88,7 +95,7 @@ func (server *Server) GetTagById(ctx *gin.Context) {
 
 	tag, err := server.store.GetTagById(ctx, req.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -123,7 +130,7 @@ func (server *Server) CreateTag(ctx *gin.Context) {
 
 	tag, err := server.store.CreateTag(ctx, req.Name)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -164,7 +171,7 @@ func (server *Server) UpdateTag(ctx *gin.Context) {
 
 	err := server.store.UpdateTag(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -199,7 +206,7 @@ func (server *Server) DeleteTag(ctx *gin.Context) {
 
 	err := server.store.DeleteTag(ctx, req.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
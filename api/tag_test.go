@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	db "blog-go-api/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tagStore records the arguments the tag queries are called with.
+// Any other query panics on the nil embedded Store.
+type tagStore struct {
+	db.Store
+	list  db.GetAllTagParams
+	count db.CountAllTagParams
+}
+
+func (store *tagStore) GetAllTag(ctx context.Context, arg db.GetAllTagParams) ([]db.GetAllTagRow, error) {
+	store.list = arg
+	return []db.GetAllTagRow{}, nil
+}
+
+func (store *tagStore) CountAllTag(ctx context.Context, arg db.CountAllTagParams) (int64, error) {
+	store.count = arg
+	return 0, nil
+}
+
+func TestGetAllTagEscapesLikeWildcards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		wantPattern string
+	}{
+		{"go", "go"},
+		{"100%", `100\%`},
+		{"snake_case", `snake\_case`},
+		{`back\slash`, `back\\slash`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := &tagStore{}
+			server := &Server{store: store}
+
+			query := url.Values{"name": {test.name}, "page_id": {"1"}, "page_size": {"5"}}
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/api/tag?"+query.Encode(), nil)
+
+			server.GetAllTag(ctx)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("GetAllTag answered %d: %s", recorder.Code, recorder.Body.String())
+			}
+			if store.list.NamePattern != test.wantPattern || store.count.NamePattern != test.wantPattern {
+				t.Errorf("LIKE patterns = %q and %q, want %q", store.list.NamePattern, store.count.NamePattern, test.wantPattern)
+			}
+			if store.list.Name != test.name || store.count.Name != test.name {
+				t.Errorf("similarity names = %q and %q, want the raw %q", store.list.Name, store.count.Name, test.name)
+			}
+		})
+	}
+}
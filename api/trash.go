@@ -0,0 +1,518 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "blog-go-api/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errNotInTrash     = errors.New("item not found in trash")
+	errUserHasContent = errors.New("user still authors blogs or reviews, purge or reassign them first")
+)
+
+type GetAllTrashRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+type RestoreTrashRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+type PurgeTrashRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// purgeResponse reports the outcome of a purge: 404 when nothing was in the trash under that id
+func purgeResponse(ctx *gin.Context, purged int64) {
+	if purged == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(errNotInTrash))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    nil,
+	})
+}
+
+// GetAllTrashBlog godoc
+//
+//	@Summary		Get All Trash Blog
+//	@Description	List deleted blogs, most recently deleted first
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			page_id		query		int	true	"Page ID"
+//	@Param			page_size	query		int	true	"Page Size"
+//	@Success		200			{object}	jsonResponseWithPaginate
+//	@Router			/api/trash/blog [get]
+//	@Security		BearerAuth
+func (server *Server) GetAllTrashBlog(ctx *gin.Context) {
+	var req GetAllTrashRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	blogs, err := server.store.ListDeletedBlog(ctx, db.ListDeletedBlogParams{
+		LimitRows:  req.PageSize,
+		OffsetRows: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	count, err := server.store.CountDeletedBlog(ctx)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponseWithPaginate{
+		jsonResponse: jsonResponse{
+			Error:   false,
+			Message: "successfully",
+			Data:    blogs,
+		},
+		Total: count,
+	})
+}
+
+// RestoreTrashBlog godoc
+//
+//	@Summary		Restore Trash Blog
+//	@Description	Restore a deleted blog together with the tags removed along with it. If another blog took its URL in the meantime, a new URL is generated from the title.
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RestoreTrashRequest	true	"Blog to restore"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/trash/blog/restore [post]
+//	@Security		BearerAuth
+func (server *Server) RestoreTrashBlog(ctx *gin.Context) {
+	var req RestoreTrashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	deletedBlog, err := server.store.GetDeletedBlogById(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNotInTrash))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	blogURL := deletedBlog.Url
+	taken, err := server.store.ExistsBlogUrl(ctx, db.ExistsBlogUrlParams{
+		Url:       blogURL,
+		ExcludeID: deletedBlog.ID,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	if taken {
+		var ok bool
+		blogURL, ok = resolveBlogUrl(*server, ctx, deletedBlog.ID, "", deletedBlog.Title)
+		if !ok {
+			return
+		}
+	}
+
+	blog, err := server.store.RestoreBlog(ctx, db.RestoreBlogParams{
+		ID:  deletedBlog.ID,
+		Url: blogURL,
+	})
+	if err != nil {
+		ctx.JSON(blogSaveError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    blog,
+	})
+}
+
+// PurgeTrashBlog godoc
+//
+//	@Summary		Purge Trash Blog
+//	@Description	Permanently delete a blog in the trash with its tags, revisions, reviews and redirects
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Blog ID"
+//	@Success		200	{object}	jsonResponse
+//	@Router			/api/trash/blog/{id} [delete]
+//	@Security		BearerAuth
+func (server *Server) PurgeTrashBlog(ctx *gin.Context) {
+	var req PurgeTrashRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	purged, err := server.store.PurgeBlog(ctx, db.PurgeBlogParams{
+		ID: pgtype.Int8{Int64: req.ID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	purgeResponse(ctx, purged)
+}
+
+// GetAllTrashTag godoc
+//
+//	@Summary		Get All Trash Tag
+//	@Description	List deleted tags, most recently deleted first
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			page_id		query		int	true	"Page ID"
+//	@Param			page_size	query		int	true	"Page Size"
+//	@Success		200			{object}	jsonResponseWithPaginate
+//	@Router			/api/trash/tag [get]
+//	@Security		BearerAuth
+func (server *Server) GetAllTrashTag(ctx *gin.Context) {
+	var req GetAllTrashRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	tags, err := server.store.ListDeletedTag(ctx, db.ListDeletedTagParams{
+		LimitRows:  req.PageSize,
+		OffsetRows: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	count, err := server.store.CountDeletedTag(ctx)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponseWithPaginate{
+		jsonResponse: jsonResponse{
+			Error:   false,
+			Message: "successfully",
+			Data:    tags,
+		},
+		Total: count,
+	})
+}
+
+// RestoreTrashTag godoc
+//
+//	@Summary		Restore Trash Tag
+//	@Description	Restore a deleted tag. Blogs that still carry the tag show it again.
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RestoreTrashRequest	true	"Tag to restore"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/trash/tag/restore [post]
+//	@Security		BearerAuth
+func (server *Server) RestoreTrashTag(ctx *gin.Context) {
+	var req RestoreTrashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	tag, err := server.store.RestoreTag(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNotInTrash))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    tag,
+	})
+}
+
+// PurgeTrashTag godoc
+//
+//	@Summary		Purge Trash Tag
+//	@Description	Permanently delete a tag in the trash and detach it from every blog
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Tag ID"
+//	@Success		200	{object}	jsonResponse
+//	@Router			/api/trash/tag/{id} [delete]
+//	@Security		BearerAuth
+func (server *Server) PurgeTrashTag(ctx *gin.Context) {
+	var req PurgeTrashRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	purged, err := server.store.PurgeTag(ctx, db.PurgeTagParams{
+		ID: pgtype.Int8{Int64: req.ID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	purgeResponse(ctx, purged)
+}
+
+// GetAllTrashRole godoc
+//
+//	@Summary		Get All Trash Role
+//	@Description	List deleted roles, most recently deleted first
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			page_id		query		int	true	"Page ID"
+//	@Param			page_size	query		int	true	"Page Size"
+//	@Success		200			{object}	jsonResponseWithPaginate
+//	@Router			/api/trash/role [get]
+//	@Security		BearerAuth
+func (server *Server) GetAllTrashRole(ctx *gin.Context) {
+	var req GetAllTrashRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	roles, err := server.store.ListDeletedRole(ctx, db.ListDeletedRoleParams{
+		LimitRows:  req.PageSize,
+		OffsetRows: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	count, err := server.store.CountDeletedRole(ctx)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponseWithPaginate{
+		jsonResponse: jsonResponse{
+			Error:   false,
+			Message: "successfully",
+			Data:    roles,
+		},
+		Total: count,
+	})
+}
+
+// RestoreTrashRole godoc
+//
+//	@Summary		Restore Trash Role
+//	@Description	Restore a deleted role together with the permissions removed along with it
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RestoreTrashRequest	true	"Role to restore"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/trash/role/restore [post]
+//	@Security		BearerAuth
+func (server *Server) RestoreTrashRole(ctx *gin.Context) {
+	var req RestoreTrashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	role, err := server.store.RestoreRole(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNotInTrash))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    role,
+	})
+}
+
+// PurgeTrashRole godoc
+//
+//	@Summary		Purge Trash Role
+//	@Description	Permanently delete a role in the trash and take it away from every user
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Role ID"
+//	@Success		200	{object}	jsonResponse
+//	@Router			/api/trash/role/{id} [delete]
+//	@Security		BearerAuth
+func (server *Server) PurgeTrashRole(ctx *gin.Context) {
+	var req PurgeTrashRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	purged, err := server.store.PurgeRole(ctx, db.PurgeRoleParams{
+		ID: pgtype.Int8{Int64: req.ID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	purgeResponse(ctx, purged)
+}
+
+// GetAllTrashUser godoc
+//
+//	@Summary		Get All Trash User
+//	@Description	List deleted users, most recently deleted first
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			page_id		query		int	true	"Page ID"
+//	@Param			page_size	query		int	true	"Page Size"
+//	@Success		200			{object}	jsonResponseWithPaginate
+//	@Router			/api/trash/user [get]
+//	@Security		BearerAuth
+func (server *Server) GetAllTrashUser(ctx *gin.Context) {
+	var req GetAllTrashRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	users, err := server.store.ListDeletedUser(ctx, db.ListDeletedUserParams{
+		LimitRows:  req.PageSize,
+		OffsetRows: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	count, err := server.store.CountDeletedUser(ctx)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponseWithPaginate{
+		jsonResponse: jsonResponse{
+			Error:   false,
+			Message: "successfully",
+			Data:    users,
+		},
+		Total: count,
+	})
+}
+
+// RestoreTrashUser godoc
+//
+//	@Summary		Restore Trash User
+//	@Description	Restore a deleted user together with the roles removed along with them
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RestoreTrashRequest	true	"User to restore"
+//	@Success		200		{object}	jsonResponse
+//	@Router			/api/trash/user/restore [post]
+//	@Security		BearerAuth
+func (server *Server) RestoreTrashUser(ctx *gin.Context) {
+	var req RestoreTrashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.RestoreUser(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNotInTrash))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jsonResponse{
+		Error:   false,
+		Message: "successfully",
+		Data:    user,
+	})
+}
+
+// PurgeTrashUser godoc
+//
+//	@Summary		Purge Trash User
+//	@Description	Permanently delete a user in the trash with their roles, sessions and password resets. Users who still author blogs or reviews cannot be purged.
+//	@Tags			Trash
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	jsonResponse
+//	@Failure		409	{object}	jsonResponse	"The user still authors blogs or reviews"
+//	@Router			/api/trash/user/{id} [delete]
+//	@Security		BearerAuth
+func (server *Server) PurgeTrashUser(ctx *gin.Context) {
+	var req PurgeTrashRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, err := server.store.GetDeletedUserById(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNotInTrash))
+			return
+		}
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	purged, err := server.store.PurgeUser(ctx, db.PurgeUserParams{
+		ID: pgtype.Int8{Int64: req.ID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	// The user is in the trash, so nothing purged means their content kept them
+	if purged == 0 {
+		ctx.JSON(http.StatusConflict, errorResponse(errUserHasContent))
+		return
+	}
+
+	purgeResponse(ctx, purged)
+}
@@ -66,14 +66,14 @@ func (server *Server) createUser(ctx *gin.Context) {
 	// Generate Hash Password
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	// Get Count User
 	countUser, err := server.store.CountUserForGenerateCode(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -94,26 +94,14 @@ func (server *Server) createUser(ctx *gin.Context) {
 		},
 	}
 
-	// Create User
-	user, err := server.store.CreateUser(ctx, arg)
+	// Create User with the default role
+	_, err = server.store.CreateUserTx(ctx, arg, []int64{1})
 	if err != nil {
 		if db.ErrorCode(err) == db.UniqueViolation {
 			ctx.JSON(http.StatusForbidden, errorResponse(err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	// Create User Role
-	argCreateUserRole := db.CreateUserRoleParams{
-		UserID: user.ID,
-		RoleID: 1,
-	}
-
-	err = server.store.CreateUserRole(ctx, argCreateUserRole)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -134,6 +122,27 @@ type updateUserRequest struct {
 	Phone       string  `json:"phone"`
 	Description string  `json:"description"`
 	Roles       []int64 `json:"roles"`
+	Version     int32   `json:"version" binding:"omitempty,min=1"` // the version the changes are based on, required unless sent as If-Match
+}
+
+// userVersionConflict answers a save based on an outdated version with the current copy of the user
+func userVersionConflict(server Server, ctx *gin.Context, userID int64) {
+	user, err := server.store.GetUser(ctx, userID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	roles, err := server.store.GetRoleByUserId(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	versionConflict(ctx, user.Version, getUserResponse{
+		GetUserRow: user,
+		Roles:      roles,
+	})
 }
 
 type updateResponse struct {
@@ -152,8 +161,13 @@ type updateResponse struct {
 // @Tags			User
 // @Accept			json
 // @Produce		json
-// @Param			input	body		updateUserRequest	true	"User information"
-// @Success		200		{object}	jsonResponse
+// @Param			input		body		updateUserRequest	true	"User information"
+// @Param			If-Match	header		string				false	"ETag of the version the changes are based on, instead of version in the body"
+// @Success		200			{object}	jsonResponse
+// @Header			200			{string}	ETag	"Version of the saved user"
+// @Failure		404			{object}	jsonResponse
+// @Failure		409			{object}	jsonResponse	"The user was changed by someone else, data holds the current copy"
+// @Failure		428			{object}	jsonResponse	"Neither version nor If-Match was sent"
 // @Router			/api/users [put]
 // @Security		BearerAuth
 func (server *Server) updateUser(ctx *gin.Context) {
@@ -163,8 +177,20 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		return
 	}
 
+	currentUser, err := server.store.GetUser(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	version, ok := expectedVersion(ctx, req.Version, currentUser.Version)
+	if !ok {
+		return
+	}
+
 	arg := db.UpdateUserParams{
-		UserID: req.ID,
+		UserID:  req.ID,
+		Version: version,
 		FirstName: pgtype.Text{
 			String: req.FirstName,
 			Valid:  req.FirstName != "",
@@ -206,41 +232,24 @@ func (server *Server) updateUser(ctx *gin.Context) {
 	// 	}
 	// }
 
-	// Update User
-	user, err := server.store.UpdateUser(ctx, arg)
+	// Update User and replace all of their roles
+	user, err := server.store.UpdateUserWithRolesTx(ctx, arg, req.Roles)
 	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			err = fmt.Errorf("user not found: %s", err)
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrVersionConflict) {
+			userVersionConflict(*server, ctx, req.ID)
 			return
 		}
-		err = fmt.Errorf("failed to update user: %s", err)
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	// Update User Role
-	// Delete All User Role
-	err = server.store.DeleteUserRoleByUserId(ctx, user.ID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	// Create User Role
-	for _, roleID := range req.Roles {
-		argCreateUserRole := db.CreateUserRoleParams{
-			UserID: user.ID,
-			RoleID: roleID,
-		}
-
-		err := server.store.CreateUserRole(ctx, argCreateUserRole)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrRecordNotFound) {
+			err = fmt.Errorf("user not found: %w", err)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
+		err = fmt.Errorf("failed to update user: %w", err)
+		ctx.JSON(errorStatus(err), errorResponse(err))
+		return
 	}
 
+	setVersionETag(ctx, user.Version)
 	result := jsonResponse{
 		Error:   false,
 		Message: "successfully",
@@ -286,13 +295,13 @@ func (server *Server) listUser(ctx *gin.Context) {
 
 	user, err := server.store.ListUsers(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	countUser, err := server.store.CountUser(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -300,7 +309,7 @@ func (server *Server) listUser(ctx *gin.Context) {
 	for _, u := range user {
 		roles, err := server.store.GetRoleByUserId(ctx, u.ID)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			ctx.JSON(errorStatus(err), errorResponse(err))
 			return
 		}
 
@@ -340,6 +349,7 @@ type getUserResponse struct {
 // @Produce		json
 // @Param			id	path		int	true	"User ID"
 // @Success		200	{object}	jsonResponse
+// @Header			200	{string}	ETag	"Version of the user, send it back as If-Match when saving"
 // @Router			/api/users/{id} [get]
 // @Security		BearerAuth
 func (server *Server) getUser(ctx *gin.Context) {
@@ -356,14 +366,14 @@ func (server *Server) getUser(ctx *gin.Context) {
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
 	// Get role
 	roles, err := server.store.GetRoleByUserId(ctx, User.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -372,6 +382,8 @@ func (server *Server) getUser(ctx *gin.Context) {
 		Roles:      roles,
 	}
 
+	setVersionETag(ctx, User.Version)
+
 	// authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	// if account.Owner != authPayload.Username {
 	// 	err := errors.New("account doesn't belong to the authenticated user")
@@ -416,22 +428,15 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 		}
 	}
 
-	// Delete User
-	err = server.store.DeleteUser(ctx, req.ID)
+	// Delete User and their roles
+	err = server.store.DeleteUserTx(ctx, req.ID, time.Now())
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	// Delete User Role
-	err = server.store.DeleteUserRoleByUserId(ctx, req.ID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errVersionRequired = errors.New("version is required, send the version you loaded in the body or as an If-Match header")
+	errInvalidIfMatch  = errors.New("If-Match must hold the ETag of the resource")
+)
+
+// setVersionETag exposes the version of a resource as its ETag, clients send it back in If-Match when saving
+func setVersionETag(ctx *gin.Context, version int32) {
+	ctx.Header("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// expectedVersion reads the version a save is based on. An If-Match header takes precedence over the version
+// in the body and "*" matches the current version. A request without either is rejected with 428.
+// Weak ETags (W/"3"), as proxies that compress responses hand them out, name the same version.
+func expectedVersion(ctx *gin.Context, bodyVersion int32, currentVersion int32) (int32, bool) {
+	ifMatch := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if ifMatch == "" {
+		if bodyVersion == 0 {
+			ctx.JSON(http.StatusPreconditionRequired, errorResponse(errVersionRequired))
+			return 0, false
+		}
+		return bodyVersion, true
+	}
+
+	var expected int32
+	for i, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return currentVersion, true
+		}
+
+		version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(tag, "W/"), `"`), 10, 32)
+		if err != nil || version < 1 {
+			ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidIfMatch))
+			return 0, false
+		}
+
+		if int32(version) == currentVersion {
+			return currentVersion, true
+		}
+		if i == 0 {
+			expected = int32(version)
+		}
+	}
+
+	return expected, true
+}
+
+// versionConflict answers a save based on an outdated version with the current copy of the resource,
+// so the client can merge its changes and save again
+func versionConflict(ctx *gin.Context, version int32, current interface{}) {
+	setVersionETag(ctx, version)
+	ctx.JSON(http.StatusConflict, jsonResponse{
+		Error:   true,
+		Message: "the record was changed by someone else, merge your changes into the current version",
+		Data:    current,
+	})
+}
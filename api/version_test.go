@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestExpectedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		bodyVersion int32
+		want        int32
+		wantStatus  int // set when the request is rejected
+	}{
+		{name: "body", bodyVersion: 3, want: 3},
+		{name: "neither", wantStatus: http.StatusPreconditionRequired},
+		{name: "strong etag", ifMatch: `"4"`, bodyVersion: 3, want: 4},
+		{name: "weak etag", ifMatch: `W/"4"`, want: 4},
+		{name: "any", ifMatch: "*", want: 5},
+		{name: "list with the current version", ifMatch: `"2", W/"5"`, want: 5},
+		{name: "list without the current version", ifMatch: `"2", "3"`, want: 2},
+		{name: "not an etag", ifMatch: `"abc"`, wantStatus: http.StatusBadRequest},
+		{name: "zero", ifMatch: `"0"`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+			if test.ifMatch != "" {
+				ctx.Request.Header.Set("If-Match", test.ifMatch)
+			}
+
+			got, ok := expectedVersion(ctx, test.bodyVersion, 5)
+
+			if test.wantStatus != 0 {
+				if ok || recorder.Code != test.wantStatus {
+					t.Errorf("expectedVersion() = %d, %v with status %d, want it rejected with %d", got, ok, recorder.Code, test.wantStatus)
+				}
+				return
+			}
+			if !ok || got != test.want {
+				t.Errorf("expectedVersion() = %d, %v, want %d", got, ok, test.want)
+			}
+		})
+	}
+}
@@ -48,6 +48,24 @@ var PermissionEditBlog = permission{
 	Name: "Edit Blog",
 }
 
+var PermissionEditOwnBlog = permission{
+	ID:   10,
+	Code: "edit_own_blog",
+	Name: "Edit Own Blog",
+}
+
+var PermissionPublishBlog = permission{
+	ID:   11,
+	Code: "publish_blog",
+	Name: "Publish Blog",
+}
+
+var PermissionReviewBlog = permission{
+	ID:   12,
+	Code: "review_blog",
+	Name: "Review Blog",
+}
+
 var PermissionViewTag = permission{
 	ID:   8,
 	Code: "view_tag",
@@ -59,3 +77,50 @@ var PermissionEditTag = permission{
 	Code: "edit_tag",
 	Name: "Edit Tag",
 }
+
+var PermissionViewMedia = permission{
+	ID:   13,
+	Code: "view_media",
+	Name: "View Media",
+}
+
+var PermissionEditMedia = permission{
+	ID:   14,
+	Code: "edit_media",
+	Name: "Edit Media",
+}
+
+// Blog lifecycle statuses stored in blog.status
+const (
+	BlogStatusDraft     = "draft"
+	BlogStatusInReview  = "in_review"
+	BlogStatusApproved  = "approved"
+	BlogStatusScheduled = "scheduled"
+	BlogStatusPublished = "published"
+	BlogStatusArchived  = "archived"
+)
+
+// BlogStatuses lists every status a blog post can be in
+var BlogStatuses = []string{
+	BlogStatusDraft,
+	BlogStatusInReview,
+	BlogStatusApproved,
+	BlogStatusScheduled,
+	BlogStatusPublished,
+	BlogStatusArchived,
+}
+
+// Editorial review actions stored in blog_review.action
+const (
+	BlogReviewActionSubmit         = "submit"
+	BlogReviewActionApprove        = "approve"
+	BlogReviewActionRequestChanges = "request_changes"
+	BlogReviewActionPublish        = "publish"
+	BlogReviewActionComment        = "comment"
+)
+
+// Formats of blog.content, rendered to HTML before it is served
+const (
+	ContentFormatHTML     = "html"
+	ContentFormatMarkdown = "markdown"
+)
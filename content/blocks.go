@@ -0,0 +1,31 @@
+package content
+
+import (
+	"regexp"
+	"strings"
+
+	"blog-go-api/constants"
+)
+
+// blockBreakPattern matches where an HTML block ends: the closing tag of a block element, or a line break or rule
+var blockBreakPattern = regexp.MustCompile(`(?i)</(p|div|h[1-6]|li|ul|ol|dl|dt|dd|blockquote|pre|table|thead|tbody|tfoot|tr|figure|figcaption|section|article|aside|header|footer|nav)\s*>|<(br|hr)\s*/?>`)
+
+// BlockLines puts every block of post content on a line of its own, so a line diff compares paragraphs,
+// headings and list items even when the HTML was saved on a single line. Markdown is line based already.
+func BlockLines(source string, format string) string {
+	if format == constants.ContentFormatMarkdown {
+		return source
+	}
+
+	source = blockBreakPattern.ReplaceAllString(source, "$0\n")
+
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	blocks := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			blocks = append(blocks, line)
+		}
+	}
+
+	return strings.Join(blocks, "\n")
+}
@@ -0,0 +1,31 @@
+package content
+
+import (
+	"testing"
+
+	"blog-go-api/constants"
+)
+
+func TestBlockLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		format string
+		want   string
+	}{
+		{"markdown is kept", "# Title\n\ntext", constants.ContentFormatMarkdown, "# Title\n\ntext"},
+		{"html on one line", "<h2>Title</h2><p>one</p><p>two</p>", constants.ContentFormatHTML, "<h2>Title</h2>\n<p>one</p>\n<p>two</p>"},
+		{"list items", "<ul><li>a</li><li>b</li></ul>", constants.ContentFormatHTML, "<ul><li>a</li>\n<li>b</li>\n</ul>"},
+		{"breaks and rules", "a<br>b<hr/>c", constants.ContentFormatHTML, "a<br>\nb<hr/>\nc"},
+		{"blank lines dropped", "<p>one</p>\r\n\r\n   <P>two</P >", constants.ContentFormatHTML, "<p>one</p>\n<P>two</P >"},
+		{"inline tags stay", "<p>a <em>b</em> c</p>", constants.ContentFormatHTML, "<p>a <em>b</em> c</p>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := BlockLines(test.source, test.format); got != test.want {
+				t.Errorf("BlockLines(%q) = %q, want %q", test.source, got, test.want)
+			}
+		})
+	}
+}
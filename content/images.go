@@ -0,0 +1,26 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var imageSourcePattern = regexp.MustCompile(`(?is)<img\b[^>]*?\ssrc\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// ImageSources returns the distinct sources of the images in HTML, in the order they appear
+func ImageSources(text string) []string {
+	sources := []string{}
+	seen := map[string]bool{}
+
+	for _, match := range imageSourcePattern.FindAllStringSubmatch(text, -1) {
+		source := strings.TrimSpace(html.UnescapeString(match[1] + match[2]))
+		if source == "" || seen[source] {
+			continue
+		}
+		seen[source] = true
+		sources = append(sources, source)
+	}
+
+	return sources
+}
@@ -0,0 +1,15 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImageSources(t *testing.T) {
+	html := `<p><img src="a.png"><img alt='x' src='b.png'><IMG SRC="a.png"><img src="c&amp;d.png"><img src=""></p>`
+
+	want := []string{"a.png", "b.png", "c&d.png"}
+	if got := ImageSources(html); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImageSources() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package content
+
+import (
+	"bytes"
+	"regexp"
+
+	"blog-go-api/constants"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// markdown renders GitHub flavoured Markdown. Raw HTML is passed through because
+// everything it renders goes through the sanitizer afterwards.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// policy is the allowlist applied to all HTML served to readers.
+// It extends bluemonday's policy for user generated content with syntax highlighting classes on code.
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w-]+$`)).OnElements("code")
+	p.RequireNoReferrerOnLinks(true)
+	return p
+}
+
+// Render turns post content into sanitized HTML ready to serve to readers.
+// Markdown is rendered first, HTML is only sanitized.
+func Render(source string, format string) (string, error) {
+	if format == constants.ContentFormatMarkdown {
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(source), &buf); err != nil {
+			return "", err
+		}
+		source = buf.String()
+	}
+
+	return Sanitize(source), nil
+}
+
+// Sanitize removes every element and attribute outside the allowlist, including scripts and event handlers
+func Sanitize(html string) string {
+	return policy.Sanitize(html)
+}
@@ -0,0 +1,78 @@
+package content
+
+import (
+	"strings"
+	"testing"
+
+	"blog-go-api/constants"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		format  string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "markdown",
+			source: "# Title\n\nsome *bold* and [a link](https://example.com)\n\n```go\nfmt.Println()\n```",
+			format: constants.ContentFormatMarkdown,
+			want: []string{
+				"<h1>Title</h1>",
+				"<em>bold</em>",
+				`<a href="https://example.com" rel="nofollow noreferrer">a link</a>`,
+				`<code class="language-go">`,
+			},
+		},
+		{
+			name:    "raw html in markdown",
+			source:  "text\n\n<script>alert(1)</script>\n\n<a href=\"javascript:alert(1)\" onclick=\"steal()\">click</a>",
+			format:  constants.ContentFormatMarkdown,
+			want:    []string{"<p>text</p>", "click"},
+			notWant: []string{"<script", "alert(1)", "javascript:", "onclick"},
+		},
+		{
+			name:    "html is not rendered as markdown",
+			source:  "<p>*not emphasis*</p>",
+			format:  constants.ContentFormatHTML,
+			want:    []string{"<p>*not emphasis*</p>"},
+			notWant: []string{"<em>"},
+		},
+		{
+			name:    "event handlers and frames",
+			source:  `<p onclick="steal()">hi<img src="cover.png" onerror="steal()"></p><iframe src="https://example.com"></iframe>`,
+			format:  constants.ContentFormatHTML,
+			want:    []string{`<p>hi<img src="cover.png"></p>`},
+			notWant: []string{"onclick", "onerror", "<iframe"},
+		},
+		{
+			name:    "classes other than a code language",
+			source:  `<code class="language-go">x</code><code class="evil">y</code><p class="language-go">z</p>`,
+			format:  constants.ContentFormatHTML,
+			want:    []string{`<code class="language-go">x</code>`, "<code>y</code>", "<p>z</p>"},
+			notWant: []string{"evil"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Render(test.source, test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range test.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range test.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Render() = %q, want no %q", got, notWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+
+	"blog-go-api/search"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// SlugMaxLength is the maximum length of a generated slug, leaving room in blog.url for a -N suffix
+	SlugMaxLength = 100
+	// slugFallback is used when nothing in the text can be turned into a slug
+	slugFallback = "post"
+)
+
+// Slugify turns a title, or a URL typed by an author, into a lower-case slug of words joined by hyphens.
+// Thai is split into words with the search segmenter and romanized, Cyrillic and Greek are transliterated
+// and accents are removed from Latin letters. Scripts without a transliteration are kept as they are.
+func Slugify(text string) string {
+	var parts []string
+	length := 0
+
+	for _, word := range search.Words(text) {
+		part := transliterate(word)
+		if part == "" {
+			continue
+		}
+
+		if length+len(part)+len(parts) > SlugMaxLength {
+			if len(parts) == 0 {
+				parts = append(parts, truncateSlug(part))
+			}
+			break
+		}
+
+		parts = append(parts, part)
+		length += len(part)
+	}
+
+	if len(parts) == 0 {
+		return slugFallback
+	}
+	return strings.Join(parts, "-")
+}
+
+// transliterate writes a single lower-case word in ASCII where a transliteration is known
+func transliterate(word string) string {
+	runes := []rune(word)
+	var b strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if isThaiRune(r) {
+			i = romanizeThai(runes, i, &b)
+			continue
+		}
+
+		if latin, ok := otherTransliteration[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+
+		// Accented letters are written as their base letter, é becomes e and ά becomes a
+		base := []rune(norm.NFD.String(string(r)))[0]
+		if latin, ok := otherTransliteration[base]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if base <= unicode.MaxASCII {
+			r = base
+		}
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// truncateSlug cuts a single long word to SlugMaxLength without splitting a character
+func truncateSlug(part string) string {
+	runes := []rune(part)
+	for len(string(runes)) > SlugMaxLength {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+func isThaiRune(r rune) bool {
+	return r >= 0x0E00 && r <= 0x0E7F
+}
+
+// romanizeThai writes the Thai character at runes[i] in the spirit of the Royal Thai General System
+// and returns the index of the last character it consumed.
+// It works character by character, so the result is readable rather than an exact RTGS spelling.
+func romanizeThai(runes []rune, i int, b *strings.Builder) int {
+	r := runes[i]
+
+	// A consonant under a silent mark (์) is not pronounced
+	if i+1 < len(runes) && runes[i+1] == '์' {
+		return i + 1
+	}
+
+	// Leading vowels are written before the consonant they follow in speech
+	if isThaiLeadingVowelRune(r) && i+1 < len(runes) {
+		if consonant, ok := thaiConsonants[runes[i+1]]; ok {
+			b.WriteString(consonant)
+			b.WriteString(thaiVowels[r])
+			return i + 1
+		}
+	}
+
+	// อ only carries a vowel at the start of a syllable
+	if r == 'อ' && i+1 < len(runes) && isThaiVowelSign(runes[i+1]) {
+		return i
+	}
+
+	if consonant, ok := thaiConsonants[r]; ok {
+		b.WriteString(consonant)
+	} else if vowel, ok := thaiVowels[r]; ok {
+		b.WriteString(vowel)
+	} else if r >= '๐' && r <= '๙' {
+		b.WriteRune('0' + r - '๐')
+	}
+
+	return i
+}
+
+func isThaiLeadingVowelRune(r rune) bool {
+	return r >= 'เ' && r <= 'ไ'
+}
+
+// isThaiVowelSign reports whether r is a vowel or tone mark written after its consonant
+func isThaiVowelSign(r rune) bool {
+	if r == '่' || r == '้' || r == '๊' || r == '๋' {
+		return true
+	}
+	_, ok := thaiVowels[r]
+	return ok && !isThaiLeadingVowelRune(r)
+}
+
+var thaiConsonants = map[rune]string{
+	'ก': "k", 'ข': "kh", 'ฃ': "kh", 'ค': "kh", 'ฅ': "kh", 'ฆ': "kh", 'ง': "ng",
+	'จ': "ch", 'ฉ': "ch", 'ช': "ch", 'ซ': "s", 'ฌ': "ch", 'ญ': "y",
+	'ฎ': "d", 'ฏ': "t", 'ฐ': "th", 'ฑ': "th", 'ฒ': "th", 'ณ': "n",
+	'ด': "d", 'ต': "t", 'ถ': "th", 'ท': "th", 'ธ': "th", 'น': "n",
+	'บ': "b", 'ป': "p", 'ผ': "ph", 'ฝ': "f", 'พ': "ph", 'ฟ': "f", 'ภ': "ph", 'ม': "m",
+	'ย': "y", 'ร': "r", 'ล': "l", 'ว': "w", 'ศ': "s", 'ษ': "s", 'ส': "s",
+	'ห': "h", 'ฬ': "l", 'อ': "o", 'ฮ': "h",
+}
+
+// thaiVowels leaves out tone marks and other signs, which have no spelling in RTGS
+var thaiVowels = map[rune]string{
+	'ะ': "a", 'ั': "a", 'า': "a", 'ำ': "am", 'ิ': "i", 'ี': "i", 'ึ': "ue", 'ื': "ue",
+	'ุ': "u", 'ู': "u", 'เ': "e", 'แ': "ae", 'โ': "o", 'ใ': "ai", 'ไ': "ai",
+	'ฤ': "rue", 'ฦ': "lue",
+}
+
+// otherTransliteration covers Latin letters that NFD does not decompose, Cyrillic and Greek
+var otherTransliteration = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'ø': "o", 'œ': "oe", 'đ': "d", 'ð': "d", 'þ': "th", 'ł': "l", 'ı': "i",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
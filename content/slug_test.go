@@ -0,0 +1,45 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"Go 1.22 release", "go-1-22-release"},
+		{"  already-a-slug  ", "already-a-slug"},
+		{"Café au lait", "cafe-au-lait"},
+		{"Straße", "strasse"},
+		{"Привет мир", "privet-mir"},
+		{"Ελληνικά", "ellinika"},
+		{"สวัสดีครับ", "swasdi-khrab"},
+		{"日本語", "日本語"},
+		{"!!!", "post"},
+		{"", "post"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := Slugify(test.text); got != test.want {
+				t.Errorf("Slugify(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyLength(t *testing.T) {
+	long := Slugify(strings.Repeat("word ", 100))
+	if len(long) > SlugMaxLength || strings.HasSuffix(long, "-") {
+		t.Errorf("Slugify of many words = %q (%d bytes), want at most %d bytes of whole words", long, len(long), SlugMaxLength)
+	}
+
+	single := Slugify(strings.Repeat("ж", 200))
+	if len(single) > SlugMaxLength || single == slugFallback {
+		t.Errorf("Slugify of one long word = %q (%d bytes), want it cut to %d bytes", single, len(single), SlugMaxLength)
+	}
+}
@@ -0,0 +1,90 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"blog-go-api/search"
+)
+
+const (
+	// ExcerptLength is the maximum length in characters of a derived excerpt
+	ExcerptLength = 200
+	// WordsPerMinute is the reading speed used to estimate reading time
+	WordsPerMinute = 200
+)
+
+var (
+	headingPattern        = regexp.MustCompile(`(?is)<h[1-6][^>]*>.*?</h[1-6]>`)
+	blockEndPattern       = regexp.MustCompile(`(?i)</(p|div|h[1-6]|li|blockquote|pre|ul|ol|table)>|<br\s*/?>`)
+	tagPattern            = regexp.MustCompile(`<[^>]*>`)
+	paragraphBreakPattern = regexp.MustCompile(`\n\s*\n`)
+	spacePattern          = regexp.MustCompile(`\s+`)
+)
+
+// Summary holds the values derived from the content of a post for listings
+type Summary struct {
+	Excerpt            string
+	WordCount          int32
+	ReadingTimeMinutes int32
+}
+
+// Summarize derives the excerpt, word count and reading time of a post
+func Summarize(text string) Summary {
+	words := int32(len(search.Words(PlainText(text))))
+
+	return Summary{
+		Excerpt:            excerpt(PlainText(headingPattern.ReplaceAllString(text, ""))),
+		WordCount:          words,
+		ReadingTimeMinutes: ReadingTimeMinutes(words),
+	}
+}
+
+// PlainText removes markup from content, keeping the end of each block as a paragraph break
+func PlainText(text string) string {
+	text = blockEndPattern.ReplaceAllString(text, "\n\n")
+	text = tagPattern.ReplaceAllString(text, " ")
+	return html.UnescapeString(text)
+}
+
+// ReadingTimeMinutes estimates reading time, rounding up so any content takes at least a minute
+func ReadingTimeMinutes(words int32) int32 {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// excerpt returns the first non-empty paragraph of plain text, shortened to ExcerptLength.
+// Headings are removed beforehand so the excerpt starts with body text.
+func excerpt(plain string) string {
+	for _, paragraph := range paragraphBreakPattern.Split(plain, -1) {
+		paragraph = strings.TrimSpace(spacePattern.ReplaceAllString(paragraph, " "))
+		if paragraph != "" {
+			return truncate(paragraph, ExcerptLength)
+		}
+	}
+	return ""
+}
+
+// truncate shortens text to at most max characters plus an ellipsis,
+// preferring to cut at a space and never inside a Thai character cluster.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	cut := max
+	for i := max; i > max/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+
+	for cut > 0 && !search.IsClusterBoundary(runes, cut) {
+		cut--
+	}
+
+	return strings.TrimSpace(string(runes[:cut])) + "…"
+}
@@ -0,0 +1,45 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want Summary
+	}{
+		{
+			name: "empty",
+			html: "",
+			want: Summary{},
+		},
+		{
+			name: "the excerpt skips headings",
+			html: "<h1>Title</h1><p>First  paragraph &amp; more</p><p>second</p>",
+			want: Summary{Excerpt: "First paragraph & more", WordCount: 5, ReadingTimeMinutes: 1},
+		},
+		{
+			name: "reading time rounds up",
+			html: "<p>" + strings.Repeat("word ", WordsPerMinute+1) + "</p>",
+			want: Summary{Excerpt: strings.TrimSpace(strings.Repeat("word ", 40)) + "…", WordCount: WordsPerMinute + 1, ReadingTimeMinutes: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Summarize(test.html); got != test.want {
+				t.Errorf("Summarize() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTruncateKeepsThaiClusters(t *testing.T) {
+	// A cut after three characters would separate the second ก from its vowel
+	if got := truncate("กีกีกี", 3); got != "กี…" {
+		t.Errorf("truncate() = %q, want %q", got, "กี…")
+	}
+}
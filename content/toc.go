@@ -0,0 +1,92 @@
+package content
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	headingElementPattern = regexp.MustCompile(`(?is)<h([1-6])([^>]*)>(.*?)</h[1-6]>`)
+	idAttributePattern    = regexp.MustCompile(`(?i)\sid="([^"]*)"`)
+)
+
+// TocEntry is a heading of a post as listed in its table of contents
+type TocEntry struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+// AddHeadingAnchors gives every heading of sanitized HTML an id to link to and returns the
+// HTML together with the table of contents.
+// Anchors are derived from the heading text so links stay valid across requests; an id already
+// set by the author is kept and repeated headings are numbered in order (intro, intro-2, ...).
+func AddHeadingAnchors(text string) (string, []TocEntry) {
+	toc := []TocEntry{}
+	used := map[string]bool{}
+
+	// Ids written by the author are taken first so generated ones never collide with them
+	for _, match := range headingElementPattern.FindAllStringSubmatch(text, -1) {
+		if id := idAttributePattern.FindStringSubmatch(match[2]); id != nil {
+			used[html.UnescapeString(id[1])] = true
+		}
+	}
+
+	text = headingElementPattern.ReplaceAllStringFunc(text, func(element string) string {
+		match := headingElementPattern.FindStringSubmatch(element)
+		level := int(match[1][0] - '0')
+		attributes := match[2]
+		inner := match[3]
+
+		headingText := strings.TrimSpace(spacePattern.ReplaceAllString(PlainText(inner), " "))
+
+		if id := idAttributePattern.FindStringSubmatch(attributes); id != nil {
+			toc = append(toc, TocEntry{Level: level, Text: headingText, Anchor: html.UnescapeString(id[1])})
+			return element
+		}
+
+		anchor := uniqueAnchor(anchorize(headingText), used)
+		toc = append(toc, TocEntry{Level: level, Text: headingText, Anchor: anchor})
+
+		return fmt.Sprintf(`<h%d id="%s"%s>%s</h%d>`, level, html.EscapeString(anchor), attributes, inner, level)
+	})
+
+	return text, toc
+}
+
+// anchorize turns heading text into an anchor: lower-case letters, marks and digits
+// joined by hyphens. Non-Latin scripts such as Thai are kept as they are valid in ids and URLs.
+func anchorize(text string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// uniqueAnchor numbers anchor when it is already used and marks the result as used
+func uniqueAnchor(anchor string, used map[string]bool) string {
+	unique := anchor
+	for n := 2; used[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", anchor, n)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,78 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddHeadingAnchors(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		wantHTML string
+		wantToc  []TocEntry
+	}{
+		{
+			name:     "no headings",
+			html:     "<p>text</p>",
+			wantHTML: "<p>text</p>",
+			wantToc:  []TocEntry{},
+		},
+		{
+			name:     "anchors from the heading text",
+			html:     `<h1>Getting Started</h1><h2 class="x">Install <em>Go</em> 1.22</h2>`,
+			wantHTML: `<h1 id="getting-started">Getting Started</h1><h2 id="install-go-1-22" class="x">Install <em>Go</em> 1.22</h2>`,
+			wantToc: []TocEntry{
+				{Level: 1, Text: "Getting Started", Anchor: "getting-started"},
+				{Level: 2, Text: "Install Go 1.22", Anchor: "install-go-1-22"},
+			},
+		},
+		{
+			name:     "repeated headings are numbered",
+			html:     "<h2>Intro</h2><h2>Intro</h2><h2>Intro</h2>",
+			wantHTML: `<h2 id="intro">Intro</h2><h2 id="intro-2">Intro</h2><h2 id="intro-3">Intro</h2>`,
+			wantToc: []TocEntry{
+				{Level: 2, Text: "Intro", Anchor: "intro"},
+				{Level: 2, Text: "Intro", Anchor: "intro-2"},
+				{Level: 2, Text: "Intro", Anchor: "intro-3"},
+			},
+		},
+		{
+			name:     "ids set by the author are kept and never reused",
+			html:     `<h2>Setup</h2><h2 id="setup">Custom</h2>`,
+			wantHTML: `<h2 id="setup-2">Setup</h2><h2 id="setup">Custom</h2>`,
+			wantToc: []TocEntry{
+				{Level: 2, Text: "Setup", Anchor: "setup-2"},
+				{Level: 2, Text: "Custom", Anchor: "setup"},
+			},
+		},
+		{
+			name:     "thai and entities",
+			html:     "<h3>การติดตั้ง &amp; ตั้งค่า</h3>",
+			wantHTML: `<h3 id="การติดตั้ง-ตั้งค่า">การติดตั้ง &amp; ตั้งค่า</h3>`,
+			wantToc:  []TocEntry{{Level: 3, Text: "การติดตั้ง & ตั้งค่า", Anchor: "การติดตั้ง-ตั้งค่า"}},
+		},
+		{
+			name:     "nothing to build an anchor from",
+			html:     "<h2>!!!</h2><h2>???</h2>",
+			wantHTML: `<h2 id="section">!!!</h2><h2 id="section-2">???</h2>`,
+			wantToc: []TocEntry{
+				{Level: 2, Text: "!!!", Anchor: "section"},
+				{Level: 2, Text: "???", Anchor: "section-2"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotHTML, gotToc := AddHeadingAnchors(test.html)
+
+			if gotHTML != test.wantHTML {
+				t.Errorf("AddHeadingAnchors() html = %q, want %q", gotHTML, test.wantHTML)
+			}
+			if !reflect.DeepEqual(gotToc, test.wantToc) {
+				t.Errorf("AddHeadingAnchors() toc = %+v, want %+v", gotToc, test.wantToc)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+ALTER TABLE blog DROP CONSTRAINT chk_blog_status;
+
+ALTER TABLE blog
+DROP COLUMN published_at,
+DROP COLUMN status;
@@ -0,0 +1,11 @@
+ALTER TABLE blog
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft',
+ADD COLUMN published_at TIMESTAMPTZ NULL;
+
+ALTER TABLE blog
+ADD CONSTRAINT chk_blog_status CHECK (status IN ('draft', 'in_review', 'published', 'archived'));
+
+-- Posts created before the lifecycle existed were already public
+UPDATE blog
+SET status = 'published',
+published_at = created_at;
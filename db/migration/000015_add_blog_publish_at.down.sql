@@ -0,0 +1,10 @@
+DROP INDEX idx_blog_scheduled_publish_at;
+
+UPDATE blog SET status = 'draft' WHERE status = 'scheduled';
+
+ALTER TABLE blog DROP CONSTRAINT chk_blog_status;
+
+ALTER TABLE blog
+ADD CONSTRAINT chk_blog_status CHECK (status IN ('draft', 'in_review', 'published', 'archived'));
+
+ALTER TABLE blog DROP COLUMN publish_at;
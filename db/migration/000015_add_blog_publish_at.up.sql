@@ -0,0 +1,9 @@
+ALTER TABLE blog
+ADD COLUMN publish_at TIMESTAMPTZ NULL;
+
+ALTER TABLE blog DROP CONSTRAINT chk_blog_status;
+
+ALTER TABLE blog
+ADD CONSTRAINT chk_blog_status CHECK (status IN ('draft', 'in_review', 'scheduled', 'published', 'archived'));
+
+CREATE INDEX idx_blog_scheduled_publish_at ON blog (publish_at) WHERE status = 'scheduled' AND deleted IS FALSE;
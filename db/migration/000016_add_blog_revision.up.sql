@@ -0,0 +1,25 @@
+CREATE TABLE blog_revision (
+    id BIGSERIAL PRIMARY KEY,
+    blog_id BIGINT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    image VARCHAR(255) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    edited_by BIGINT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE blog_revision
+ADD CONSTRAINT fk_blog_revision_blog FOREIGN KEY (blog_id) REFERENCES blog (id);
+
+ALTER TABLE blog_revision
+ADD CONSTRAINT fk_blog_revision_user FOREIGN KEY (edited_by) REFERENCES users (id);
+
+CREATE INDEX idx_blog_revision_blog_id ON blog_revision (blog_id, created_at DESC);
+
+-- Snapshot existing posts so their current content can be restored later.
+-- The original editor is unknown, so edited_by stays NULL.
+INSERT INTO blog_revision (blog_id, title, content, image, url, edited_by, created_at)
+SELECT id, title, content, image, url, NULL, updated_at
+FROM blog
+WHERE deleted IS FALSE;
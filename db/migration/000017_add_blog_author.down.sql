@@ -0,0 +1,8 @@
+DROP INDEX idx_blog_author_id;
+
+ALTER TABLE blog DROP CONSTRAINT fk_blog_updated_by;
+ALTER TABLE blog DROP CONSTRAINT fk_blog_author;
+
+ALTER TABLE blog
+DROP COLUMN updated_by,
+DROP COLUMN author_id;
@@ -0,0 +1,16 @@
+ALTER TABLE blog
+ADD COLUMN author_id BIGINT NULL,
+ADD COLUMN updated_by BIGINT NULL;
+
+-- Posts written before authorship was tracked are attributed to the administrator
+UPDATE blog SET author_id = 1;
+
+ALTER TABLE blog ALTER COLUMN author_id SET NOT NULL;
+
+ALTER TABLE blog
+ADD CONSTRAINT fk_blog_author FOREIGN KEY (author_id) REFERENCES users (id);
+
+ALTER TABLE blog
+ADD CONSTRAINT fk_blog_updated_by FOREIGN KEY (updated_by) REFERENCES users (id);
+
+CREATE INDEX idx_blog_author_id ON blog (author_id);
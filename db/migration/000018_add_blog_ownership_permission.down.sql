@@ -0,0 +1,2 @@
+DELETE FROM role_permission WHERE permission_id IN (10, 11);
+DELETE FROM permission WHERE id IN (10, 11);
@@ -0,0 +1,13 @@
+-- Blogs
+INSERT INTO permission (id, code, name, permission_group_id) VALUES (10, 'edit_own_blog', 'Edit Own Blog', 4);
+INSERT INTO permission (id, code, name, permission_group_id) VALUES (11, 'publish_blog', 'Publish Blog', 4);
+
+-- Roles that could already edit every blog keep the ability to publish
+INSERT INTO role_permission (role_id, permission_id, created_at, updated_at, deleted)
+SELECT DISTINCT role_id, 11, now(), now(), false
+FROM role_permission
+WHERE permission_id = 7
+AND deleted IS FALSE;
+
+INSERT INTO role_permission (role_id, permission_id, created_at, updated_at, deleted)
+SELECT 1, 10, now(), now(), false;
@@ -0,0 +1,11 @@
+DELETE FROM role_permission WHERE permission_id = 12;
+DELETE FROM permission WHERE id = 12;
+
+DROP TABLE blog_review;
+
+UPDATE blog SET status = 'in_review' WHERE status = 'approved';
+
+ALTER TABLE blog DROP CONSTRAINT chk_blog_status;
+
+ALTER TABLE blog
+ADD CONSTRAINT chk_blog_status CHECK (status IN ('draft', 'in_review', 'scheduled', 'published', 'archived'));
@@ -0,0 +1,34 @@
+ALTER TABLE blog DROP CONSTRAINT chk_blog_status;
+
+ALTER TABLE blog
+ADD CONSTRAINT chk_blog_status CHECK (status IN ('draft', 'in_review', 'approved', 'scheduled', 'published', 'archived'));
+
+CREATE TABLE blog_review (
+    id BIGSERIAL PRIMARY KEY,
+    blog_id BIGINT NOT NULL,
+    blog_revision_id BIGINT NULL,
+    user_id BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE blog_review
+ADD CONSTRAINT chk_blog_review_action CHECK (action IN ('submit', 'approve', 'request_changes', 'publish', 'comment'));
+
+ALTER TABLE blog_review
+ADD CONSTRAINT fk_blog_review_blog FOREIGN KEY (blog_id) REFERENCES blog (id);
+
+ALTER TABLE blog_review
+ADD CONSTRAINT fk_blog_review_blog_revision FOREIGN KEY (blog_revision_id) REFERENCES blog_revision (id);
+
+ALTER TABLE blog_review
+ADD CONSTRAINT fk_blog_review_user FOREIGN KEY (user_id) REFERENCES users (id);
+
+CREATE INDEX idx_blog_review_blog_id ON blog_review (blog_id, created_at DESC);
+
+-- Blogs
+INSERT INTO permission (id, code, name, permission_group_id) VALUES (12, 'review_blog', 'Review Blog', 4);
+
+INSERT INTO role_permission (role_id, permission_id, created_at, updated_at, deleted)
+SELECT 1, 12, now(), now(), false;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_blog_search_vector;
+
+ALTER TABLE blog DROP COLUMN IF EXISTS search_vector;
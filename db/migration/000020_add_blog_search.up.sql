@@ -0,0 +1,8 @@
+-- Title matches rank above content matches
+ALTER TABLE blog
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(content, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_blog_search_vector ON blog USING GIN (search_vector);
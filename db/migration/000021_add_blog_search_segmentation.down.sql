@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_blog_search_version;
+DROP INDEX IF EXISTS idx_blog_search_vector;
+
+ALTER TABLE blog
+DROP COLUMN IF EXISTS search_version,
+DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE blog
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(content, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_blog_search_vector ON blog USING GIN (search_vector);
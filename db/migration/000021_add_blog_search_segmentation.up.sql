@@ -0,0 +1,17 @@
+-- The search vector is now built by the API from segmented text so Thai, which is written
+-- without spaces between words, can be searched word by word.
+DROP INDEX IF EXISTS idx_blog_search_vector;
+
+ALTER TABLE blog DROP COLUMN search_vector;
+
+ALTER TABLE blog
+ADD COLUMN search_vector TSVECTOR NOT NULL DEFAULT ''::TSVECTOR,
+ADD COLUMN search_version INT NOT NULL DEFAULT 0;
+
+-- Keep existing posts searchable until the reindex job rebuilds them with the segmenter
+UPDATE blog SET search_vector =
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(content, '')), 'B');
+
+CREATE INDEX idx_blog_search_vector ON blog USING GIN (search_vector);
+CREATE INDEX idx_blog_search_version ON blog (search_version);
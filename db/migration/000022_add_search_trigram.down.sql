@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tag_name_trgm;
+DROP INDEX IF EXISTS idx_blog_title_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_blog_title_trgm ON blog USING GIN (LOWER(title) gin_trgm_ops);
+CREATE INDEX idx_tag_name_trgm ON tag USING GIN (LOWER(name) gin_trgm_ops);
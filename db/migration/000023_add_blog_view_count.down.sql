@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_blog_view_count;
+DROP INDEX IF EXISTS idx_blog_published_at;
+
+ALTER TABLE blog DROP COLUMN IF EXISTS view_count;
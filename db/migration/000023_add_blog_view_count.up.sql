@@ -0,0 +1,5 @@
+ALTER TABLE blog
+ADD COLUMN view_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_blog_published_at ON blog (published_at);
+CREATE INDEX idx_blog_view_count ON blog (view_count);
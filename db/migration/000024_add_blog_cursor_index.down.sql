@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_blog_published_at_id;
@@ -0,0 +1,2 @@
+-- Serves keyset pagination of public listings ordered by published_at, id
+CREATE INDEX idx_blog_published_at_id ON blog (published_at DESC, id DESC) WHERE deleted IS FALSE;
@@ -0,0 +1,5 @@
+ALTER TABLE blog
+DROP COLUMN IF EXISTS reading_time_minutes,
+DROP COLUMN IF EXISTS word_count,
+DROP COLUMN IF EXISTS auto_excerpt,
+DROP COLUMN IF EXISTS excerpt;
@@ -0,0 +1,7 @@
+-- excerpt is written by the author and may be empty, auto_excerpt is derived from the content.
+-- auto_excerpt, word_count and reading_time_minutes are filled in by the API and its reindex job.
+ALTER TABLE blog
+ADD COLUMN excerpt TEXT NOT NULL DEFAULT '',
+ADD COLUMN auto_excerpt TEXT NOT NULL DEFAULT '',
+ADD COLUMN word_count INT NOT NULL DEFAULT 0,
+ADD COLUMN reading_time_minutes INT NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+ALTER TABLE blog_revision DROP COLUMN IF EXISTS content_format;
+
+ALTER TABLE blog DROP CONSTRAINT IF EXISTS chk_blog_content_format;
+
+ALTER TABLE blog
+DROP COLUMN IF EXISTS content_html,
+DROP COLUMN IF EXISTS content_format;
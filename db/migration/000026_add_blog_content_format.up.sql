@@ -0,0 +1,14 @@
+-- content keeps the source as written, content_html the sanitized HTML served to readers.
+ALTER TABLE blog
+ADD COLUMN content_format VARCHAR(10) NOT NULL DEFAULT 'html',
+ADD COLUMN content_html TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE blog
+ADD CONSTRAINT chk_blog_content_format CHECK (content_format IN ('html', 'markdown'));
+
+-- Existing posts are HTML. The reindex job replaces this copy with the sanitized rendering,
+-- until then GetBlogByUrl sanitizes on read.
+UPDATE blog SET content_html = content;
+
+ALTER TABLE blog_revision
+ADD COLUMN content_format VARCHAR(10) NOT NULL DEFAULT 'html';
@@ -0,0 +1,3 @@
+DROP TABLE blog_redirect;
+
+DROP INDEX IF EXISTS idx_blog_url_unique;
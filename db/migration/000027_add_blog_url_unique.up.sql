@@ -0,0 +1,27 @@
+-- Posts sharing a URL keep it on the oldest post, the others get their id appended.
+-- The URL is shortened first so the suffix still fits in VARCHAR(255).
+UPDATE blog b
+SET url = left(b.url, 255 - length('-' || b.id)) || '-' || b.id
+WHERE b.deleted IS FALSE
+AND EXISTS (
+    SELECT 1
+    FROM blog other
+    WHERE other.deleted IS FALSE
+    AND other.url = b.url
+    AND other.id < b.id
+);
+
+CREATE UNIQUE INDEX idx_blog_url_unique ON blog (url) WHERE deleted IS FALSE;
+
+-- Old URLs of posts whose URL changed, so inbound links can be redirected
+CREATE TABLE blog_redirect (
+    id BIGSERIAL PRIMARY KEY,
+    old_url VARCHAR(255) NOT NULL,
+    blog_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE blog_redirect
+ADD CONSTRAINT fk_blog_redirect_blog FOREIGN KEY (blog_id) REFERENCES blog (id);
+
+CREATE UNIQUE INDEX idx_blog_redirect_old_url ON blog_redirect (old_url);
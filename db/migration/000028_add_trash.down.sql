@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_role_deleted_at;
+DROP INDEX IF EXISTS idx_tag_deleted_at;
+DROP INDEX IF EXISTS idx_blog_deleted_at;
+
+ALTER TABLE role_permission DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE user_role DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE blog_tag DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE "role" DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tag DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE blog DROP COLUMN IF EXISTS deleted_at;
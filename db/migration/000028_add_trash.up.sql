@@ -0,0 +1,20 @@
+-- deleted_at records when a row went to the trash. Rows deleted together with their parent
+-- (blog_tag, user_role, role_permission) share its deleted_at, which is how a restore finds them.
+ALTER TABLE blog ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE tag ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE "role" ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE blog_tag ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE user_role ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+ALTER TABLE role_permission ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+
+-- The deletion time of rows already in the trash is unknown, their last update is the closest estimate
+UPDATE blog SET deleted_at = updated_at WHERE deleted IS TRUE;
+UPDATE tag SET deleted_at = updated_at WHERE deleted IS TRUE;
+UPDATE "role" SET deleted_at = COALESCE(updated_at, created_at) WHERE deleted IS TRUE;
+UPDATE users SET deleted_at = COALESCE(updated_at, created_at) WHERE deleted IS TRUE;
+
+CREATE INDEX idx_blog_deleted_at ON blog (deleted_at) WHERE deleted IS TRUE;
+CREATE INDEX idx_tag_deleted_at ON tag (deleted_at) WHERE deleted IS TRUE;
+CREATE INDEX idx_role_deleted_at ON "role" (deleted_at) WHERE deleted IS TRUE;
+CREATE INDEX idx_users_deleted_at ON users (deleted_at) WHERE deleted IS TRUE;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS version;
+ALTER TABLE "role" DROP COLUMN IF EXISTS version;
+ALTER TABLE blog DROP COLUMN IF EXISTS version;
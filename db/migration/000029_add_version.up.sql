@@ -0,0 +1,5 @@
+-- version is bumped by every edit. Editors send back the version they loaded,
+-- a save based on an older version is rejected instead of overwriting someone else's changes.
+ALTER TABLE blog ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE "role" ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,16 @@
+-- Advisory edit locks. A blog has at most one lock, the editor renews it with heartbeats
+-- and it lapses at expires_at when they stop, so a closed browser never keeps a post locked.
+CREATE TABLE blog_lock (
+    blog_id BIGINT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE blog_lock
+ADD CONSTRAINT fk_blog_lock_blog FOREIGN KEY (blog_id) REFERENCES blog (id);
+
+ALTER TABLE blog_lock
+ADD CONSTRAINT fk_blog_lock_user FOREIGN KEY (user_id) REFERENCES users (id);
+
+CREATE INDEX idx_blog_lock_user_id ON blog_lock (user_id);
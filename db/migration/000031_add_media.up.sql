@@ -0,0 +1,16 @@
+-- Files uploaded to object storage, blogs reference them by id instead of sending the file inline
+CREATE TABLE media (
+    id BIGSERIAL PRIMARY KEY,
+    object_key VARCHAR(255) NOT NULL,
+    url VARCHAR(1024) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    original_name VARCHAR(255) NOT NULL DEFAULT '',
+    uploaded_by BIGINT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE media
+ADD CONSTRAINT fk_media_uploaded_by FOREIGN KEY (uploaded_by) REFERENCES users (id);
+
+CREATE UNIQUE INDEX idx_media_object_key ON media (object_key);
@@ -0,0 +1,17 @@
+DELETE FROM role_permission WHERE permission_id IN (13, 14);
+DELETE FROM permission WHERE id IN (13, 14);
+DELETE FROM permission_group WHERE id = 6;
+
+DROP TABLE IF EXISTS blog_media;
+
+ALTER TABLE blog DROP CONSTRAINT IF EXISTS fk_blog_image_media;
+DROP INDEX IF EXISTS idx_blog_image_media_id;
+ALTER TABLE blog DROP COLUMN IF EXISTS image_media_id;
+
+DROP INDEX IF EXISTS idx_media_created_at;
+DROP INDEX IF EXISTS idx_media_checksum;
+ALTER TABLE media DROP COLUMN IF EXISTS caption;
+ALTER TABLE media DROP COLUMN IF EXISTS alt_text;
+ALTER TABLE media DROP COLUMN IF EXISTS checksum;
+ALTER TABLE media DROP COLUMN IF EXISTS height;
+ALTER TABLE media DROP COLUMN IF EXISTS width;
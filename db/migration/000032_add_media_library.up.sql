@@ -0,0 +1,70 @@
+-- Metadata of the media library. Existing uploads have no checksum or dimensions, they were not recorded at the time.
+ALTER TABLE media ADD COLUMN width INT NULL;
+ALTER TABLE media ADD COLUMN height INT NULL;
+ALTER TABLE media ADD COLUMN checksum VARCHAR(64) NULL;
+ALTER TABLE media ADD COLUMN alt_text VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN caption TEXT NOT NULL DEFAULT '';
+
+-- Uploading the same file again returns the media item that already holds it, also for concurrent uploads
+CREATE UNIQUE INDEX idx_media_checksum ON media (checksum);
+CREATE INDEX idx_media_created_at ON media (created_at DESC, id DESC);
+
+-- The cover image of a blog
+ALTER TABLE blog ADD COLUMN image_media_id BIGINT NULL;
+
+ALTER TABLE blog
+ADD CONSTRAINT fk_blog_image_media FOREIGN KEY (image_media_id) REFERENCES media (id);
+
+CREATE INDEX idx_blog_image_media_id ON blog (image_media_id);
+
+-- Media items shown inline in the content of a blog
+CREATE TABLE blog_media (
+    blog_id BIGINT NOT NULL,
+    media_id BIGINT NOT NULL,
+    PRIMARY KEY (blog_id, media_id)
+);
+
+ALTER TABLE blog_media
+ADD CONSTRAINT fk_blog_media_blog FOREIGN KEY (blog_id) REFERENCES blog (id);
+
+ALTER TABLE blog_media
+ADD CONSTRAINT fk_blog_media_media FOREIGN KEY (media_id) REFERENCES media (id);
+
+CREATE INDEX idx_blog_media_media_id ON blog_media (media_id);
+
+-- Link the posts saved before blogs could reference media
+UPDATE blog b
+SET image_media_id = m.id
+FROM media m
+WHERE m.url = b.image;
+
+INSERT INTO blog_media (blog_id, media_id)
+SELECT b.id, m.id
+FROM blog b
+INNER JOIN media m ON strpos(b.content_html, m.url) > 0
+ON CONFLICT DO NOTHING;
+
+-- Media
+INSERT INTO permission_group (id, name) VALUES (6, 'Media');
+INSERT INTO permission (id, code, name, permission_group_id) VALUES (13, 'view_media', 'View Media', 6);
+INSERT INTO permission (id, code, name, permission_group_id) VALUES (14, 'edit_media', 'Edit Media', 6);
+
+-- Roles that could already upload images while editing blogs keep the ability to
+INSERT INTO role_permission (role_id, permission_id, created_at, updated_at, deleted)
+SELECT DISTINCT rp.role_id, p.id, now(), now(), false
+FROM role_permission rp
+CROSS JOIN permission p
+WHERE rp.permission_id IN (7, 10)
+AND rp.deleted IS FALSE
+AND p.id IN (13, 14);
+
+INSERT INTO role_permission (role_id, permission_id, created_at, updated_at, deleted)
+SELECT 1, p.id, now(), now(), false
+FROM permission p
+WHERE p.id IN (13, 14)
+AND NOT EXISTS (
+    SELECT 1 FROM role_permission rp
+    WHERE rp.role_id = 1
+    AND rp.permission_id = p.id
+    AND rp.deleted IS FALSE
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_blog_listed_at_id;
+CREATE INDEX idx_blog_published_at_id ON blog (published_at DESC, id DESC) WHERE deleted IS FALSE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_blog_published_at_id;
+-- Serves keyset pagination of listings ordered by publish date, or creation date for posts that were never published
+CREATE INDEX idx_blog_listed_at_id ON blog ((COALESCE(published_at, created_at)) DESC, id DESC) WHERE deleted IS FALSE;
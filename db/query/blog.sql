@@ -1,78 +1,419 @@
--- name: GetAllBlog :many
+-- name: ListBlog :many
+-- The snippet is cut from the text of the post without its markup, search.HighlightSnippet escapes it.
+-- The body is left empty unless with_content is set, listings send the excerpt instead.
 SELECT
-id, title, content, image, url, created_at, updated_at
-FROM blog
-WHERE deleted IS FALSE
-AND LOWER(title) LIKE '%' || LOWER($1) || '%'
-ORDER BY created_at DESC
-OFFSET $2
-LIMIT $3;
-
--- name: CountAllBlog :one
-SELECT COUNT(1) AS count
-FROM blog
-WHERE deleted IS FALSE
-AND LOWER(title) LIKE '%' || LOWER($1) || '%'
-LIMIT 1;
+b.id, b.title, CASE WHEN sqlc.arg(with_content)::bool THEN b.content_html ELSE '' END::TEXT AS content, b.image, b.url, b.status, b.published_at, b.view_count, b.created_at, b.updated_at,
+COALESCE(NULLIF(b.excerpt, ''), b.auto_excerpt)::TEXT AS excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name,
+CASE WHEN sqlc.arg(fuzzy)::text <> '' THEN word_similarity(sqlc.arg(fuzzy)::text, LOWER(b.title))
+    WHEN sqlc.arg(query)::text = '' THEN 0
+    ELSE ts_rank_cd(b.search_vector, to_tsquery('simple', sqlc.arg(query)::text))
+END::REAL AS rank,
+CASE WHEN sqlc.arg(query)::text = '' THEN ''
+    ELSE ts_headline('simple', regexp_replace(b.content_html, '<[^>]*>', ' ', 'g'), to_tsquery('simple', sqlc.arg(query)::text), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')
+END::TEXT AS snippet
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+WHERE b.deleted IS FALSE
+AND (sqlc.arg(query)::text = '' OR b.search_vector @@ to_tsquery('simple', sqlc.arg(query)::text))
+AND (sqlc.arg(fuzzy)::text = '' OR sqlc.arg(fuzzy)::text <% LOWER(b.title))
+AND b.status = ANY(sqlc.arg(statuses)::varchar[])
+AND (sqlc.arg(author_code)::varchar = '' OR u.code = sqlc.arg(author_code)::varchar)
+AND (cardinality(sqlc.arg(tags)::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY(sqlc.arg(tags)::varchar[])
+) >= CASE WHEN sqlc.arg(match_all_tags)::bool THEN cardinality(sqlc.arg(tags)::varchar[]) ELSE 1 END)
+AND (sqlc.narg(created_from)::timestamptz IS NULL OR b.created_at >= sqlc.narg(created_from)::timestamptz)
+AND (sqlc.narg(created_to)::timestamptz IS NULL OR b.created_at <= sqlc.narg(created_to)::timestamptz)
+AND (sqlc.narg(published_from)::timestamptz IS NULL OR b.published_at >= sqlc.narg(published_from)::timestamptz)
+AND (sqlc.narg(published_to)::timestamptz IS NULL OR b.published_at <= sqlc.narg(published_to)::timestamptz)
+ORDER BY
+CASE WHEN sqlc.arg(sort)::text = 'relevance' THEN
+    CASE WHEN sqlc.arg(fuzzy)::text <> '' THEN word_similarity(sqlc.arg(fuzzy)::text, LOWER(b.title))
+        WHEN sqlc.arg(query)::text <> '' THEN ts_rank_cd(b.search_vector, to_tsquery('simple', sqlc.arg(query)::text))
+    END
+END DESC NULLS LAST,
+CASE WHEN sqlc.arg(sort)::text = 'most_viewed' THEN b.view_count END DESC NULLS LAST,
+CASE WHEN sqlc.arg(sort)::text = 'title' THEN LOWER(b.title) END ASC NULLS LAST,
+CASE WHEN sqlc.arg(sort)::text = 'oldest' THEN b.created_at END ASC NULLS LAST,
+b.created_at DESC,
+b.id DESC
+OFFSET sqlc.arg(offset_rows)
+LIMIT sqlc.arg(limit_rows);
 
--- name: GetAllBlogWithTag :many
-SELECT DISTINCT
-b.id, b.title, b.content, b.image, b.url, b.created_at, b.updated_at
+-- name: ListBlogByCursor :many
+-- The snippet is cut from the text of the post without its markup, search.HighlightSnippet escapes it.
+-- The body is left empty unless with_content is set, listings send the excerpt instead.
+-- Posts are listed by publish date, posts that were never published by their creation date.
+SELECT
+b.id, b.title, CASE WHEN sqlc.arg(with_content)::bool THEN b.content_html ELSE '' END::TEXT AS content, b.image, b.url, b.status, b.published_at, b.view_count, b.created_at, b.updated_at,
+COALESCE(NULLIF(b.excerpt, ''), b.auto_excerpt)::TEXT AS excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name,
+CASE WHEN sqlc.arg(query)::text = '' THEN 0
+    ELSE ts_rank_cd(b.search_vector, to_tsquery('simple', sqlc.arg(query)::text))
+END::REAL AS rank,
+CASE WHEN sqlc.arg(query)::text = '' THEN ''
+    ELSE ts_headline('simple', regexp_replace(b.content_html, '<[^>]*>', ' ', 'g'), to_tsquery('simple', sqlc.arg(query)::text), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')
+END::TEXT AS snippet
 FROM blog b
-LEFT JOIN blog_tag bt ON b.id = bt.blog_id AND bt.deleted IS FALSE
-LEFT JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+INNER JOIN users u ON b.author_id = u.id
 WHERE b.deleted IS FALSE
-AND LOWER(b.title) LIKE '%' || LOWER($1) || '%'
-AND LOWER(t.name) = LOWER($2)
-ORDER BY b.created_at DESC
-OFFSET $3
-LIMIT $4;
-
--- name: CountAllBlogWithTag :one
-SELECT COUNT(DISTINCT b.id) AS count
+AND (sqlc.arg(query)::text = '' OR b.search_vector @@ to_tsquery('simple', sqlc.arg(query)::text))
+AND (sqlc.arg(fuzzy)::text = '' OR sqlc.arg(fuzzy)::text <% LOWER(b.title))
+AND b.status = ANY(sqlc.arg(statuses)::varchar[])
+AND (sqlc.arg(author_code)::varchar = '' OR u.code = sqlc.arg(author_code)::varchar)
+AND (cardinality(sqlc.arg(tags)::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY(sqlc.arg(tags)::varchar[])
+) >= CASE WHEN sqlc.arg(match_all_tags)::bool THEN cardinality(sqlc.arg(tags)::varchar[]) ELSE 1 END)
+AND (sqlc.narg(created_from)::timestamptz IS NULL OR b.created_at >= sqlc.narg(created_from)::timestamptz)
+AND (sqlc.narg(created_to)::timestamptz IS NULL OR b.created_at <= sqlc.narg(created_to)::timestamptz)
+AND (sqlc.narg(published_from)::timestamptz IS NULL OR b.published_at >= sqlc.narg(published_from)::timestamptz)
+AND (sqlc.narg(published_to)::timestamptz IS NULL OR b.published_at <= sqlc.narg(published_to)::timestamptz)
+AND (
+    sqlc.narg(cursor_listed_at)::timestamptz IS NULL
+    OR (COALESCE(b.published_at, b.created_at), b.id) < (sqlc.narg(cursor_listed_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+)
+ORDER BY COALESCE(b.published_at, b.created_at) DESC, b.id DESC
+LIMIT sqlc.arg(limit_rows);
+
+-- name: CountBlog :one
+SELECT COUNT(1) AS count
 FROM blog b
-LEFT JOIN blog_tag bt ON b.id = bt.blog_id AND bt.deleted IS FALSE
-LEFT JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+INNER JOIN users u ON b.author_id = u.id
 WHERE b.deleted IS FALSE
-AND LOWER(b.title) LIKE '%' || LOWER($1) || '%'
-AND LOWER(t.name) = LOWER($2)
+AND (sqlc.arg(query)::text = '' OR b.search_vector @@ to_tsquery('simple', sqlc.arg(query)::text))
+AND (sqlc.arg(fuzzy)::text = '' OR sqlc.arg(fuzzy)::text <% LOWER(b.title))
+AND b.status = ANY(sqlc.arg(statuses)::varchar[])
+AND (sqlc.arg(author_code)::varchar = '' OR u.code = sqlc.arg(author_code)::varchar)
+AND (cardinality(sqlc.arg(tags)::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY(sqlc.arg(tags)::varchar[])
+) >= CASE WHEN sqlc.arg(match_all_tags)::bool THEN cardinality(sqlc.arg(tags)::varchar[]) ELSE 1 END)
+AND (sqlc.narg(created_from)::timestamptz IS NULL OR b.created_at >= sqlc.narg(created_from)::timestamptz)
+AND (sqlc.narg(created_to)::timestamptz IS NULL OR b.created_at <= sqlc.narg(created_to)::timestamptz)
+AND (sqlc.narg(published_from)::timestamptz IS NULL OR b.published_at >= sqlc.narg(published_from)::timestamptz)
+AND (sqlc.narg(published_to)::timestamptz IS NULL OR b.published_at <= sqlc.narg(published_to)::timestamptz)
 LIMIT 1;
 
+-- name: GetBlogTagFacet :many
+SELECT
+ft.id, ft.name, COUNT(DISTINCT b.id) AS count
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+INNER JOIN blog_tag fbt ON b.id = fbt.blog_id AND fbt.deleted IS FALSE
+INNER JOIN tag ft ON fbt.tag_id = ft.id AND ft.deleted IS FALSE
+WHERE b.deleted IS FALSE
+AND (sqlc.arg(query)::text = '' OR b.search_vector @@ to_tsquery('simple', sqlc.arg(query)::text))
+AND (sqlc.arg(fuzzy)::text = '' OR sqlc.arg(fuzzy)::text <% LOWER(b.title))
+AND b.status = ANY(sqlc.arg(statuses)::varchar[])
+AND (sqlc.arg(author_code)::varchar = '' OR u.code = sqlc.arg(author_code)::varchar)
+AND (cardinality(sqlc.arg(tags)::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY(sqlc.arg(tags)::varchar[])
+) >= CASE WHEN sqlc.arg(match_all_tags)::bool THEN cardinality(sqlc.arg(tags)::varchar[]) ELSE 1 END)
+AND (sqlc.narg(created_from)::timestamptz IS NULL OR b.created_at >= sqlc.narg(created_from)::timestamptz)
+AND (sqlc.narg(created_to)::timestamptz IS NULL OR b.created_at <= sqlc.narg(created_to)::timestamptz)
+AND (sqlc.narg(published_from)::timestamptz IS NULL OR b.published_at >= sqlc.narg(published_from)::timestamptz)
+AND (sqlc.narg(published_to)::timestamptz IS NULL OR b.published_at <= sqlc.narg(published_to)::timestamptz)
+GROUP BY ft.id, ft.name
+ORDER BY count DESC, ft.name ASC
+LIMIT sqlc.arg(limit_rows);
+
 -- name: GetBlogById :one
 SELECT
-id, title, content, image, url, created_at, updated_at
-FROM blog
-WHERE deleted IS FALSE
-AND id = $1
+b.id, b.title, b.content, b.content_format, b.content_html, b.image, b.image_media_id, b.url, b.status, b.published_at, b.publish_at, b.view_count, b.created_at, b.updated_at,
+b.excerpt, b.auto_excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name,
+b.updated_by, e.code AS editor_code, e.first_name AS editor_first_name, e.last_name AS editor_last_name,
+b.version
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+LEFT JOIN users e ON b.updated_by = e.id
+WHERE b.deleted IS FALSE
+AND b.id = $1
 LIMIT 1;
 
 -- name: GetBlogByUrl :one
 SELECT
-id, title, content, image, url, created_at, updated_at
-FROM blog
-WHERE deleted IS FALSE
-AND url = $1
+b.id, b.title, b.content_html AS content, b.content_format, b.image, b.url, b.status, b.published_at, b.view_count, b.created_at, b.updated_at,
+COALESCE(NULLIF(b.excerpt, ''), b.auto_excerpt)::TEXT AS excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+WHERE b.deleted IS FALSE
+AND b.url = sqlc.arg(url)
+AND b.status = ANY(sqlc.arg(statuses)::varchar[])
 LIMIT 1;
 
 -- name: CreateBlog :one
 INSERT INTO blog
-(title, content, image, url, created_at)
-VALUES ($1, $2, $3, $4, NOW()::TIMESTAMPTZ)
-RETURNING *;
+(title, content, content_format, content_html, image, image_media_id, url, status, published_at, publish_at, author_id, excerpt, auto_excerpt, word_count, reading_time_minutes, search_vector, search_version, created_at)
+VALUES (
+    sqlc.arg(title),
+    sqlc.arg(content),
+    sqlc.arg(content_format),
+    sqlc.arg(content_html),
+    sqlc.arg(image),
+    sqlc.narg(image_media_id),
+    sqlc.arg(url),
+    sqlc.arg(status),
+    CASE WHEN sqlc.arg(status)::varchar = 'published' THEN NOW()::TIMESTAMPTZ END,
+    sqlc.narg(publish_at),
+    sqlc.arg(author_id),
+    sqlc.arg(excerpt),
+    sqlc.arg(auto_excerpt),
+    sqlc.arg(word_count),
+    sqlc.arg(reading_time_minutes),
+    setweight(to_tsvector('simple', sqlc.arg(search_title)::text), 'A') ||
+    setweight(to_tsvector('simple', sqlc.arg(search_content)::text), 'B'),
+    sqlc.arg(search_version),
+    NOW()::TIMESTAMPTZ
+)
+RETURNING id, title, content, content_format, content_html, image, image_media_id, url, status, published_at, publish_at, author_id, updated_by,
+excerpt, auto_excerpt, word_count, reading_time_minutes, created_at, updated_at, deleted, version;
 
--- name: UpdateBlog :exec
+-- name: UpdateBlog :execrows
+-- No row is updated when the blog was saved by someone else since the caller loaded version
 UPDATE blog
-SET title = $2,
-content = $3,
-image = $4,
-url = $5,
+SET title = sqlc.arg(title),
+content = sqlc.arg(content),
+content_format = sqlc.arg(content_format),
+content_html = sqlc.arg(content_html),
+image = sqlc.arg(image),
+image_media_id = sqlc.narg(image_media_id),
+url = sqlc.arg(url),
+status = COALESCE(sqlc.narg(status), status),
+published_at = CASE
+    WHEN COALESCE(sqlc.narg(status), status) = 'published' THEN COALESCE(published_at, NOW()::TIMESTAMPTZ)
+    ELSE published_at
+END,
+publish_at = CASE
+    WHEN COALESCE(sqlc.narg(status), status) = 'published' THEN NULL
+    ELSE COALESCE(sqlc.narg(publish_at), publish_at)
+END,
+updated_by = sqlc.arg(updated_by),
+excerpt = sqlc.arg(excerpt),
+auto_excerpt = sqlc.arg(auto_excerpt),
+word_count = sqlc.arg(word_count),
+reading_time_minutes = sqlc.arg(reading_time_minutes),
+search_vector = setweight(to_tsvector('simple', sqlc.arg(search_title)::text), 'A') ||
+    setweight(to_tsvector('simple', sqlc.arg(search_content)::text), 'B'),
+search_version = sqlc.arg(search_version),
+version = version + 1,
 updated_at = NOW()::TIMESTAMPTZ
 WHERE deleted IS FALSE
-AND id = $1;
+AND id = sqlc.arg(id)
+AND version = sqlc.arg(version);
 
 -- name: DeleteBlog :exec
 UPDATE blog
 SET updated_at = NOW()::TIMESTAMPTZ,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = sqlc.arg(deleted_at)::TIMESTAMPTZ
+WHERE deleted IS FALSE
+AND id = sqlc.arg(id);
+
+-- name: PublishScheduledBlog :many
+UPDATE blog
+SET status = 'published',
+published_at = publish_at,
+publish_at = NULL,
+version = version + 1,
+updated_at = NOW()::TIMESTAMPTZ
+WHERE deleted IS FALSE
+AND status = 'scheduled'
+AND publish_at <= sqlc.arg(due_at)::TIMESTAMPTZ
+RETURNING id;
+
+-- name: UpdateBlogStatus :one
+UPDATE blog
+SET status = sqlc.arg(status),
+published_at = CASE
+    WHEN sqlc.arg(status)::varchar = 'published' THEN COALESCE(published_at, NOW()::TIMESTAMPTZ)
+    ELSE published_at
+END,
+publish_at = CASE
+    WHEN sqlc.arg(status)::varchar = 'published' THEN NULL
+    ELSE COALESCE(sqlc.narg(publish_at), publish_at)
+END,
+updated_by = sqlc.arg(updated_by),
+version = version + 1,
+updated_at = NOW()::TIMESTAMPTZ
+WHERE deleted IS FALSE
+AND id = sqlc.arg(id)
+AND status = sqlc.arg(current_status)
+RETURNING id;
+
+-- name: GetBlogPendingSearchIndex :many
+-- Search versions are fingerprints rather than counters, any version but the current one is stale
+SELECT id, title, content, content_format
+FROM blog
+WHERE search_version <> sqlc.arg(search_version)::int
+ORDER BY id
+LIMIT sqlc.arg(limit_rows);
+
+-- name: UpdateBlogSearchIndex :exec
+UPDATE blog
+SET content_html = sqlc.arg(content_html),
+auto_excerpt = sqlc.arg(auto_excerpt),
+word_count = sqlc.arg(word_count),
+reading_time_minutes = sqlc.arg(reading_time_minutes),
+search_vector = setweight(to_tsvector('simple', sqlc.arg(search_title)::text), 'A') ||
+    setweight(to_tsvector('simple', sqlc.arg(search_content)::text), 'B'),
+search_version = sqlc.arg(search_version)::int
+WHERE id = sqlc.arg(id)
+AND search_version <> sqlc.arg(search_version)::int;
+
+-- name: SuggestBlog :many
+SELECT
+id, title, url
+FROM blog
+WHERE deleted IS FALSE
+AND status = ANY(sqlc.arg(statuses)::varchar[])
+AND (
+    LOWER(title) LIKE '%' || sqlc.arg(query_pattern)::text || '%' ESCAPE '\'
+    OR sqlc.arg(query)::text <% LOWER(title)
+)
+ORDER BY LOWER(title) LIKE sqlc.arg(query_pattern)::text || '%' ESCAPE '\' DESC,
+word_similarity(sqlc.arg(query)::text, LOWER(title)) DESC,
+created_at DESC
+LIMIT sqlc.arg(limit_rows);
+
+-- name: GetSearchSuggestion :one
+SELECT term::text AS term
+FROM (
+    SELECT title AS term, word_similarity(sqlc.arg(query)::text, LOWER(title)) AS score
+    FROM blog
+    WHERE deleted IS FALSE
+    AND status = ANY(sqlc.arg(statuses)::varchar[])
+    AND sqlc.arg(query)::text <% LOWER(title)
+    UNION ALL
+    SELECT name AS term, word_similarity(sqlc.arg(query)::text, LOWER(name)) AS score
+    FROM tag
+    WHERE deleted IS FALSE
+    AND sqlc.arg(query)::text <% LOWER(name)
+) suggestion
+ORDER BY score DESC
+LIMIT 1;
+
+-- name: IncrementBlogViewCount :exec
+UPDATE blog
+SET view_count = view_count + 1
 WHERE deleted IS FALSE
 AND id = $1;
+
+-- name: ExistsBlogUrl :one
+SELECT EXISTS (
+    SELECT 1
+    FROM blog
+    WHERE deleted IS FALSE
+    AND url = sqlc.arg(url)
+    AND id <> sqlc.arg(exclude_id)
+) AS exists;
+
+-- name: ExistsDeletedBlogById :one
+SELECT EXISTS (
+    SELECT 1
+    FROM blog
+    WHERE deleted IS TRUE
+    AND id = sqlc.arg(id)
+) AS exists;
+
+-- name: ExistsDeletedBlogByUrl :one
+SELECT EXISTS (
+    SELECT 1
+    FROM blog
+    WHERE deleted IS TRUE
+    AND url = sqlc.arg(url)
+    AND status = ANY(sqlc.arg(statuses)::varchar[])
+) AS exists;
+
+-- name: ListDeletedBlog :many
+SELECT
+b.id, b.title, b.url, b.status, b.author_id,
+u.first_name AS author_first_name, u.last_name AS author_last_name,
+b.deleted_at
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+WHERE b.deleted IS TRUE
+ORDER BY b.deleted_at DESC NULLS LAST, b.id DESC
+OFFSET sqlc.arg(offset_rows)
+LIMIT sqlc.arg(limit_rows);
+
+-- name: CountDeletedBlog :one
+SELECT COUNT(1) AS count
+FROM blog
+WHERE deleted IS TRUE;
+
+-- name: GetDeletedBlogById :one
+SELECT id, title, url, deleted_at
+FROM blog
+WHERE deleted IS TRUE
+AND id = $1
+LIMIT 1;
+
+-- name: RestoreBlog :one
+-- Tags removed together with the blog come back with it, tags removed earlier stay removed
+WITH target AS (
+    SELECT db.id, db.deleted_at
+    FROM blog db
+    WHERE db.deleted IS TRUE
+    AND db.id = sqlc.arg(id)
+), relinked_tags AS (
+    UPDATE blog_tag bt
+    SET deleted = FALSE,
+    deleted_at = NULL,
+    updated_at = NOW()::TIMESTAMP
+    FROM target
+    WHERE bt.blog_id = target.id
+    AND bt.deleted_at = target.deleted_at
+)
+UPDATE blog b
+SET deleted = FALSE,
+deleted_at = NULL,
+url = sqlc.arg(url),
+updated_at = NOW()::TIMESTAMPTZ
+FROM target
+WHERE b.id = target.id
+RETURNING b.id, b.title, b.url, b.status;
+
+-- name: PurgeBlog :execrows
+-- Permanently removes blogs in the trash, by id or deleted before a time, with everything that belongs to them
+WITH target AS (
+    SELECT id
+    FROM blog
+    WHERE deleted IS TRUE
+    AND (sqlc.narg(id)::bigint IS NULL OR id = sqlc.narg(id))
+    AND (sqlc.narg(deleted_before)::timestamptz IS NULL OR deleted_at < sqlc.narg(deleted_before))
+), purged_tags AS (
+    DELETE FROM blog_tag WHERE blog_id IN (SELECT id FROM target)
+), purged_reviews AS (
+    DELETE FROM blog_review WHERE blog_id IN (SELECT id FROM target)
+), purged_revisions AS (
+    DELETE FROM blog_revision WHERE blog_id IN (SELECT id FROM target)
+), purged_redirects AS (
+    DELETE FROM blog_redirect WHERE blog_id IN (SELECT id FROM target)
+), purged_locks AS (
+    DELETE FROM blog_lock WHERE blog_id IN (SELECT id FROM target)
+), purged_media AS (
+    DELETE FROM blog_media WHERE blog_id IN (SELECT id FROM target)
+)
+DELETE FROM blog
+WHERE id IN (SELECT id FROM target);
@@ -0,0 +1,32 @@
+-- name: GetBlogLock :one
+-- An expired lock is treated as no lock at all
+SELECT bl.blog_id, bl.user_id, u.code AS user_code, u.first_name AS user_first_name, u.last_name AS user_last_name,
+bl.acquired_at, bl.expires_at
+FROM blog_lock bl
+INNER JOIN users u ON bl.user_id = u.id
+WHERE bl.blog_id = $1
+AND bl.expires_at > NOW()
+LIMIT 1;
+
+-- name: AcquireBlogLock :one
+-- Takes the lock when it is free, expired or already held by the user, a heartbeat renews it the same way.
+-- With force the lock is taken over from whoever holds it. No row is returned when someone else holds it.
+INSERT INTO blog_lock (blog_id, user_id, acquired_at, expires_at)
+VALUES (sqlc.arg(blog_id), sqlc.arg(user_id), NOW(), NOW() + sqlc.arg(ttl_seconds)::int * INTERVAL '1 second')
+ON CONFLICT (blog_id) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+acquired_at = CASE
+    WHEN blog_lock.user_id = EXCLUDED.user_id AND blog_lock.expires_at > NOW() THEN blog_lock.acquired_at
+    ELSE EXCLUDED.acquired_at
+END,
+expires_at = EXCLUDED.expires_at
+WHERE blog_lock.user_id = EXCLUDED.user_id
+OR blog_lock.expires_at <= NOW()
+OR sqlc.arg(force)::bool
+RETURNING blog_id, user_id, acquired_at, expires_at;
+
+-- name: ReleaseBlogLock :execrows
+-- Releases the lock of the user, with force it is broken whoever holds it
+DELETE FROM blog_lock
+WHERE blog_id = sqlc.arg(blog_id)
+AND (user_id = sqlc.arg(user_id) OR sqlc.arg(force)::bool);
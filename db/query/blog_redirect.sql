@@ -0,0 +1,20 @@
+-- name: GetBlogRedirect :one
+SELECT b.id, b.url
+FROM blog_redirect br
+INNER JOIN blog b ON br.blog_id = b.id
+WHERE br.old_url = sqlc.arg(old_url)
+AND b.deleted IS FALSE
+AND b.status = ANY(sqlc.arg(statuses)::varchar[])
+LIMIT 1;
+
+-- name: UpsertBlogRedirect :exec
+INSERT INTO blog_redirect
+(old_url, blog_id, created_at)
+VALUES (sqlc.arg(old_url), sqlc.arg(blog_id), NOW()::TIMESTAMPTZ)
+ON CONFLICT (old_url) DO UPDATE
+SET blog_id = EXCLUDED.blog_id,
+created_at = EXCLUDED.created_at;
+
+-- name: DeleteBlogRedirectByOldUrl :exec
+DELETE FROM blog_redirect
+WHERE old_url = $1;
@@ -0,0 +1,22 @@
+-- name: GetBlogReviewByBlogId :many
+SELECT
+br.id,
+br.blog_id,
+br.blog_revision_id,
+br.user_id,
+u.code AS user_code,
+u.first_name AS user_first_name,
+u.last_name AS user_last_name,
+br.action,
+br.note,
+br.created_at
+FROM blog_review br
+INNER JOIN users u ON br.user_id = u.id
+WHERE br.blog_id = $1
+ORDER BY br.created_at DESC, br.id DESC;
+
+-- name: CreateBlogReview :one
+INSERT INTO blog_review
+(blog_id, blog_revision_id, user_id, action, note, created_at)
+VALUES ($1, $2, $3, $4, $5, NOW()::TIMESTAMPTZ)
+RETURNING *;
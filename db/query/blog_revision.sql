@@ -0,0 +1,42 @@
+-- name: GetBlogRevisionByBlogId :many
+SELECT
+br.id,
+br.blog_id,
+br.title,
+br.url,
+br.edited_by,
+u.first_name AS editor_first_name,
+u.last_name AS editor_last_name,
+br.created_at
+FROM blog_revision br
+LEFT JOIN users u ON br.edited_by = u.id
+WHERE br.blog_id = $1
+ORDER BY br.created_at DESC, br.id DESC
+OFFSET $2
+LIMIT $3;
+
+-- name: CountBlogRevisionByBlogId :one
+SELECT COUNT(1) AS count
+FROM blog_revision
+WHERE blog_id = $1
+LIMIT 1;
+
+-- name: GetBlogRevisionById :one
+SELECT
+id, blog_id, title, content, content_format, image, url, edited_by, created_at
+FROM blog_revision
+WHERE id = $1
+LIMIT 1;
+
+-- name: CreateBlogRevision :one
+INSERT INTO blog_revision
+(blog_id, title, content, image, url, edited_by, content_format, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW()::TIMESTAMPTZ)
+RETURNING *;
+
+-- name: GetLatestBlogRevisionIdByBlogId :one
+SELECT id
+FROM blog_revision
+WHERE blog_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
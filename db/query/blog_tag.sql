@@ -10,6 +10,19 @@ INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
 WHERE bt.deleted IS FALSE
 AND blog_id = $1;
 
+-- name: GetBlogTagByBlogIds :many
+SELECT
+bt.id,
+bt.blog_id,
+bt.tag_id,
+t.name,
+bt.deleted
+FROM blog_tag bt
+INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+WHERE bt.deleted IS FALSE
+AND bt.blog_id = ANY(sqlc.arg(blog_ids)::bigint[])
+ORDER BY bt.blog_id, bt.id;
+
 -- name: GetBlogTagByBlogIdAndTagId :one
 SELECT
 id, blog_id, tag_id, created_at, updated_at, deleted
@@ -28,6 +41,7 @@ VALUES
 -- name: DeleteBlogTag :exec
 UPDATE blog_tag
 SET deleted = True,
+deleted_at = NOW()::TIMESTAMPTZ,
 updated_at = NOW()::TIMESTAMP
 WHERE blog_id = $1
 AND tag_id = $2;
@@ -35,5 +49,7 @@ AND tag_id = $2;
 -- name: DeleteBlogTagByBlogId :exec
 UPDATE blog_tag
 SET deleted = True,
+deleted_at = sqlc.arg(deleted_at)::TIMESTAMPTZ,
 updated_at = NOW()::TIMESTAMP
-WHERE blog_id = $1;
+WHERE blog_id = sqlc.arg(blog_id)
+AND deleted IS FALSE;
@@ -0,0 +1,94 @@
+-- name: CreateMedia :one
+-- No row is returned when a media item with the same checksum exists already
+INSERT INTO media
+(object_key, url, content_type, size, width, height, checksum, original_name, uploaded_by, created_at)
+VALUES (sqlc.arg(object_key), sqlc.arg(url), sqlc.arg(content_type), sqlc.arg(size), sqlc.narg(width), sqlc.narg(height),
+sqlc.narg(checksum), sqlc.arg(original_name), sqlc.arg(uploaded_by), NOW()::TIMESTAMPTZ)
+ON CONFLICT (checksum) DO NOTHING
+RETURNING *;
+
+-- name: ListMedia :many
+-- query is matched literally, its LIKE wildcards are escaped by the caller
+SELECT m.id, m.object_key, m.url, m.content_type, m.size, m.width, m.height, m.checksum, m.alt_text, m.caption, m.original_name,
+m.uploaded_by, u.code AS uploader_code, u.first_name AS uploader_first_name, u.last_name AS uploader_last_name, m.created_at,
+(
+    SELECT COUNT(1) FROM blog b
+    WHERE b.image_media_id = m.id
+    OR EXISTS (SELECT 1 FROM blog_media bm WHERE bm.blog_id = b.id AND bm.media_id = m.id)
+) AS usage_count
+FROM media m
+LEFT JOIN users u ON m.uploaded_by = u.id
+WHERE (sqlc.arg(query)::text = ''
+    OR m.original_name ILIKE '%' || sqlc.arg(query)::text || '%' ESCAPE '\'
+    OR m.alt_text ILIKE '%' || sqlc.arg(query)::text || '%' ESCAPE '\'
+    OR m.caption ILIKE '%' || sqlc.arg(query)::text || '%' ESCAPE '\')
+AND (sqlc.arg(content_type)::text = '' OR m.content_type = sqlc.arg(content_type)::text)
+ORDER BY m.created_at DESC, m.id DESC
+OFFSET sqlc.arg(offset_rows)
+LIMIT sqlc.arg(limit_rows);
+
+-- name: CountMedia :one
+-- query is matched literally, its LIKE wildcards are escaped by the caller
+SELECT COUNT(1) AS count
+FROM media m
+WHERE (sqlc.arg(query)::text = ''
+    OR m.original_name ILIKE '%' || sqlc.arg(query)::text || '%' ESCAPE '\'
+    OR m.alt_text ILIKE '%' || sqlc.arg(query)::text || '%' ESCAPE '\'
+    OR m.caption ILIKE '%' || sqlc.arg(query)::text || '%' ESCAPE '\')
+AND (sqlc.arg(content_type)::text = '' OR m.content_type = sqlc.arg(content_type)::text);
+
+-- name: GetMediaById :one
+SELECT m.id, m.object_key, m.url, m.content_type, m.size, m.width, m.height, m.checksum, m.alt_text, m.caption, m.original_name,
+m.uploaded_by, u.code AS uploader_code, u.first_name AS uploader_first_name, u.last_name AS uploader_last_name, m.created_at,
+(
+    SELECT COUNT(1) FROM blog b
+    WHERE b.image_media_id = m.id
+    OR EXISTS (SELECT 1 FROM blog_media bm WHERE bm.blog_id = b.id AND bm.media_id = m.id)
+) AS usage_count
+FROM media m
+LEFT JOIN users u ON m.uploaded_by = u.id
+WHERE m.id = $1
+LIMIT 1;
+
+-- name: GetMediaByChecksum :one
+SELECT * FROM media
+WHERE checksum = $1
+LIMIT 1;
+
+-- name: GetMediaByUrls :many
+SELECT id, url
+FROM media
+WHERE url = ANY(sqlc.arg(urls)::varchar[]);
+
+-- name: UpdateMedia :one
+UPDATE media
+SET alt_text = sqlc.arg(alt_text),
+caption = sqlc.arg(caption)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteMedia :one
+-- Media used by a blog, including blogs in the trash, is kept. No row is returned then.
+DELETE FROM media m
+WHERE m.id = $1
+AND NOT EXISTS (SELECT 1 FROM blog b WHERE b.image_media_id = m.id)
+AND NOT EXISTS (SELECT 1 FROM blog_media bm WHERE bm.media_id = m.id)
+RETURNING m.id, m.object_key;
+
+-- name: ListBlogByMediaId :many
+SELECT b.id, b.title, b.url, b.status, b.deleted
+FROM blog b
+WHERE b.image_media_id = sqlc.arg(media_id)::bigint
+OR EXISTS (SELECT 1 FROM blog_media bm WHERE bm.blog_id = b.id AND bm.media_id = sqlc.arg(media_id)::bigint)
+ORDER BY b.id DESC;
+
+-- name: SetBlogMedia :exec
+-- Replaces the media shown inline in a blog
+WITH removed AS (
+    DELETE FROM blog_media
+    WHERE blog_id = sqlc.arg(blog_id)
+    AND NOT (media_id = ANY(sqlc.arg(media_ids)::bigint[]))
+)
+INSERT INTO blog_media (blog_id, media_id)
+SELECT sqlc.arg(blog_id), unnest(sqlc.arg(media_ids)::bigint[])
+ON CONFLICT DO NOTHING;
@@ -17,7 +17,7 @@ LIMIT 1;
 
 -- name: GetRoleById :one
 SELECT
-id, name
+id, name, version
 FROM "role"
 WHERE deleted IS False 
 AND id = $1
@@ -28,19 +28,24 @@ INSERT INTO "role" (name, created_at)
 VALUES ($1, NOW()::TIMESTAMP)
 RETURNING *;
 
--- name: UpdateRole :exec
+-- name: UpdateRole :one
+-- No row is returned when the role was saved by someone else since the caller loaded version
 UPDATE "role"
 SET name = $2,
+version = version + 1,
 updated_at = NOW()::TIMESTAMP
 WHERE deleted IS False 
-AND id = $1;
+AND id = $1
+AND version = $3
+RETURNING id, name, version;
 
 -- name: DeleteRole :exec
 UPDATE "role"
 SET updated_at = NOW()::TIMESTAMP,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = sqlc.arg(deleted_at)::TIMESTAMPTZ
 WHERE deleted IS False 
-AND id = $1;
+AND id = sqlc.arg(id);
 
 -- name: GetRoleByUserId :many
 SELECT r.id
@@ -56,4 +61,56 @@ SELECT
 id, name
 FROM "role"
 WHERE deleted IS False
-ORDER BY name ASC;
\ No newline at end of file
+ORDER BY name ASC;
+-- name: ListDeletedRole :many
+SELECT id, name, deleted_at
+FROM "role"
+WHERE deleted IS TRUE
+ORDER BY deleted_at DESC NULLS LAST, id DESC
+OFFSET sqlc.arg(offset_rows)
+LIMIT sqlc.arg(limit_rows);
+
+-- name: CountDeletedRole :one
+SELECT COUNT(1) AS count
+FROM "role"
+WHERE deleted IS TRUE;
+
+-- name: RestoreRole :one
+-- Permissions removed together with the role come back with it
+WITH target AS (
+    SELECT dr.id, dr.deleted_at
+    FROM "role" dr
+    WHERE dr.deleted IS TRUE
+    AND dr.id = sqlc.arg(id)
+), relinked_permissions AS (
+    UPDATE "role_permission" rp
+    SET deleted = FALSE,
+    deleted_at = NULL,
+    updated_at = NOW()::TIMESTAMP
+    FROM target
+    WHERE rp.role_id = target.id
+    AND rp.deleted_at = target.deleted_at
+)
+UPDATE "role" r
+SET deleted = FALSE,
+deleted_at = NULL,
+updated_at = NOW()::TIMESTAMP
+FROM target
+WHERE r.id = target.id
+RETURNING r.id, r.name;
+
+-- name: PurgeRole :execrows
+-- Permanently removes roles in the trash, by id or deleted before a time, and takes them away from every user
+WITH target AS (
+    SELECT id
+    FROM "role"
+    WHERE deleted IS TRUE
+    AND (sqlc.narg(id)::bigint IS NULL OR id = sqlc.narg(id))
+    AND (sqlc.narg(deleted_before)::timestamptz IS NULL OR deleted_at < sqlc.narg(deleted_before))
+), purged_permissions AS (
+    DELETE FROM "role_permission" WHERE role_id IN (SELECT id FROM target)
+), purged_user_roles AS (
+    DELETE FROM user_role WHERE role_id IN (SELECT id FROM target)
+)
+DELETE FROM "role"
+WHERE id IN (SELECT id FROM target);
@@ -15,7 +15,8 @@ RETURNING id, role_id, permission_id, created_at, updated_at, deleted;
 -- name: DeleteRolePermission :exec
 UPDATE "role_permission"
 SET updated_at = NOW()::TIMESTAMP,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = NOW()::TIMESTAMPTZ
 WHERE deleted IS False
 AND role_id = $1
 AND permission_id = $2;
@@ -23,5 +24,7 @@ AND permission_id = $2;
 -- name: DeleteRolePermissionByRoleId :exec
 UPDATE "role_permission"
 SET updated_at = NOW()::TIMESTAMP,
-deleted = TRUE
-WHERE role_id = $1;
+deleted = TRUE,
+deleted_at = sqlc.arg(deleted_at)::TIMESTAMPTZ
+WHERE role_id = sqlc.arg(role_id)
+AND deleted IS False;
@@ -3,18 +3,38 @@ SELECT
 id, name
 FROM tag
 WHERE deleted IS FALSE
-AND LOWER(name) LIKE '%' || LOWER($1) || '%'
-ORDER BY name ASC
-OFFSET $2
-LIMIT $3;
+AND (
+    LOWER(name) LIKE '%' || LOWER(sqlc.arg(name_pattern)::text) || '%' ESCAPE '\'
+    OR LOWER(sqlc.arg(name)::text) <% LOWER(name)
+)
+ORDER BY word_similarity(LOWER(sqlc.arg(name)::text), LOWER(name)) DESC, name ASC
+OFFSET sqlc.arg(offset_rows)
+LIMIT sqlc.arg(limit_rows);
 
 -- name: CountAllTag :one
 SELECT COUNT(1) AS count
 FROM tag
 WHERE deleted IS FALSE
-AND LOWER(name) LIKE '%' || LOWER($1) || '%' 
+AND (
+    LOWER(name) LIKE '%' || LOWER(sqlc.arg(name_pattern)::text) || '%' ESCAPE '\'
+    OR LOWER(sqlc.arg(name)::text) <% LOWER(name)
+)
 LIMIT 1;
 
+-- name: SuggestTag :many
+SELECT
+id, name
+FROM tag
+WHERE deleted IS FALSE
+AND (
+    LOWER(name) LIKE LOWER(sqlc.arg(query_pattern)::text) || '%' ESCAPE '\'
+    OR LOWER(sqlc.arg(query)::text) <% LOWER(name)
+)
+ORDER BY LOWER(name) LIKE LOWER(sqlc.arg(query_pattern)::text) || '%' ESCAPE '\' DESC,
+word_similarity(LOWER(sqlc.arg(query)::text), LOWER(name)) DESC,
+name ASC
+LIMIT sqlc.arg(limit_rows);
+
 -- name: GetTagById :one
 SELECT
 id, name
@@ -39,7 +59,8 @@ AND id = $1;
 -- name: DeleteTag :exec
 UPDATE tag
 SET updated_at = NOW()::TIMESTAMPTZ,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = NOW()::TIMESTAMPTZ
 WHERE deleted IS FALSE
 AND id = $1;
 
@@ -50,3 +71,39 @@ FROM tag
 WHERE deleted IS FALSE
 AND created_at = $1
 ORDER BY name ASC;
+
+-- name: ListDeletedTag :many
+SELECT id, name, deleted_at
+FROM tag
+WHERE deleted IS TRUE
+ORDER BY deleted_at DESC NULLS LAST, id DESC
+OFFSET sqlc.arg(offset_rows)
+LIMIT sqlc.arg(limit_rows);
+
+-- name: CountDeletedTag :one
+SELECT COUNT(1) AS count
+FROM tag
+WHERE deleted IS TRUE;
+
+-- name: RestoreTag :one
+UPDATE tag
+SET deleted = FALSE,
+deleted_at = NULL,
+updated_at = NOW()::TIMESTAMPTZ
+WHERE deleted IS TRUE
+AND id = $1
+RETURNING id, name;
+
+-- name: PurgeTag :execrows
+-- Permanently removes tags in the trash, by id or deleted before a time, and detaches them from every blog
+WITH target AS (
+    SELECT id
+    FROM tag
+    WHERE deleted IS TRUE
+    AND (sqlc.narg(id)::bigint IS NULL OR id = sqlc.narg(id))
+    AND (sqlc.narg(deleted_before)::timestamptz IS NULL OR deleted_at < sqlc.narg(deleted_before))
+), purged_blog_tags AS (
+    DELETE FROM blog_tag WHERE tag_id IN (SELECT id FROM target)
+)
+DELETE FROM tag
+WHERE id IN (SELECT id FROM target);
@@ -36,7 +36,8 @@ last_name,
 username,
 email,
 phone,
-description
+description,
+version
 FROM users
 WHERE id = $1 
 AND deleted IS False
@@ -60,6 +61,7 @@ FROM users
 LIMIT 1;
 
 -- name: UpdateUser :one
+-- No row is returned when the user was saved by someone else since the caller loaded version
 UPDATE users
 SET
   first_name = COALESCE(sqlc.narg(first_name), first_name),
@@ -69,16 +71,19 @@ SET
   description = COALESCE(sqlc.narg(description), description),
   -- hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password),
   -- password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at),
+  version = version + 1,
   updated_at = NOW()::TIMESTAMP
 WHERE
   id = sqlc.arg(user_id) AND deleted IS False
+  AND version = sqlc.arg(version)
 RETURNING *;
 
 -- name: DeleteUser :exec
 UPDATE users
 SET deleted = True,
+deleted_at = sqlc.arg(deleted_at)::TIMESTAMPTZ,
 updated_at = NOW()
-WHERE id = $1
+WHERE id = sqlc.arg(id)
 AND deleted IS False;
 
 -- name: GetUserHashedPassword :one
@@ -101,3 +106,76 @@ WHERE
 SELECT * FROM users
 WHERE email = $1
 AND deleted IS False;
+
+-- name: ListDeletedUser :many
+SELECT id, code, username, first_name, last_name, email, deleted_at
+FROM users
+WHERE deleted IS TRUE
+ORDER BY deleted_at DESC NULLS LAST, id DESC
+OFFSET sqlc.arg(offset_rows)
+LIMIT sqlc.arg(limit_rows);
+
+-- name: CountDeletedUser :one
+SELECT COUNT(1) AS count
+FROM users
+WHERE deleted IS TRUE;
+
+-- name: GetDeletedUserById :one
+SELECT id, code, username, first_name, last_name, email, deleted_at
+FROM users
+WHERE deleted IS TRUE
+AND id = $1
+LIMIT 1;
+
+-- name: RestoreUser :one
+-- Roles removed together with the user come back with it
+WITH target AS (
+    SELECT du.id, du.deleted_at
+    FROM users du
+    WHERE du.deleted IS TRUE
+    AND du.id = sqlc.arg(id)
+), relinked_roles AS (
+    UPDATE user_role ur
+    SET deleted = FALSE,
+    deleted_at = NULL,
+    updated_at = NOW()::TIMESTAMP
+    FROM target
+    WHERE ur.user_id = target.id
+    AND ur.deleted_at = target.deleted_at
+)
+UPDATE users u
+SET deleted = FALSE,
+deleted_at = NULL,
+updated_at = NOW()
+FROM target
+WHERE u.id = target.id
+RETURNING u.id, u.code, u.username, u.first_name, u.last_name, u.email;
+
+-- name: PurgeUser :execrows
+-- Permanently removes users in the trash, by id or deleted before a time. Users who still author
+-- blogs or reviews are kept, their content has to be handed over or purged first.
+WITH target AS (
+    SELECT u.id
+    FROM users u
+    WHERE u.deleted IS TRUE
+    AND (sqlc.narg(id)::bigint IS NULL OR u.id = sqlc.narg(id))
+    AND (sqlc.narg(deleted_before)::timestamptz IS NULL OR u.deleted_at < sqlc.narg(deleted_before))
+    AND NOT EXISTS (SELECT 1 FROM blog b WHERE b.author_id = u.id)
+    AND NOT EXISTS (SELECT 1 FROM blog_review br WHERE br.user_id = u.id)
+), purged_roles AS (
+    DELETE FROM user_role WHERE user_id IN (SELECT id FROM target)
+), purged_sessions AS (
+    DELETE FROM sessions WHERE user_id IN (SELECT id FROM target)
+), purged_reset_passwords AS (
+    DELETE FROM reset_password WHERE user_id IN (SELECT id FROM target)
+), purged_blog_locks AS (
+    DELETE FROM blog_lock WHERE user_id IN (SELECT id FROM target)
+), detached_blogs AS (
+    UPDATE blog SET updated_by = NULL WHERE updated_by IN (SELECT id FROM target)
+), detached_revisions AS (
+    UPDATE blog_revision SET edited_by = NULL WHERE edited_by IN (SELECT id FROM target)
+), detached_media AS (
+    UPDATE media SET uploaded_by = NULL WHERE uploaded_by IN (SELECT id FROM target)
+)
+DELETE FROM users
+WHERE id IN (SELECT id FROM target);
@@ -7,5 +7,7 @@ VALUES
 -- name: DeleteUserRoleByUserId :exec
 UPDATE user_role
 SET deleted = True,
+deleted_at = sqlc.arg(deleted_at)::TIMESTAMPTZ,
 updated_at = NOW()::TIMESTAMP
-WHERE user_id = $1;
+WHERE user_id = sqlc.arg(user_id)
+AND deleted IS False;
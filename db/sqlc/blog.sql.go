@@ -8,41 +8,73 @@ package db
 import (
 	"context"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const countAllBlog = `-- name: CountAllBlog :one
+const countBlog = `-- name: CountBlog :one
 SELECT COUNT(1) AS count
-FROM blog
-WHERE deleted IS FALSE
-AND LOWER(title) LIKE '%' || LOWER($1) || '%'
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+WHERE b.deleted IS FALSE
+AND ($1::text = '' OR b.search_vector @@ to_tsquery('simple', $1::text))
+AND ($2::text = '' OR $2::text <% LOWER(b.title))
+AND b.status = ANY($3::varchar[])
+AND ($4::varchar = '' OR u.code = $4::varchar)
+AND (cardinality($5::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY($5::varchar[])
+) >= CASE WHEN $6::bool THEN cardinality($5::varchar[]) ELSE 1 END)
+AND ($7::timestamptz IS NULL OR b.created_at >= $7::timestamptz)
+AND ($8::timestamptz IS NULL OR b.created_at <= $8::timestamptz)
+AND ($9::timestamptz IS NULL OR b.published_at >= $9::timestamptz)
+AND ($10::timestamptz IS NULL OR b.published_at <= $10::timestamptz)
 LIMIT 1
 `
 
-func (q *Queries) CountAllBlog(ctx context.Context, lower string) (int64, error) {
-	row := q.db.QueryRow(ctx, countAllBlog, lower)
+type CountBlogParams struct {
+	Query         string             `json:"query"`
+	Fuzzy         string             `json:"fuzzy"`
+	Statuses      []string           `json:"statuses"`
+	AuthorCode    string             `json:"author_code"`
+	Tags          []string           `json:"tags"`
+	MatchAllTags  bool               `json:"match_all_tags"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	PublishedFrom pgtype.Timestamptz `json:"published_from"`
+	PublishedTo   pgtype.Timestamptz `json:"published_to"`
+}
+
+func (q *Queries) CountBlog(ctx context.Context, arg CountBlogParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countBlog,
+		arg.Query,
+		arg.Fuzzy,
+		arg.Statuses,
+		arg.AuthorCode,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
-const countAllBlogWithTag = `-- name: CountAllBlogWithTag :one
-SELECT COUNT(DISTINCT b.id) AS count
-FROM blog b
-LEFT JOIN blog_tag bt ON b.id = bt.blog_id AND bt.deleted IS FALSE
-LEFT JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
-WHERE b.deleted IS FALSE
-AND LOWER(b.title) LIKE '%' || LOWER($1) || '%'
-AND LOWER(t.name) = LOWER($2)
-LIMIT 1
+const countDeletedBlog = `-- name: CountDeletedBlog :one
+SELECT COUNT(1) AS count
+FROM blog
+WHERE deleted IS TRUE
 `
 
-type CountAllBlogWithTagParams struct {
-	Lower   string `json:"lower"`
-	Lower_2 string `json:"lower_2"`
-}
-
-func (q *Queries) CountAllBlogWithTag(ctx context.Context, arg CountAllBlogWithTagParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countAllBlogWithTag, arg.Lower, arg.Lower_2)
+func (q *Queries) CountDeletedBlog(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeletedBlog)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -50,35 +82,119 @@ func (q *Queries) CountAllBlogWithTag(ctx context.Context, arg CountAllBlogWithT
 
 const createBlog = `-- name: CreateBlog :one
 INSERT INTO blog
-(title, content, image, url, created_at)
-VALUES ($1, $2, $3, $4, NOW()::TIMESTAMPTZ)
-RETURNING id, title, content, image, url, created_at, updated_at, deleted
+(title, content, content_format, content_html, image, image_media_id, url, status, published_at, publish_at, author_id, excerpt, auto_excerpt, word_count, reading_time_minutes, search_vector, search_version, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    CASE WHEN $8::varchar = 'published' THEN NOW()::TIMESTAMPTZ END,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14,
+    setweight(to_tsvector('simple', $15::text), 'A') ||
+    setweight(to_tsvector('simple', $16::text), 'B'),
+    $17,
+    NOW()::TIMESTAMPTZ
+)
+RETURNING id, title, content, content_format, content_html, image, image_media_id, url, status, published_at, publish_at, author_id, updated_by,
+excerpt, auto_excerpt, word_count, reading_time_minutes, created_at, updated_at, deleted, version
 `
 
 type CreateBlogParams struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Image   string `json:"image"`
-	Url     string `json:"url"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	ContentFormat      string             `json:"content_format"`
+	ContentHtml        string             `json:"content_html"`
+	Image              string             `json:"image"`
+	ImageMediaID       pgtype.Int8        `json:"image_media_id"`
+	Url                string             `json:"url"`
+	Status             string             `json:"status"`
+	PublishAt          pgtype.Timestamptz `json:"publish_at"`
+	AuthorID           int64              `json:"author_id"`
+	Excerpt            string             `json:"excerpt"`
+	AutoExcerpt        string             `json:"auto_excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	SearchTitle        string             `json:"search_title"`
+	SearchContent      string             `json:"search_content"`
+	SearchVersion      int32              `json:"search_version"`
+}
+
+type CreateBlogRow struct {
+	ID                 int64              `json:"id"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	ContentFormat      string             `json:"content_format"`
+	ContentHtml        string             `json:"content_html"`
+	Image              string             `json:"image"`
+	ImageMediaID       pgtype.Int8        `json:"image_media_id"`
+	Url                string             `json:"url"`
+	Status             string             `json:"status"`
+	PublishedAt        pgtype.Timestamptz `json:"published_at"`
+	PublishAt          pgtype.Timestamptz `json:"publish_at"`
+	AuthorID           int64              `json:"author_id"`
+	UpdatedBy          pgtype.Int8        `json:"updated_by"`
+	Excerpt            string             `json:"excerpt"`
+	AutoExcerpt        string             `json:"auto_excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	Deleted            bool               `json:"deleted"`
+	Version            int32              `json:"version"`
 }
 
-func (q *Queries) CreateBlog(ctx context.Context, arg CreateBlogParams) (Blog, error) {
+func (q *Queries) CreateBlog(ctx context.Context, arg CreateBlogParams) (CreateBlogRow, error) {
 	row := q.db.QueryRow(ctx, createBlog,
 		arg.Title,
 		arg.Content,
+		arg.ContentFormat,
+		arg.ContentHtml,
 		arg.Image,
+		arg.ImageMediaID,
 		arg.Url,
+		arg.Status,
+		arg.PublishAt,
+		arg.AuthorID,
+		arg.Excerpt,
+		arg.AutoExcerpt,
+		arg.WordCount,
+		arg.ReadingTimeMinutes,
+		arg.SearchTitle,
+		arg.SearchContent,
+		arg.SearchVersion,
 	)
-	var i Blog
+	var i CreateBlogRow
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
 		&i.Content,
+		&i.ContentFormat,
+		&i.ContentHtml,
 		&i.Image,
+		&i.ImageMediaID,
 		&i.Url,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.AuthorID,
+		&i.UpdatedBy,
+		&i.Excerpt,
+		&i.AutoExcerpt,
+		&i.WordCount,
+		&i.ReadingTimeMinutes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Deleted,
+		&i.Version,
 	)
 	return i, err
 }
@@ -86,60 +202,554 @@ func (q *Queries) CreateBlog(ctx context.Context, arg CreateBlogParams) (Blog, e
 const deleteBlog = `-- name: DeleteBlog :exec
 UPDATE blog
 SET updated_at = NOW()::TIMESTAMPTZ,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = $1::TIMESTAMPTZ
 WHERE deleted IS FALSE
-AND id = $1
+AND id = $2
 `
 
-func (q *Queries) DeleteBlog(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteBlog, id)
+type DeleteBlogParams struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	ID        int64     `json:"id"`
+}
+
+func (q *Queries) DeleteBlog(ctx context.Context, arg DeleteBlogParams) error {
+	_, err := q.db.Exec(ctx, deleteBlog, arg.DeletedAt, arg.ID)
 	return err
 }
 
-const getAllBlog = `-- name: GetAllBlog :many
+const existsBlogUrl = `-- name: ExistsBlogUrl :one
+SELECT EXISTS (
+    SELECT 1
+    FROM blog
+    WHERE deleted IS FALSE
+    AND url = $1
+    AND id <> $2
+) AS exists
+`
+
+type ExistsBlogUrlParams struct {
+	Url       string `json:"url"`
+	ExcludeID int64  `json:"exclude_id"`
+}
+
+func (q *Queries) ExistsBlogUrl(ctx context.Context, arg ExistsBlogUrlParams) (bool, error) {
+	row := q.db.QueryRow(ctx, existsBlogUrl, arg.Url, arg.ExcludeID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const existsDeletedBlogById = `-- name: ExistsDeletedBlogById :one
+SELECT EXISTS (
+    SELECT 1
+    FROM blog
+    WHERE deleted IS TRUE
+    AND id = $1
+) AS exists
+`
+
+func (q *Queries) ExistsDeletedBlogById(ctx context.Context, id int64) (bool, error) {
+	row := q.db.QueryRow(ctx, existsDeletedBlogById, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const existsDeletedBlogByUrl = `-- name: ExistsDeletedBlogByUrl :one
+SELECT EXISTS (
+    SELECT 1
+    FROM blog
+    WHERE deleted IS TRUE
+    AND url = $1
+    AND status = ANY($2::varchar[])
+) AS exists
+`
+
+type ExistsDeletedBlogByUrlParams struct {
+	Url      string   `json:"url"`
+	Statuses []string `json:"statuses"`
+}
+
+func (q *Queries) ExistsDeletedBlogByUrl(ctx context.Context, arg ExistsDeletedBlogByUrlParams) (bool, error) {
+	row := q.db.QueryRow(ctx, existsDeletedBlogByUrl, arg.Url, arg.Statuses)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const getBlogById = `-- name: GetBlogById :one
 SELECT
-id, title, content, image, url, created_at, updated_at
+b.id, b.title, b.content, b.content_format, b.content_html, b.image, b.image_media_id, b.url, b.status, b.published_at, b.publish_at, b.view_count, b.created_at, b.updated_at,
+b.excerpt, b.auto_excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name,
+b.updated_by, e.code AS editor_code, e.first_name AS editor_first_name, e.last_name AS editor_last_name,
+b.version
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+LEFT JOIN users e ON b.updated_by = e.id
+WHERE b.deleted IS FALSE
+AND b.id = $1
+LIMIT 1
+`
+
+type GetBlogByIdRow struct {
+	ID                 int64              `json:"id"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	ContentFormat      string             `json:"content_format"`
+	ContentHtml        string             `json:"content_html"`
+	Image              string             `json:"image"`
+	ImageMediaID       pgtype.Int8        `json:"image_media_id"`
+	Url                string             `json:"url"`
+	Status             string             `json:"status"`
+	PublishedAt        pgtype.Timestamptz `json:"published_at"`
+	PublishAt          pgtype.Timestamptz `json:"publish_at"`
+	ViewCount          int64              `json:"view_count"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	Excerpt            string             `json:"excerpt"`
+	AutoExcerpt        string             `json:"auto_excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	AuthorID           int64              `json:"author_id"`
+	AuthorCode         string             `json:"author_code"`
+	AuthorFirstName    string             `json:"author_first_name"`
+	AuthorLastName     string             `json:"author_last_name"`
+	UpdatedBy          pgtype.Int8        `json:"updated_by"`
+	EditorCode         pgtype.Text        `json:"editor_code"`
+	EditorFirstName    pgtype.Text        `json:"editor_first_name"`
+	EditorLastName     pgtype.Text        `json:"editor_last_name"`
+	Version            int32              `json:"version"`
+}
+
+func (q *Queries) GetBlogById(ctx context.Context, id int64) (GetBlogByIdRow, error) {
+	row := q.db.QueryRow(ctx, getBlogById, id)
+	var i GetBlogByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.ContentFormat,
+		&i.ContentHtml,
+		&i.Image,
+		&i.ImageMediaID,
+		&i.Url,
+		&i.Status,
+		&i.PublishedAt,
+		&i.PublishAt,
+		&i.ViewCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Excerpt,
+		&i.AutoExcerpt,
+		&i.WordCount,
+		&i.ReadingTimeMinutes,
+		&i.AuthorID,
+		&i.AuthorCode,
+		&i.AuthorFirstName,
+		&i.AuthorLastName,
+		&i.UpdatedBy,
+		&i.EditorCode,
+		&i.EditorFirstName,
+		&i.EditorLastName,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getBlogByUrl = `-- name: GetBlogByUrl :one
+SELECT
+b.id, b.title, b.content_html AS content, b.content_format, b.image, b.url, b.status, b.published_at, b.view_count, b.created_at, b.updated_at,
+COALESCE(NULLIF(b.excerpt, ''), b.auto_excerpt)::TEXT AS excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+WHERE b.deleted IS FALSE
+AND b.url = $1
+AND b.status = ANY($2::varchar[])
+LIMIT 1
+`
+
+type GetBlogByUrlParams struct {
+	Url      string   `json:"url"`
+	Statuses []string `json:"statuses"`
+}
+
+type GetBlogByUrlRow struct {
+	ID                 int64              `json:"id"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	ContentFormat      string             `json:"content_format"`
+	Image              string             `json:"image"`
+	Url                string             `json:"url"`
+	Status             string             `json:"status"`
+	PublishedAt        pgtype.Timestamptz `json:"published_at"`
+	ViewCount          int64              `json:"view_count"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	Excerpt            string             `json:"excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	AuthorID           int64              `json:"author_id"`
+	AuthorCode         string             `json:"author_code"`
+	AuthorFirstName    string             `json:"author_first_name"`
+	AuthorLastName     string             `json:"author_last_name"`
+}
+
+func (q *Queries) GetBlogByUrl(ctx context.Context, arg GetBlogByUrlParams) (GetBlogByUrlRow, error) {
+	row := q.db.QueryRow(ctx, getBlogByUrl, arg.Url, arg.Statuses)
+	var i GetBlogByUrlRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Content,
+		&i.ContentFormat,
+		&i.Image,
+		&i.Url,
+		&i.Status,
+		&i.PublishedAt,
+		&i.ViewCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Excerpt,
+		&i.WordCount,
+		&i.ReadingTimeMinutes,
+		&i.AuthorID,
+		&i.AuthorCode,
+		&i.AuthorFirstName,
+		&i.AuthorLastName,
+	)
+	return i, err
+}
+
+const getBlogPendingSearchIndex = `-- name: GetBlogPendingSearchIndex :many
+SELECT id, title, content, content_format
+FROM blog
+WHERE search_version <> $1::int
+ORDER BY id
+LIMIT $2
+`
+
+type GetBlogPendingSearchIndexParams struct {
+	SearchVersion int32 `json:"search_version"`
+	LimitRows     int32 `json:"limit_rows"`
+}
+
+type GetBlogPendingSearchIndexRow struct {
+	ID            int64  `json:"id"`
+	Title         string `json:"title"`
+	Content       string `json:"content"`
+	ContentFormat string `json:"content_format"`
+}
+
+// Search versions are fingerprints rather than counters, any version but the current one is stale
+func (q *Queries) GetBlogPendingSearchIndex(ctx context.Context, arg GetBlogPendingSearchIndexParams) ([]GetBlogPendingSearchIndexRow, error) {
+	rows, err := q.db.Query(ctx, getBlogPendingSearchIndex, arg.SearchVersion, arg.LimitRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetBlogPendingSearchIndexRow{}
+	for rows.Next() {
+		var i GetBlogPendingSearchIndexRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Content,
+			&i.ContentFormat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBlogTagFacet = `-- name: GetBlogTagFacet :many
+SELECT
+ft.id, ft.name, COUNT(DISTINCT b.id) AS count
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+INNER JOIN blog_tag fbt ON b.id = fbt.blog_id AND fbt.deleted IS FALSE
+INNER JOIN tag ft ON fbt.tag_id = ft.id AND ft.deleted IS FALSE
+WHERE b.deleted IS FALSE
+AND ($1::text = '' OR b.search_vector @@ to_tsquery('simple', $1::text))
+AND ($2::text = '' OR $2::text <% LOWER(b.title))
+AND b.status = ANY($3::varchar[])
+AND ($4::varchar = '' OR u.code = $4::varchar)
+AND (cardinality($5::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY($5::varchar[])
+) >= CASE WHEN $6::bool THEN cardinality($5::varchar[]) ELSE 1 END)
+AND ($7::timestamptz IS NULL OR b.created_at >= $7::timestamptz)
+AND ($8::timestamptz IS NULL OR b.created_at <= $8::timestamptz)
+AND ($9::timestamptz IS NULL OR b.published_at >= $9::timestamptz)
+AND ($10::timestamptz IS NULL OR b.published_at <= $10::timestamptz)
+GROUP BY ft.id, ft.name
+ORDER BY count DESC, ft.name ASC
+LIMIT $11
+`
+
+type GetBlogTagFacetParams struct {
+	Query         string             `json:"query"`
+	Fuzzy         string             `json:"fuzzy"`
+	Statuses      []string           `json:"statuses"`
+	AuthorCode    string             `json:"author_code"`
+	Tags          []string           `json:"tags"`
+	MatchAllTags  bool               `json:"match_all_tags"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	PublishedFrom pgtype.Timestamptz `json:"published_from"`
+	PublishedTo   pgtype.Timestamptz `json:"published_to"`
+	LimitRows     int32              `json:"limit_rows"`
+}
+
+type GetBlogTagFacetRow struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+func (q *Queries) GetBlogTagFacet(ctx context.Context, arg GetBlogTagFacetParams) ([]GetBlogTagFacetRow, error) {
+	rows, err := q.db.Query(ctx, getBlogTagFacet,
+		arg.Query,
+		arg.Fuzzy,
+		arg.Statuses,
+		arg.AuthorCode,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.LimitRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetBlogTagFacetRow{}
+	for rows.Next() {
+		var i GetBlogTagFacetRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedBlogById = `-- name: GetDeletedBlogById :one
+SELECT id, title, url, deleted_at
 FROM blog
+WHERE deleted IS TRUE
+AND id = $1
+LIMIT 1
+`
+
+type GetDeletedBlogByIdRow struct {
+	ID        int64              `json:"id"`
+	Title     string             `json:"title"`
+	Url       string             `json:"url"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) GetDeletedBlogById(ctx context.Context, id int64) (GetDeletedBlogByIdRow, error) {
+	row := q.db.QueryRow(ctx, getDeletedBlogById, id)
+	var i GetDeletedBlogByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Url,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getSearchSuggestion = `-- name: GetSearchSuggestion :one
+SELECT term::text AS term
+FROM (
+    SELECT title AS term, word_similarity($1::text, LOWER(title)) AS score
+    FROM blog
+    WHERE deleted IS FALSE
+    AND status = ANY($2::varchar[])
+    AND $1::text <% LOWER(title)
+    UNION ALL
+    SELECT name AS term, word_similarity($1::text, LOWER(name)) AS score
+    FROM tag
+    WHERE deleted IS FALSE
+    AND $1::text <% LOWER(name)
+) suggestion
+ORDER BY score DESC
+LIMIT 1
+`
+
+type GetSearchSuggestionParams struct {
+	Query    string   `json:"query"`
+	Statuses []string `json:"statuses"`
+}
+
+func (q *Queries) GetSearchSuggestion(ctx context.Context, arg GetSearchSuggestionParams) (string, error) {
+	row := q.db.QueryRow(ctx, getSearchSuggestion, arg.Query, arg.Statuses)
+	var term string
+	err := row.Scan(&term)
+	return term, err
+}
+
+const incrementBlogViewCount = `-- name: IncrementBlogViewCount :exec
+UPDATE blog
+SET view_count = view_count + 1
 WHERE deleted IS FALSE
-AND LOWER(title) LIKE '%' || LOWER($1) || '%'
-ORDER BY created_at DESC
-OFFSET $2
-LIMIT $3
+AND id = $1
 `
 
-type GetAllBlogParams struct {
-	Lower  string `json:"lower"`
-	Offset int32  `json:"offset"`
-	Limit  int32  `json:"limit"`
+func (q *Queries) IncrementBlogViewCount(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, incrementBlogViewCount, id)
+	return err
 }
 
-type GetAllBlogRow struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Image     string    `json:"image"`
-	Url       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+const listBlog = `-- name: ListBlog :many
+SELECT
+b.id, b.title, CASE WHEN $1::bool THEN b.content_html ELSE '' END::TEXT AS content, b.image, b.url, b.status, b.published_at, b.view_count, b.created_at, b.updated_at,
+COALESCE(NULLIF(b.excerpt, ''), b.auto_excerpt)::TEXT AS excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name,
+CASE WHEN $2::text <> '' THEN word_similarity($2::text, LOWER(b.title))
+    WHEN $3::text = '' THEN 0
+    ELSE ts_rank_cd(b.search_vector, to_tsquery('simple', $3::text))
+END::REAL AS rank,
+CASE WHEN $3::text = '' THEN ''
+    ELSE ts_headline('simple', regexp_replace(b.content_html, '<[^>]*>', ' ', 'g'), to_tsquery('simple', $3::text), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')
+END::TEXT AS snippet
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+WHERE b.deleted IS FALSE
+AND ($3::text = '' OR b.search_vector @@ to_tsquery('simple', $3::text))
+AND ($2::text = '' OR $2::text <% LOWER(b.title))
+AND b.status = ANY($4::varchar[])
+AND ($5::varchar = '' OR u.code = $5::varchar)
+AND (cardinality($6::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY($6::varchar[])
+) >= CASE WHEN $7::bool THEN cardinality($6::varchar[]) ELSE 1 END)
+AND ($8::timestamptz IS NULL OR b.created_at >= $8::timestamptz)
+AND ($9::timestamptz IS NULL OR b.created_at <= $9::timestamptz)
+AND ($10::timestamptz IS NULL OR b.published_at >= $10::timestamptz)
+AND ($11::timestamptz IS NULL OR b.published_at <= $11::timestamptz)
+ORDER BY
+CASE WHEN $12::text = 'relevance' THEN
+    CASE WHEN $2::text <> '' THEN word_similarity($2::text, LOWER(b.title))
+        WHEN $3::text <> '' THEN ts_rank_cd(b.search_vector, to_tsquery('simple', $3::text))
+    END
+END DESC NULLS LAST,
+CASE WHEN $12::text = 'most_viewed' THEN b.view_count END DESC NULLS LAST,
+CASE WHEN $12::text = 'title' THEN LOWER(b.title) END ASC NULLS LAST,
+CASE WHEN $12::text = 'oldest' THEN b.created_at END ASC NULLS LAST,
+b.created_at DESC,
+b.id DESC
+OFFSET $13
+LIMIT $14
+`
+
+type ListBlogParams struct {
+	WithContent   bool               `json:"with_content"`
+	Fuzzy         string             `json:"fuzzy"`
+	Query         string             `json:"query"`
+	Statuses      []string           `json:"statuses"`
+	AuthorCode    string             `json:"author_code"`
+	Tags          []string           `json:"tags"`
+	MatchAllTags  bool               `json:"match_all_tags"`
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	PublishedFrom pgtype.Timestamptz `json:"published_from"`
+	PublishedTo   pgtype.Timestamptz `json:"published_to"`
+	Sort          string             `json:"sort"`
+	OffsetRows    int32              `json:"offset_rows"`
+	LimitRows     int32              `json:"limit_rows"`
+}
+
+type ListBlogRow struct {
+	ID                 int64              `json:"id"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	Image              string             `json:"image"`
+	Url                string             `json:"url"`
+	Status             string             `json:"status"`
+	PublishedAt        pgtype.Timestamptz `json:"published_at"`
+	ViewCount          int64              `json:"view_count"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	Excerpt            string             `json:"excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	AuthorID           int64              `json:"author_id"`
+	AuthorCode         string             `json:"author_code"`
+	AuthorFirstName    string             `json:"author_first_name"`
+	AuthorLastName     string             `json:"author_last_name"`
+	Rank               float32            `json:"rank"`
+	Snippet            string             `json:"snippet"`
 }
 
-func (q *Queries) GetAllBlog(ctx context.Context, arg GetAllBlogParams) ([]GetAllBlogRow, error) {
-	rows, err := q.db.Query(ctx, getAllBlog, arg.Lower, arg.Offset, arg.Limit)
+// The snippet is cut from the text of the post without its markup, search.HighlightSnippet escapes it.
+// The body is left empty unless with_content is set, listings send the excerpt instead.
+func (q *Queries) ListBlog(ctx context.Context, arg ListBlogParams) ([]ListBlogRow, error) {
+	rows, err := q.db.Query(ctx, listBlog,
+		arg.WithContent,
+		arg.Fuzzy,
+		arg.Query,
+		arg.Statuses,
+		arg.AuthorCode,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.Sort,
+		arg.OffsetRows,
+		arg.LimitRows,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetAllBlogRow{}
+	items := []ListBlogRow{}
 	for rows.Next() {
-		var i GetAllBlogRow
+		var i ListBlogRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
 			&i.Content,
 			&i.Image,
 			&i.Url,
+			&i.Status,
+			&i.PublishedAt,
+			&i.ViewCount,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Excerpt,
+			&i.WordCount,
+			&i.ReadingTimeMinutes,
+			&i.AuthorID,
+			&i.AuthorCode,
+			&i.AuthorFirstName,
+			&i.AuthorLastName,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -151,59 +761,130 @@ func (q *Queries) GetAllBlog(ctx context.Context, arg GetAllBlogParams) ([]GetAl
 	return items, nil
 }
 
-const getAllBlogWithTag = `-- name: GetAllBlogWithTag :many
-SELECT DISTINCT
-b.id, b.title, b.content, b.image, b.url, b.created_at, b.updated_at
+const listBlogByCursor = `-- name: ListBlogByCursor :many
+SELECT
+b.id, b.title, CASE WHEN $1::bool THEN b.content_html ELSE '' END::TEXT AS content, b.image, b.url, b.status, b.published_at, b.view_count, b.created_at, b.updated_at,
+COALESCE(NULLIF(b.excerpt, ''), b.auto_excerpt)::TEXT AS excerpt, b.word_count, b.reading_time_minutes,
+b.author_id, u.code AS author_code, u.first_name AS author_first_name, u.last_name AS author_last_name,
+CASE WHEN $2::text = '' THEN 0
+    ELSE ts_rank_cd(b.search_vector, to_tsquery('simple', $2::text))
+END::REAL AS rank,
+CASE WHEN $2::text = '' THEN ''
+    ELSE ts_headline('simple', regexp_replace(b.content_html, '<[^>]*>', ' ', 'g'), to_tsquery('simple', $2::text), 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')
+END::TEXT AS snippet
 FROM blog b
-LEFT JOIN blog_tag bt ON b.id = bt.blog_id AND bt.deleted IS FALSE
-LEFT JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+INNER JOIN users u ON b.author_id = u.id
 WHERE b.deleted IS FALSE
-AND LOWER(b.title) LIKE '%' || LOWER($1) || '%'
-AND LOWER(t.name) = LOWER($2)
-ORDER BY b.created_at DESC
-OFFSET $3
-LIMIT $4
+AND ($2::text = '' OR b.search_vector @@ to_tsquery('simple', $2::text))
+AND ($3::text = '' OR $3::text <% LOWER(b.title))
+AND b.status = ANY($4::varchar[])
+AND ($5::varchar = '' OR u.code = $5::varchar)
+AND (cardinality($6::varchar[]) = 0 OR (
+    SELECT COUNT(DISTINCT LOWER(t.name))
+    FROM blog_tag bt
+    INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+    WHERE bt.deleted IS FALSE
+    AND bt.blog_id = b.id
+    AND LOWER(t.name) = ANY($6::varchar[])
+) >= CASE WHEN $7::bool THEN cardinality($6::varchar[]) ELSE 1 END)
+AND ($8::timestamptz IS NULL OR b.created_at >= $8::timestamptz)
+AND ($9::timestamptz IS NULL OR b.created_at <= $9::timestamptz)
+AND ($10::timestamptz IS NULL OR b.published_at >= $10::timestamptz)
+AND ($11::timestamptz IS NULL OR b.published_at <= $11::timestamptz)
+AND (
+    $12::timestamptz IS NULL
+    OR (COALESCE(b.published_at, b.created_at), b.id) < ($12::timestamptz, $13::bigint)
+)
+ORDER BY COALESCE(b.published_at, b.created_at) DESC, b.id DESC
+LIMIT $14
 `
 
-type GetAllBlogWithTagParams struct {
-	Lower   string `json:"lower"`
-	Lower_2 string `json:"lower_2"`
-	Offset  int32  `json:"offset"`
-	Limit   int32  `json:"limit"`
+type ListBlogByCursorParams struct {
+	WithContent    bool               `json:"with_content"`
+	Query          string             `json:"query"`
+	Fuzzy          string             `json:"fuzzy"`
+	Statuses       []string           `json:"statuses"`
+	AuthorCode     string             `json:"author_code"`
+	Tags           []string           `json:"tags"`
+	MatchAllTags   bool               `json:"match_all_tags"`
+	CreatedFrom    pgtype.Timestamptz `json:"created_from"`
+	CreatedTo      pgtype.Timestamptz `json:"created_to"`
+	PublishedFrom  pgtype.Timestamptz `json:"published_from"`
+	PublishedTo    pgtype.Timestamptz `json:"published_to"`
+	CursorListedAt pgtype.Timestamptz `json:"cursor_listed_at"`
+	CursorID       int64              `json:"cursor_id"`
+	LimitRows      int32              `json:"limit_rows"`
 }
 
-type GetAllBlogWithTagRow struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Image     string    `json:"image"`
-	Url       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
-
-func (q *Queries) GetAllBlogWithTag(ctx context.Context, arg GetAllBlogWithTagParams) ([]GetAllBlogWithTagRow, error) {
-	rows, err := q.db.Query(ctx, getAllBlogWithTag,
-		arg.Lower,
-		arg.Lower_2,
-		arg.Offset,
-		arg.Limit,
+type ListBlogByCursorRow struct {
+	ID                 int64              `json:"id"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	Image              string             `json:"image"`
+	Url                string             `json:"url"`
+	Status             string             `json:"status"`
+	PublishedAt        pgtype.Timestamptz `json:"published_at"`
+	ViewCount          int64              `json:"view_count"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	Excerpt            string             `json:"excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	AuthorID           int64              `json:"author_id"`
+	AuthorCode         string             `json:"author_code"`
+	AuthorFirstName    string             `json:"author_first_name"`
+	AuthorLastName     string             `json:"author_last_name"`
+	Rank               float32            `json:"rank"`
+	Snippet            string             `json:"snippet"`
+}
+
+// The snippet is cut from the text of the post without its markup, search.HighlightSnippet escapes it.
+// The body is left empty unless with_content is set, listings send the excerpt instead.
+// Posts are listed by publish date, posts that were never published by their creation date.
+func (q *Queries) ListBlogByCursor(ctx context.Context, arg ListBlogByCursorParams) ([]ListBlogByCursorRow, error) {
+	rows, err := q.db.Query(ctx, listBlogByCursor,
+		arg.WithContent,
+		arg.Query,
+		arg.Fuzzy,
+		arg.Statuses,
+		arg.AuthorCode,
+		arg.Tags,
+		arg.MatchAllTags,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.CursorListedAt,
+		arg.CursorID,
+		arg.LimitRows,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetAllBlogWithTagRow{}
+	items := []ListBlogByCursorRow{}
 	for rows.Next() {
-		var i GetAllBlogWithTagRow
+		var i ListBlogByCursorRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
 			&i.Content,
 			&i.Image,
 			&i.Url,
+			&i.Status,
+			&i.PublishedAt,
+			&i.ViewCount,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Excerpt,
+			&i.WordCount,
+			&i.ReadingTimeMinutes,
+			&i.AuthorID,
+			&i.AuthorCode,
+			&i.AuthorFirstName,
+			&i.AuthorLastName,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -215,100 +896,398 @@ func (q *Queries) GetAllBlogWithTag(ctx context.Context, arg GetAllBlogWithTagPa
 	return items, nil
 }
 
-const getBlogById = `-- name: GetBlogById :one
+const listDeletedBlog = `-- name: ListDeletedBlog :many
 SELECT
-id, title, content, image, url, created_at, updated_at
-FROM blog
+b.id, b.title, b.url, b.status, b.author_id,
+u.first_name AS author_first_name, u.last_name AS author_last_name,
+b.deleted_at
+FROM blog b
+INNER JOIN users u ON b.author_id = u.id
+WHERE b.deleted IS TRUE
+ORDER BY b.deleted_at DESC NULLS LAST, b.id DESC
+OFFSET $1
+LIMIT $2
+`
+
+type ListDeletedBlogParams struct {
+	OffsetRows int32 `json:"offset_rows"`
+	LimitRows  int32 `json:"limit_rows"`
+}
+
+type ListDeletedBlogRow struct {
+	ID              int64              `json:"id"`
+	Title           string             `json:"title"`
+	Url             string             `json:"url"`
+	Status          string             `json:"status"`
+	AuthorID        int64              `json:"author_id"`
+	AuthorFirstName string             `json:"author_first_name"`
+	AuthorLastName  string             `json:"author_last_name"`
+	DeletedAt       pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedBlog(ctx context.Context, arg ListDeletedBlogParams) ([]ListDeletedBlogRow, error) {
+	rows, err := q.db.Query(ctx, listDeletedBlog, arg.OffsetRows, arg.LimitRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedBlogRow{}
+	for rows.Next() {
+		var i ListDeletedBlogRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Status,
+			&i.AuthorID,
+			&i.AuthorFirstName,
+			&i.AuthorLastName,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const publishScheduledBlog = `-- name: PublishScheduledBlog :many
+UPDATE blog
+SET status = 'published',
+published_at = publish_at,
+publish_at = NULL,
+version = version + 1,
+updated_at = NOW()::TIMESTAMPTZ
 WHERE deleted IS FALSE
-AND id = $1
-LIMIT 1
+AND status = 'scheduled'
+AND publish_at <= $1::TIMESTAMPTZ
+RETURNING id
 `
 
-type GetBlogByIdRow struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Image     string    `json:"image"`
-	Url       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+func (q *Queries) PublishScheduledBlog(ctx context.Context, dueAt time.Time) ([]int64, error) {
+	rows, err := q.db.Query(ctx, publishScheduledBlog, dueAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) GetBlogById(ctx context.Context, id int64) (GetBlogByIdRow, error) {
-	row := q.db.QueryRow(ctx, getBlogById, id)
-	var i GetBlogByIdRow
+const purgeBlog = `-- name: PurgeBlog :execrows
+WITH target AS (
+    SELECT id
+    FROM blog
+    WHERE deleted IS TRUE
+    AND ($1::bigint IS NULL OR id = $1)
+    AND ($2::timestamptz IS NULL OR deleted_at < $2)
+), purged_tags AS (
+    DELETE FROM blog_tag WHERE blog_id IN (SELECT id FROM target)
+), purged_reviews AS (
+    DELETE FROM blog_review WHERE blog_id IN (SELECT id FROM target)
+), purged_revisions AS (
+    DELETE FROM blog_revision WHERE blog_id IN (SELECT id FROM target)
+), purged_redirects AS (
+    DELETE FROM blog_redirect WHERE blog_id IN (SELECT id FROM target)
+), purged_locks AS (
+    DELETE FROM blog_lock WHERE blog_id IN (SELECT id FROM target)
+), purged_media AS (
+    DELETE FROM blog_media WHERE blog_id IN (SELECT id FROM target)
+)
+DELETE FROM blog
+WHERE id IN (SELECT id FROM target)
+`
+
+type PurgeBlogParams struct {
+	ID            pgtype.Int8        `json:"id"`
+	DeletedBefore pgtype.Timestamptz `json:"deleted_before"`
+}
+
+// Permanently removes blogs in the trash, by id or deleted before a time, with everything that belongs to them
+func (q *Queries) PurgeBlog(ctx context.Context, arg PurgeBlogParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeBlog, arg.ID, arg.DeletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreBlog = `-- name: RestoreBlog :one
+WITH target AS (
+    SELECT db.id, db.deleted_at
+    FROM blog db
+    WHERE db.deleted IS TRUE
+    AND db.id = $2
+), relinked_tags AS (
+    UPDATE blog_tag bt
+    SET deleted = FALSE,
+    deleted_at = NULL,
+    updated_at = NOW()::TIMESTAMP
+    FROM target
+    WHERE bt.blog_id = target.id
+    AND bt.deleted_at = target.deleted_at
+)
+UPDATE blog b
+SET deleted = FALSE,
+deleted_at = NULL,
+url = $1,
+updated_at = NOW()::TIMESTAMPTZ
+FROM target
+WHERE b.id = target.id
+RETURNING b.id, b.title, b.url, b.status
+`
+
+type RestoreBlogParams struct {
+	Url string `json:"url"`
+	ID  int64  `json:"id"`
+}
+
+type RestoreBlogRow struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Url    string `json:"url"`
+	Status string `json:"status"`
+}
+
+// Tags removed together with the blog come back with it, tags removed earlier stay removed
+func (q *Queries) RestoreBlog(ctx context.Context, arg RestoreBlogParams) (RestoreBlogRow, error) {
+	row := q.db.QueryRow(ctx, restoreBlog, arg.Url, arg.ID)
+	var i RestoreBlogRow
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
-		&i.Content,
-		&i.Image,
 		&i.Url,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
-const getBlogByUrl = `-- name: GetBlogByUrl :one
+const suggestBlog = `-- name: SuggestBlog :many
 SELECT
-id, title, content, image, url, created_at, updated_at
+id, title, url
 FROM blog
 WHERE deleted IS FALSE
-AND url = $1
-LIMIT 1
+AND status = ANY($1::varchar[])
+AND (
+    LOWER(title) LIKE '%' || $2::text || '%' ESCAPE '\'
+    OR $3::text <% LOWER(title)
+)
+ORDER BY LOWER(title) LIKE $2::text || '%' ESCAPE '\' DESC,
+word_similarity($3::text, LOWER(title)) DESC,
+created_at DESC
+LIMIT $4
 `
 
-type GetBlogByUrlRow struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Image     string    `json:"image"`
-	Url       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+type SuggestBlogParams struct {
+	Statuses     []string `json:"statuses"`
+	QueryPattern string   `json:"query_pattern"`
+	Query        string   `json:"query"`
+	LimitRows    int32    `json:"limit_rows"`
 }
 
-func (q *Queries) GetBlogByUrl(ctx context.Context, url string) (GetBlogByUrlRow, error) {
-	row := q.db.QueryRow(ctx, getBlogByUrl, url)
-	var i GetBlogByUrlRow
-	err := row.Scan(
-		&i.ID,
-		&i.Title,
-		&i.Content,
-		&i.Image,
-		&i.Url,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+type SuggestBlogRow struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Url   string `json:"url"`
+}
+
+func (q *Queries) SuggestBlog(ctx context.Context, arg SuggestBlogParams) ([]SuggestBlogRow, error) {
+	rows, err := q.db.Query(ctx, suggestBlog,
+		arg.Statuses,
+		arg.QueryPattern,
+		arg.Query,
+		arg.LimitRows,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SuggestBlogRow{}
+	for rows.Next() {
+		var i SuggestBlogRow
+		if err := rows.Scan(&i.ID, &i.Title, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const updateBlog = `-- name: UpdateBlog :exec
+const updateBlog = `-- name: UpdateBlog :execrows
 UPDATE blog
-SET title = $2,
-content = $3,
-image = $4,
-url = $5,
+SET title = $1,
+content = $2,
+content_format = $3,
+content_html = $4,
+image = $5,
+image_media_id = $6,
+url = $7,
+status = COALESCE($8, status),
+published_at = CASE
+    WHEN COALESCE($8, status) = 'published' THEN COALESCE(published_at, NOW()::TIMESTAMPTZ)
+    ELSE published_at
+END,
+publish_at = CASE
+    WHEN COALESCE($8, status) = 'published' THEN NULL
+    ELSE COALESCE($9, publish_at)
+END,
+updated_by = $10,
+excerpt = $11,
+auto_excerpt = $12,
+word_count = $13,
+reading_time_minutes = $14,
+search_vector = setweight(to_tsvector('simple', $15::text), 'A') ||
+    setweight(to_tsvector('simple', $16::text), 'B'),
+search_version = $17,
+version = version + 1,
 updated_at = NOW()::TIMESTAMPTZ
 WHERE deleted IS FALSE
-AND id = $1
+AND id = $18
+AND version = $19
 `
 
 type UpdateBlogParams struct {
-	ID      int64  `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
-	Image   string `json:"image"`
-	Url     string `json:"url"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	ContentFormat      string             `json:"content_format"`
+	ContentHtml        string             `json:"content_html"`
+	Image              string             `json:"image"`
+	ImageMediaID       pgtype.Int8        `json:"image_media_id"`
+	Url                string             `json:"url"`
+	Status             pgtype.Text        `json:"status"`
+	PublishAt          pgtype.Timestamptz `json:"publish_at"`
+	UpdatedBy          pgtype.Int8        `json:"updated_by"`
+	Excerpt            string             `json:"excerpt"`
+	AutoExcerpt        string             `json:"auto_excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	SearchTitle        string             `json:"search_title"`
+	SearchContent      string             `json:"search_content"`
+	SearchVersion      int32              `json:"search_version"`
+	ID                 int64              `json:"id"`
+	Version            int32              `json:"version"`
 }
 
-func (q *Queries) UpdateBlog(ctx context.Context, arg UpdateBlogParams) error {
-	_, err := q.db.Exec(ctx, updateBlog,
-		arg.ID,
+// No row is updated when the blog was saved by someone else since the caller loaded version
+func (q *Queries) UpdateBlog(ctx context.Context, arg UpdateBlogParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateBlog,
 		arg.Title,
 		arg.Content,
+		arg.ContentFormat,
+		arg.ContentHtml,
 		arg.Image,
+		arg.ImageMediaID,
 		arg.Url,
+		arg.Status,
+		arg.PublishAt,
+		arg.UpdatedBy,
+		arg.Excerpt,
+		arg.AutoExcerpt,
+		arg.WordCount,
+		arg.ReadingTimeMinutes,
+		arg.SearchTitle,
+		arg.SearchContent,
+		arg.SearchVersion,
+		arg.ID,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateBlogSearchIndex = `-- name: UpdateBlogSearchIndex :exec
+UPDATE blog
+SET content_html = $1,
+auto_excerpt = $2,
+word_count = $3,
+reading_time_minutes = $4,
+search_vector = setweight(to_tsvector('simple', $5::text), 'A') ||
+    setweight(to_tsvector('simple', $6::text), 'B'),
+search_version = $7::int
+WHERE id = $8
+AND search_version <> $7::int
+`
+
+type UpdateBlogSearchIndexParams struct {
+	ContentHtml        string `json:"content_html"`
+	AutoExcerpt        string `json:"auto_excerpt"`
+	WordCount          int32  `json:"word_count"`
+	ReadingTimeMinutes int32  `json:"reading_time_minutes"`
+	SearchTitle        string `json:"search_title"`
+	SearchContent      string `json:"search_content"`
+	SearchVersion      int32  `json:"search_version"`
+	ID                 int64  `json:"id"`
+}
+
+func (q *Queries) UpdateBlogSearchIndex(ctx context.Context, arg UpdateBlogSearchIndexParams) error {
+	_, err := q.db.Exec(ctx, updateBlogSearchIndex,
+		arg.ContentHtml,
+		arg.AutoExcerpt,
+		arg.WordCount,
+		arg.ReadingTimeMinutes,
+		arg.SearchTitle,
+		arg.SearchContent,
+		arg.SearchVersion,
+		arg.ID,
 	)
 	return err
 }
+
+const updateBlogStatus = `-- name: UpdateBlogStatus :one
+UPDATE blog
+SET status = $1,
+published_at = CASE
+    WHEN $1::varchar = 'published' THEN COALESCE(published_at, NOW()::TIMESTAMPTZ)
+    ELSE published_at
+END,
+publish_at = CASE
+    WHEN $1::varchar = 'published' THEN NULL
+    ELSE COALESCE($2, publish_at)
+END,
+updated_by = $3,
+version = version + 1,
+updated_at = NOW()::TIMESTAMPTZ
+WHERE deleted IS FALSE
+AND id = $4
+AND status = $5
+RETURNING id
+`
+
+type UpdateBlogStatusParams struct {
+	Status        string             `json:"status"`
+	PublishAt     pgtype.Timestamptz `json:"publish_at"`
+	UpdatedBy     pgtype.Int8        `json:"updated_by"`
+	ID            int64              `json:"id"`
+	CurrentStatus string             `json:"current_status"`
+}
+
+func (q *Queries) UpdateBlogStatus(ctx context.Context, arg UpdateBlogStatusParams) (int64, error) {
+	row := q.db.QueryRow(ctx, updateBlogStatus,
+		arg.Status,
+		arg.PublishAt,
+		arg.UpdatedBy,
+		arg.ID,
+		arg.CurrentStatus,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
@@ -0,0 +1,110 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.21.0
+// source: blog_lock.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const acquireBlogLock = `-- name: AcquireBlogLock :one
+INSERT INTO blog_lock (blog_id, user_id, acquired_at, expires_at)
+VALUES ($1, $2, NOW(), NOW() + $3::int * INTERVAL '1 second')
+ON CONFLICT (blog_id) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+acquired_at = CASE
+    WHEN blog_lock.user_id = EXCLUDED.user_id AND blog_lock.expires_at > NOW() THEN blog_lock.acquired_at
+    ELSE EXCLUDED.acquired_at
+END,
+expires_at = EXCLUDED.expires_at
+WHERE blog_lock.user_id = EXCLUDED.user_id
+OR blog_lock.expires_at <= NOW()
+OR $4::bool
+RETURNING blog_id, user_id, acquired_at, expires_at
+`
+
+type AcquireBlogLockParams struct {
+	BlogID     int64 `json:"blog_id"`
+	UserID     int64 `json:"user_id"`
+	TtlSeconds int32 `json:"ttl_seconds"`
+	Force      bool  `json:"force"`
+}
+
+// Takes the lock when it is free, expired or already held by the user, a heartbeat renews it the same way.
+// With force the lock is taken over from whoever holds it. No row is returned when someone else holds it.
+func (q *Queries) AcquireBlogLock(ctx context.Context, arg AcquireBlogLockParams) (BlogLock, error) {
+	row := q.db.QueryRow(ctx, acquireBlogLock,
+		arg.BlogID,
+		arg.UserID,
+		arg.TtlSeconds,
+		arg.Force,
+	)
+	var i BlogLock
+	err := row.Scan(
+		&i.BlogID,
+		&i.UserID,
+		&i.AcquiredAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getBlogLock = `-- name: GetBlogLock :one
+SELECT bl.blog_id, bl.user_id, u.code AS user_code, u.first_name AS user_first_name, u.last_name AS user_last_name,
+bl.acquired_at, bl.expires_at
+FROM blog_lock bl
+INNER JOIN users u ON bl.user_id = u.id
+WHERE bl.blog_id = $1
+AND bl.expires_at > NOW()
+LIMIT 1
+`
+
+type GetBlogLockRow struct {
+	BlogID        int64     `json:"blog_id"`
+	UserID        int64     `json:"user_id"`
+	UserCode      string    `json:"user_code"`
+	UserFirstName string    `json:"user_first_name"`
+	UserLastName  string    `json:"user_last_name"`
+	AcquiredAt    time.Time `json:"acquired_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// An expired lock is treated as no lock at all
+func (q *Queries) GetBlogLock(ctx context.Context, blogID int64) (GetBlogLockRow, error) {
+	row := q.db.QueryRow(ctx, getBlogLock, blogID)
+	var i GetBlogLockRow
+	err := row.Scan(
+		&i.BlogID,
+		&i.UserID,
+		&i.UserCode,
+		&i.UserFirstName,
+		&i.UserLastName,
+		&i.AcquiredAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const releaseBlogLock = `-- name: ReleaseBlogLock :execrows
+DELETE FROM blog_lock
+WHERE blog_id = $1
+AND (user_id = $2 OR $3::bool)
+`
+
+type ReleaseBlogLockParams struct {
+	BlogID int64 `json:"blog_id"`
+	UserID int64 `json:"user_id"`
+	Force  bool  `json:"force"`
+}
+
+// Releases the lock of the user, with force it is broken whoever holds it
+func (q *Queries) ReleaseBlogLock(ctx context.Context, arg ReleaseBlogLockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseBlogLock, arg.BlogID, arg.UserID, arg.Force)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
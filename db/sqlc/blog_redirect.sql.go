@@ -0,0 +1,66 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.21.0
+// source: blog_redirect.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteBlogRedirectByOldUrl = `-- name: DeleteBlogRedirectByOldUrl :exec
+DELETE FROM blog_redirect
+WHERE old_url = $1
+`
+
+func (q *Queries) DeleteBlogRedirectByOldUrl(ctx context.Context, oldUrl string) error {
+	_, err := q.db.Exec(ctx, deleteBlogRedirectByOldUrl, oldUrl)
+	return err
+}
+
+const getBlogRedirect = `-- name: GetBlogRedirect :one
+SELECT b.id, b.url
+FROM blog_redirect br
+INNER JOIN blog b ON br.blog_id = b.id
+WHERE br.old_url = $1
+AND b.deleted IS FALSE
+AND b.status = ANY($2::varchar[])
+LIMIT 1
+`
+
+type GetBlogRedirectParams struct {
+	OldUrl   string   `json:"old_url"`
+	Statuses []string `json:"statuses"`
+}
+
+type GetBlogRedirectRow struct {
+	ID  int64  `json:"id"`
+	Url string `json:"url"`
+}
+
+func (q *Queries) GetBlogRedirect(ctx context.Context, arg GetBlogRedirectParams) (GetBlogRedirectRow, error) {
+	row := q.db.QueryRow(ctx, getBlogRedirect, arg.OldUrl, arg.Statuses)
+	var i GetBlogRedirectRow
+	err := row.Scan(&i.ID, &i.Url)
+	return i, err
+}
+
+const upsertBlogRedirect = `-- name: UpsertBlogRedirect :exec
+INSERT INTO blog_redirect
+(old_url, blog_id, created_at)
+VALUES ($1, $2, NOW()::TIMESTAMPTZ)
+ON CONFLICT (old_url) DO UPDATE
+SET blog_id = EXCLUDED.blog_id,
+created_at = EXCLUDED.created_at
+`
+
+type UpsertBlogRedirectParams struct {
+	OldUrl string `json:"old_url"`
+	BlogID int64  `json:"blog_id"`
+}
+
+func (q *Queries) UpsertBlogRedirect(ctx context.Context, arg UpsertBlogRedirectParams) error {
+	_, err := q.db.Exec(ctx, upsertBlogRedirect, arg.OldUrl, arg.BlogID)
+	return err
+}
@@ -0,0 +1,111 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.21.0
+// source: blog_review.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createBlogReview = `-- name: CreateBlogReview :one
+INSERT INTO blog_review
+(blog_id, blog_revision_id, user_id, action, note, created_at)
+VALUES ($1, $2, $3, $4, $5, NOW()::TIMESTAMPTZ)
+RETURNING id, blog_id, blog_revision_id, user_id, action, note, created_at
+`
+
+type CreateBlogReviewParams struct {
+	BlogID         int64       `json:"blog_id"`
+	BlogRevisionID pgtype.Int8 `json:"blog_revision_id"`
+	UserID         int64       `json:"user_id"`
+	Action         string      `json:"action"`
+	Note           string      `json:"note"`
+}
+
+func (q *Queries) CreateBlogReview(ctx context.Context, arg CreateBlogReviewParams) (BlogReview, error) {
+	row := q.db.QueryRow(ctx, createBlogReview,
+		arg.BlogID,
+		arg.BlogRevisionID,
+		arg.UserID,
+		arg.Action,
+		arg.Note,
+	)
+	var i BlogReview
+	err := row.Scan(
+		&i.ID,
+		&i.BlogID,
+		&i.BlogRevisionID,
+		&i.UserID,
+		&i.Action,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBlogReviewByBlogId = `-- name: GetBlogReviewByBlogId :many
+SELECT
+br.id,
+br.blog_id,
+br.blog_revision_id,
+br.user_id,
+u.code AS user_code,
+u.first_name AS user_first_name,
+u.last_name AS user_last_name,
+br.action,
+br.note,
+br.created_at
+FROM blog_review br
+INNER JOIN users u ON br.user_id = u.id
+WHERE br.blog_id = $1
+ORDER BY br.created_at DESC, br.id DESC
+`
+
+type GetBlogReviewByBlogIdRow struct {
+	ID             int64       `json:"id"`
+	BlogID         int64       `json:"blog_id"`
+	BlogRevisionID pgtype.Int8 `json:"blog_revision_id"`
+	UserID         int64       `json:"user_id"`
+	UserCode       string      `json:"user_code"`
+	UserFirstName  string      `json:"user_first_name"`
+	UserLastName   string      `json:"user_last_name"`
+	Action         string      `json:"action"`
+	Note           string      `json:"note"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+func (q *Queries) GetBlogReviewByBlogId(ctx context.Context, blogID int64) ([]GetBlogReviewByBlogIdRow, error) {
+	rows, err := q.db.Query(ctx, getBlogReviewByBlogId, blogID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetBlogReviewByBlogIdRow{}
+	for rows.Next() {
+		var i GetBlogReviewByBlogIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlogID,
+			&i.BlogRevisionID,
+			&i.UserID,
+			&i.UserCode,
+			&i.UserFirstName,
+			&i.UserLastName,
+			&i.Action,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
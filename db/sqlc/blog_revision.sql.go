@@ -0,0 +1,185 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.21.0
+// source: blog_revision.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countBlogRevisionByBlogId = `-- name: CountBlogRevisionByBlogId :one
+SELECT COUNT(1) AS count
+FROM blog_revision
+WHERE blog_id = $1
+LIMIT 1
+`
+
+func (q *Queries) CountBlogRevisionByBlogId(ctx context.Context, blogID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countBlogRevisionByBlogId, blogID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBlogRevision = `-- name: CreateBlogRevision :one
+INSERT INTO blog_revision
+(blog_id, title, content, image, url, edited_by, content_format, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW()::TIMESTAMPTZ)
+RETURNING id, blog_id, title, content, image, url, edited_by, created_at, content_format
+`
+
+type CreateBlogRevisionParams struct {
+	BlogID        int64       `json:"blog_id"`
+	Title         string      `json:"title"`
+	Content       string      `json:"content"`
+	Image         string      `json:"image"`
+	Url           string      `json:"url"`
+	EditedBy      pgtype.Int8 `json:"edited_by"`
+	ContentFormat string      `json:"content_format"`
+}
+
+func (q *Queries) CreateBlogRevision(ctx context.Context, arg CreateBlogRevisionParams) (BlogRevision, error) {
+	row := q.db.QueryRow(ctx, createBlogRevision,
+		arg.BlogID,
+		arg.Title,
+		arg.Content,
+		arg.Image,
+		arg.Url,
+		arg.EditedBy,
+		arg.ContentFormat,
+	)
+	var i BlogRevision
+	err := row.Scan(
+		&i.ID,
+		&i.BlogID,
+		&i.Title,
+		&i.Content,
+		&i.Image,
+		&i.Url,
+		&i.EditedBy,
+		&i.CreatedAt,
+		&i.ContentFormat,
+	)
+	return i, err
+}
+
+const getBlogRevisionByBlogId = `-- name: GetBlogRevisionByBlogId :many
+SELECT
+br.id,
+br.blog_id,
+br.title,
+br.url,
+br.edited_by,
+u.first_name AS editor_first_name,
+u.last_name AS editor_last_name,
+br.created_at
+FROM blog_revision br
+LEFT JOIN users u ON br.edited_by = u.id
+WHERE br.blog_id = $1
+ORDER BY br.created_at DESC, br.id DESC
+OFFSET $2
+LIMIT $3
+`
+
+type GetBlogRevisionByBlogIdParams struct {
+	BlogID int64 `json:"blog_id"`
+	Offset int32 `json:"offset"`
+	Limit  int32 `json:"limit"`
+}
+
+type GetBlogRevisionByBlogIdRow struct {
+	ID              int64       `json:"id"`
+	BlogID          int64       `json:"blog_id"`
+	Title           string      `json:"title"`
+	Url             string      `json:"url"`
+	EditedBy        pgtype.Int8 `json:"edited_by"`
+	EditorFirstName pgtype.Text `json:"editor_first_name"`
+	EditorLastName  pgtype.Text `json:"editor_last_name"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+func (q *Queries) GetBlogRevisionByBlogId(ctx context.Context, arg GetBlogRevisionByBlogIdParams) ([]GetBlogRevisionByBlogIdRow, error) {
+	rows, err := q.db.Query(ctx, getBlogRevisionByBlogId, arg.BlogID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetBlogRevisionByBlogIdRow{}
+	for rows.Next() {
+		var i GetBlogRevisionByBlogIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlogID,
+			&i.Title,
+			&i.Url,
+			&i.EditedBy,
+			&i.EditorFirstName,
+			&i.EditorLastName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBlogRevisionById = `-- name: GetBlogRevisionById :one
+SELECT
+id, blog_id, title, content, content_format, image, url, edited_by, created_at
+FROM blog_revision
+WHERE id = $1
+LIMIT 1
+`
+
+type GetBlogRevisionByIdRow struct {
+	ID            int64       `json:"id"`
+	BlogID        int64       `json:"blog_id"`
+	Title         string      `json:"title"`
+	Content       string      `json:"content"`
+	ContentFormat string      `json:"content_format"`
+	Image         string      `json:"image"`
+	Url           string      `json:"url"`
+	EditedBy      pgtype.Int8 `json:"edited_by"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+func (q *Queries) GetBlogRevisionById(ctx context.Context, id int64) (GetBlogRevisionByIdRow, error) {
+	row := q.db.QueryRow(ctx, getBlogRevisionById, id)
+	var i GetBlogRevisionByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.BlogID,
+		&i.Title,
+		&i.Content,
+		&i.ContentFormat,
+		&i.Image,
+		&i.Url,
+		&i.EditedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestBlogRevisionIdByBlogId = `-- name: GetLatestBlogRevisionIdByBlogId :one
+SELECT id
+FROM blog_revision
+WHERE blog_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestBlogRevisionIdByBlogId(ctx context.Context, blogID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getLatestBlogRevisionIdByBlogId, blogID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
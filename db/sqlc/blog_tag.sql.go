@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createBlogTag = `-- name: CreateBlogTag :exec
@@ -29,6 +30,7 @@ func (q *Queries) CreateBlogTag(ctx context.Context, arg CreateBlogTagParams) er
 const deleteBlogTag = `-- name: DeleteBlogTag :exec
 UPDATE blog_tag
 SET deleted = True,
+deleted_at = NOW()::TIMESTAMPTZ,
 updated_at = NOW()::TIMESTAMP
 WHERE blog_id = $1
 AND tag_id = $2
@@ -47,12 +49,19 @@ func (q *Queries) DeleteBlogTag(ctx context.Context, arg DeleteBlogTagParams) er
 const deleteBlogTagByBlogId = `-- name: DeleteBlogTagByBlogId :exec
 UPDATE blog_tag
 SET deleted = True,
+deleted_at = $1::TIMESTAMPTZ,
 updated_at = NOW()::TIMESTAMP
-WHERE blog_id = $1
+WHERE blog_id = $2
+AND deleted IS FALSE
 `
 
-func (q *Queries) DeleteBlogTagByBlogId(ctx context.Context, blogID int64) error {
-	_, err := q.db.Exec(ctx, deleteBlogTagByBlogId, blogID)
+type DeleteBlogTagByBlogIdParams struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	BlogID    int64     `json:"blog_id"`
+}
+
+func (q *Queries) DeleteBlogTagByBlogId(ctx context.Context, arg DeleteBlogTagByBlogIdParams) error {
+	_, err := q.db.Exec(ctx, deleteBlogTagByBlogId, arg.DeletedAt, arg.BlogID)
 	return err
 }
 
@@ -118,9 +127,18 @@ type GetBlogTagByBlogIdAndTagIdParams struct {
 	TagID  int64 `json:"tag_id"`
 }
 
-func (q *Queries) GetBlogTagByBlogIdAndTagId(ctx context.Context, arg GetBlogTagByBlogIdAndTagIdParams) (BlogTag, error) {
+type GetBlogTagByBlogIdAndTagIdRow struct {
+	ID        int64     `json:"id"`
+	BlogID    int64     `json:"blog_id"`
+	TagID     int64     `json:"tag_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Deleted   bool      `json:"deleted"`
+}
+
+func (q *Queries) GetBlogTagByBlogIdAndTagId(ctx context.Context, arg GetBlogTagByBlogIdAndTagIdParams) (GetBlogTagByBlogIdAndTagIdRow, error) {
 	row := q.db.QueryRow(ctx, getBlogTagByBlogIdAndTagId, arg.BlogID, arg.TagID)
-	var i BlogTag
+	var i GetBlogTagByBlogIdAndTagIdRow
 	err := row.Scan(
 		&i.ID,
 		&i.BlogID,
@@ -131,3 +149,51 @@ func (q *Queries) GetBlogTagByBlogIdAndTagId(ctx context.Context, arg GetBlogTag
 	)
 	return i, err
 }
+
+const getBlogTagByBlogIds = `-- name: GetBlogTagByBlogIds :many
+SELECT
+bt.id,
+bt.blog_id,
+bt.tag_id,
+t.name,
+bt.deleted
+FROM blog_tag bt
+INNER JOIN tag t ON bt.tag_id = t.id AND t.deleted IS FALSE
+WHERE bt.deleted IS FALSE
+AND bt.blog_id = ANY($1::bigint[])
+ORDER BY bt.blog_id, bt.id
+`
+
+type GetBlogTagByBlogIdsRow struct {
+	ID      int64  `json:"id"`
+	BlogID  int64  `json:"blog_id"`
+	TagID   int64  `json:"tag_id"`
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+}
+
+func (q *Queries) GetBlogTagByBlogIds(ctx context.Context, blogIds []int64) ([]GetBlogTagByBlogIdsRow, error) {
+	rows, err := q.db.Query(ctx, getBlogTagByBlogIds, blogIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetBlogTagByBlogIdsRow{}
+	for rows.Next() {
+		var i GetBlogTagByBlogIdsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BlogID,
+			&i.TagID,
+			&i.Name,
+			&i.Deleted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
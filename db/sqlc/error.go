@@ -8,12 +8,17 @@ import (
 )
 
 const (
+	NotNullViolation    = "23502"
 	ForeignKeyViolation = "23503"
 	UniqueViolation     = "23505"
+	CheckViolation      = "23514"
 )
 
 var ErrRecordNotFound = pgx.ErrNoRows
 
+// ErrVersionConflict is returned when a row was saved by someone else since the caller loaded it
+var ErrVersionConflict = errors.New("the record was changed by someone else")
+
 var ErrUniqueViolation = &pgconn.PgError{
 	Code: UniqueViolation,
 }
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// execTx executes a function within a database transaction.
+// The transaction is rolled back when fn returns an error and committed otherwise.
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
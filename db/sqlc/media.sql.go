@@ -0,0 +1,417 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.21.0
+// source: media.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countMedia = `-- name: CountMedia :one
+SELECT COUNT(1) AS count
+FROM media m
+WHERE ($1::text = ''
+    OR m.original_name ILIKE '%' || $1::text || '%' ESCAPE '\'
+    OR m.alt_text ILIKE '%' || $1::text || '%' ESCAPE '\'
+    OR m.caption ILIKE '%' || $1::text || '%' ESCAPE '\')
+AND ($2::text = '' OR m.content_type = $2::text)
+`
+
+type CountMediaParams struct {
+	Query       string `json:"query"`
+	ContentType string `json:"content_type"`
+}
+
+// query is matched literally, its LIKE wildcards are escaped by the caller
+func (q *Queries) CountMedia(ctx context.Context, arg CountMediaParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countMedia, arg.Query, arg.ContentType)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createMedia = `-- name: CreateMedia :one
+INSERT INTO media
+(object_key, url, content_type, size, width, height, checksum, original_name, uploaded_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6,
+$7, $8, $9, NOW()::TIMESTAMPTZ)
+ON CONFLICT (checksum) DO NOTHING
+RETURNING id, object_key, url, content_type, size, original_name, uploaded_by, created_at, width, height, checksum, alt_text, caption
+`
+
+type CreateMediaParams struct {
+	ObjectKey    string      `json:"object_key"`
+	Url          string      `json:"url"`
+	ContentType  string      `json:"content_type"`
+	Size         int64       `json:"size"`
+	Width        pgtype.Int4 `json:"width"`
+	Height       pgtype.Int4 `json:"height"`
+	Checksum     pgtype.Text `json:"checksum"`
+	OriginalName string      `json:"original_name"`
+	UploadedBy   pgtype.Int8 `json:"uploaded_by"`
+}
+
+// No row is returned when a media item with the same checksum exists already
+func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (Media, error) {
+	row := q.db.QueryRow(ctx, createMedia,
+		arg.ObjectKey,
+		arg.Url,
+		arg.ContentType,
+		arg.Size,
+		arg.Width,
+		arg.Height,
+		arg.Checksum,
+		arg.OriginalName,
+		arg.UploadedBy,
+	)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.ObjectKey,
+		&i.Url,
+		&i.ContentType,
+		&i.Size,
+		&i.OriginalName,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.Width,
+		&i.Height,
+		&i.Checksum,
+		&i.AltText,
+		&i.Caption,
+	)
+	return i, err
+}
+
+const deleteMedia = `-- name: DeleteMedia :one
+DELETE FROM media m
+WHERE m.id = $1
+AND NOT EXISTS (SELECT 1 FROM blog b WHERE b.image_media_id = m.id)
+AND NOT EXISTS (SELECT 1 FROM blog_media bm WHERE bm.media_id = m.id)
+RETURNING m.id, m.object_key
+`
+
+type DeleteMediaRow struct {
+	ID        int64  `json:"id"`
+	ObjectKey string `json:"object_key"`
+}
+
+// Media used by a blog, including blogs in the trash, is kept. No row is returned then.
+func (q *Queries) DeleteMedia(ctx context.Context, id int64) (DeleteMediaRow, error) {
+	row := q.db.QueryRow(ctx, deleteMedia, id)
+	var i DeleteMediaRow
+	err := row.Scan(&i.ID, &i.ObjectKey)
+	return i, err
+}
+
+const getMediaByChecksum = `-- name: GetMediaByChecksum :one
+SELECT id, object_key, url, content_type, size, original_name, uploaded_by, created_at, width, height, checksum, alt_text, caption FROM media
+WHERE checksum = $1
+LIMIT 1
+`
+
+func (q *Queries) GetMediaByChecksum(ctx context.Context, checksum pgtype.Text) (Media, error) {
+	row := q.db.QueryRow(ctx, getMediaByChecksum, checksum)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.ObjectKey,
+		&i.Url,
+		&i.ContentType,
+		&i.Size,
+		&i.OriginalName,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.Width,
+		&i.Height,
+		&i.Checksum,
+		&i.AltText,
+		&i.Caption,
+	)
+	return i, err
+}
+
+const getMediaById = `-- name: GetMediaById :one
+SELECT m.id, m.object_key, m.url, m.content_type, m.size, m.width, m.height, m.checksum, m.alt_text, m.caption, m.original_name,
+m.uploaded_by, u.code AS uploader_code, u.first_name AS uploader_first_name, u.last_name AS uploader_last_name, m.created_at,
+(
+    SELECT COUNT(1) FROM blog b
+    WHERE b.image_media_id = m.id
+    OR EXISTS (SELECT 1 FROM blog_media bm WHERE bm.blog_id = b.id AND bm.media_id = m.id)
+) AS usage_count
+FROM media m
+LEFT JOIN users u ON m.uploaded_by = u.id
+WHERE m.id = $1
+LIMIT 1
+`
+
+type GetMediaByIdRow struct {
+	ID                int64       `json:"id"`
+	ObjectKey         string      `json:"object_key"`
+	Url               string      `json:"url"`
+	ContentType       string      `json:"content_type"`
+	Size              int64       `json:"size"`
+	Width             pgtype.Int4 `json:"width"`
+	Height            pgtype.Int4 `json:"height"`
+	Checksum          pgtype.Text `json:"checksum"`
+	AltText           string      `json:"alt_text"`
+	Caption           string      `json:"caption"`
+	OriginalName      string      `json:"original_name"`
+	UploadedBy        pgtype.Int8 `json:"uploaded_by"`
+	UploaderCode      pgtype.Text `json:"uploader_code"`
+	UploaderFirstName pgtype.Text `json:"uploader_first_name"`
+	UploaderLastName  pgtype.Text `json:"uploader_last_name"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UsageCount        int64       `json:"usage_count"`
+}
+
+func (q *Queries) GetMediaById(ctx context.Context, id int64) (GetMediaByIdRow, error) {
+	row := q.db.QueryRow(ctx, getMediaById, id)
+	var i GetMediaByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.ObjectKey,
+		&i.Url,
+		&i.ContentType,
+		&i.Size,
+		&i.Width,
+		&i.Height,
+		&i.Checksum,
+		&i.AltText,
+		&i.Caption,
+		&i.OriginalName,
+		&i.UploadedBy,
+		&i.UploaderCode,
+		&i.UploaderFirstName,
+		&i.UploaderLastName,
+		&i.CreatedAt,
+		&i.UsageCount,
+	)
+	return i, err
+}
+
+const getMediaByUrls = `-- name: GetMediaByUrls :many
+SELECT id, url
+FROM media
+WHERE url = ANY($1::varchar[])
+`
+
+type GetMediaByUrlsRow struct {
+	ID  int64  `json:"id"`
+	Url string `json:"url"`
+}
+
+func (q *Queries) GetMediaByUrls(ctx context.Context, urls []string) ([]GetMediaByUrlsRow, error) {
+	rows, err := q.db.Query(ctx, getMediaByUrls, urls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMediaByUrlsRow{}
+	for rows.Next() {
+		var i GetMediaByUrlsRow
+		if err := rows.Scan(&i.ID, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBlogByMediaId = `-- name: ListBlogByMediaId :many
+SELECT b.id, b.title, b.url, b.status, b.deleted
+FROM blog b
+WHERE b.image_media_id = $1::bigint
+OR EXISTS (SELECT 1 FROM blog_media bm WHERE bm.blog_id = b.id AND bm.media_id = $1::bigint)
+ORDER BY b.id DESC
+`
+
+type ListBlogByMediaIdRow struct {
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Url     string `json:"url"`
+	Status  string `json:"status"`
+	Deleted bool   `json:"deleted"`
+}
+
+func (q *Queries) ListBlogByMediaId(ctx context.Context, mediaID int64) ([]ListBlogByMediaIdRow, error) {
+	rows, err := q.db.Query(ctx, listBlogByMediaId, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListBlogByMediaIdRow{}
+	for rows.Next() {
+		var i ListBlogByMediaIdRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Status,
+			&i.Deleted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMedia = `-- name: ListMedia :many
+SELECT m.id, m.object_key, m.url, m.content_type, m.size, m.width, m.height, m.checksum, m.alt_text, m.caption, m.original_name,
+m.uploaded_by, u.code AS uploader_code, u.first_name AS uploader_first_name, u.last_name AS uploader_last_name, m.created_at,
+(
+    SELECT COUNT(1) FROM blog b
+    WHERE b.image_media_id = m.id
+    OR EXISTS (SELECT 1 FROM blog_media bm WHERE bm.blog_id = b.id AND bm.media_id = m.id)
+) AS usage_count
+FROM media m
+LEFT JOIN users u ON m.uploaded_by = u.id
+WHERE ($1::text = ''
+    OR m.original_name ILIKE '%' || $1::text || '%' ESCAPE '\'
+    OR m.alt_text ILIKE '%' || $1::text || '%' ESCAPE '\'
+    OR m.caption ILIKE '%' || $1::text || '%' ESCAPE '\')
+AND ($2::text = '' OR m.content_type = $2::text)
+ORDER BY m.created_at DESC, m.id DESC
+OFFSET $3
+LIMIT $4
+`
+
+type ListMediaParams struct {
+	Query       string `json:"query"`
+	ContentType string `json:"content_type"`
+	OffsetRows  int32  `json:"offset_rows"`
+	LimitRows   int32  `json:"limit_rows"`
+}
+
+type ListMediaRow struct {
+	ID                int64       `json:"id"`
+	ObjectKey         string      `json:"object_key"`
+	Url               string      `json:"url"`
+	ContentType       string      `json:"content_type"`
+	Size              int64       `json:"size"`
+	Width             pgtype.Int4 `json:"width"`
+	Height            pgtype.Int4 `json:"height"`
+	Checksum          pgtype.Text `json:"checksum"`
+	AltText           string      `json:"alt_text"`
+	Caption           string      `json:"caption"`
+	OriginalName      string      `json:"original_name"`
+	UploadedBy        pgtype.Int8 `json:"uploaded_by"`
+	UploaderCode      pgtype.Text `json:"uploader_code"`
+	UploaderFirstName pgtype.Text `json:"uploader_first_name"`
+	UploaderLastName  pgtype.Text `json:"uploader_last_name"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UsageCount        int64       `json:"usage_count"`
+}
+
+// query is matched literally, its LIKE wildcards are escaped by the caller
+func (q *Queries) ListMedia(ctx context.Context, arg ListMediaParams) ([]ListMediaRow, error) {
+	rows, err := q.db.Query(ctx, listMedia,
+		arg.Query,
+		arg.ContentType,
+		arg.OffsetRows,
+		arg.LimitRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListMediaRow{}
+	for rows.Next() {
+		var i ListMediaRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ObjectKey,
+			&i.Url,
+			&i.ContentType,
+			&i.Size,
+			&i.Width,
+			&i.Height,
+			&i.Checksum,
+			&i.AltText,
+			&i.Caption,
+			&i.OriginalName,
+			&i.UploadedBy,
+			&i.UploaderCode,
+			&i.UploaderFirstName,
+			&i.UploaderLastName,
+			&i.CreatedAt,
+			&i.UsageCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setBlogMedia = `-- name: SetBlogMedia :exec
+WITH removed AS (
+    DELETE FROM blog_media
+    WHERE blog_id = $1
+    AND NOT (media_id = ANY($2::bigint[]))
+)
+INSERT INTO blog_media (blog_id, media_id)
+SELECT $1, unnest($2::bigint[])
+ON CONFLICT DO NOTHING
+`
+
+type SetBlogMediaParams struct {
+	BlogID   int64   `json:"blog_id"`
+	MediaIds []int64 `json:"media_ids"`
+}
+
+// Replaces the media shown inline in a blog
+func (q *Queries) SetBlogMedia(ctx context.Context, arg SetBlogMediaParams) error {
+	_, err := q.db.Exec(ctx, setBlogMedia, arg.BlogID, arg.MediaIds)
+	return err
+}
+
+const updateMedia = `-- name: UpdateMedia :one
+UPDATE media
+SET alt_text = $1,
+caption = $2
+WHERE id = $3
+RETURNING id, object_key, url, content_type, size, original_name, uploaded_by, created_at, width, height, checksum, alt_text, caption
+`
+
+type UpdateMediaParams struct {
+	AltText string `json:"alt_text"`
+	Caption string `json:"caption"`
+	ID      int64  `json:"id"`
+}
+
+func (q *Queries) UpdateMedia(ctx context.Context, arg UpdateMediaParams) (Media, error) {
+	row := q.db.QueryRow(ctx, updateMedia, arg.AltText, arg.Caption, arg.ID)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.ObjectKey,
+		&i.Url,
+		&i.ContentType,
+		&i.Size,
+		&i.OriginalName,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.Width,
+		&i.Height,
+		&i.Checksum,
+		&i.AltText,
+		&i.Caption,
+	)
+	return i, err
+}
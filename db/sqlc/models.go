@@ -12,23 +12,98 @@ import (
 )
 
 type Blog struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Image     string    `json:"image"`
-	Url       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Deleted   bool      `json:"deleted"`
+	ID                 int64              `json:"id"`
+	Title              string             `json:"title"`
+	Content            string             `json:"content"`
+	Image              string             `json:"image"`
+	Url                string             `json:"url"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	Deleted            bool               `json:"deleted"`
+	Status             string             `json:"status"`
+	PublishedAt        pgtype.Timestamptz `json:"published_at"`
+	PublishAt          pgtype.Timestamptz `json:"publish_at"`
+	AuthorID           int64              `json:"author_id"`
+	UpdatedBy          pgtype.Int8        `json:"updated_by"`
+	SearchVector       interface{}        `json:"search_vector"`
+	SearchVersion      int32              `json:"search_version"`
+	ViewCount          int64              `json:"view_count"`
+	Excerpt            string             `json:"excerpt"`
+	AutoExcerpt        string             `json:"auto_excerpt"`
+	WordCount          int32              `json:"word_count"`
+	ReadingTimeMinutes int32              `json:"reading_time_minutes"`
+	ContentFormat      string             `json:"content_format"`
+	ContentHtml        string             `json:"content_html"`
+	DeletedAt          pgtype.Timestamptz `json:"deleted_at"`
+	Version            int32              `json:"version"`
+	ImageMediaID       pgtype.Int8        `json:"image_media_id"`
 }
 
-type BlogTag struct {
+type BlogLock struct {
+	BlogID     int64     `json:"blog_id"`
+	UserID     int64     `json:"user_id"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type BlogMedium struct {
+	BlogID  int64 `json:"blog_id"`
+	MediaID int64 `json:"media_id"`
+}
+
+type BlogRedirect struct {
 	ID        int64     `json:"id"`
+	OldUrl    string    `json:"old_url"`
 	BlogID    int64     `json:"blog_id"`
-	TagID     int64     `json:"tag_id"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Deleted   bool      `json:"deleted"`
+}
+
+type BlogReview struct {
+	ID             int64       `json:"id"`
+	BlogID         int64       `json:"blog_id"`
+	BlogRevisionID pgtype.Int8 `json:"blog_revision_id"`
+	UserID         int64       `json:"user_id"`
+	Action         string      `json:"action"`
+	Note           string      `json:"note"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+type BlogRevision struct {
+	ID            int64       `json:"id"`
+	BlogID        int64       `json:"blog_id"`
+	Title         string      `json:"title"`
+	Content       string      `json:"content"`
+	Image         string      `json:"image"`
+	Url           string      `json:"url"`
+	EditedBy      pgtype.Int8 `json:"edited_by"`
+	CreatedAt     time.Time   `json:"created_at"`
+	ContentFormat string      `json:"content_format"`
+}
+
+type BlogTag struct {
+	ID        int64              `json:"id"`
+	BlogID    int64              `json:"blog_id"`
+	TagID     int64              `json:"tag_id"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Deleted   bool               `json:"deleted"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+type Media struct {
+	ID           int64       `json:"id"`
+	ObjectKey    string      `json:"object_key"`
+	Url          string      `json:"url"`
+	ContentType  string      `json:"content_type"`
+	Size         int64       `json:"size"`
+	OriginalName string      `json:"original_name"`
+	UploadedBy   pgtype.Int8 `json:"uploaded_by"`
+	CreatedAt    time.Time   `json:"created_at"`
+	Width        pgtype.Int4 `json:"width"`
+	Height       pgtype.Int4 `json:"height"`
+	Checksum     pgtype.Text `json:"checksum"`
+	AltText      string      `json:"alt_text"`
+	Caption      string      `json:"caption"`
 }
 
 type Permission struct {
@@ -58,6 +133,8 @@ type Role struct {
 	CreatedAt time.Time          `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 	Deleted   bool               `json:"deleted"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	Version   int32              `json:"version"`
 }
 
 type RolePermission struct {
@@ -67,6 +144,7 @@ type RolePermission struct {
 	CreatedAt    time.Time          `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 	Deleted      bool               `json:"deleted"`
+	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
 }
 
 type Session struct {
@@ -81,11 +159,12 @@ type Session struct {
 }
 
 type Tag struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Deleted   bool      `json:"deleted"`
+	ID        int64              `json:"id"`
+	Name      string             `json:"name"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Deleted   bool               `json:"deleted"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
 }
 
 type User struct {
@@ -102,6 +181,8 @@ type User struct {
 	CreatedAt         time.Time          `json:"created_at"`
 	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
 	Deleted           bool               `json:"deleted"`
+	DeletedAt         pgtype.Timestamptz `json:"deleted_at"`
+	Version           int32              `json:"version"`
 }
 
 type UserRole struct {
@@ -111,4 +192,5 @@ type UserRole struct {
 	CreatedAt time.Time          `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 	Deleted   bool               `json:"deleted"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
 }
@@ -13,45 +13,78 @@ import (
 )
 
 type Querier interface {
-	CountAllBlog(ctx context.Context, lower string) (int64, error)
-	CountAllBlogWithTag(ctx context.Context, arg CountAllBlogWithTagParams) (int64, error)
+	// Takes the lock when it is free, expired or already held by the user, a heartbeat renews it the same way.
+	// With force the lock is taken over from whoever holds it. No row is returned when someone else holds it.
+	AcquireBlogLock(ctx context.Context, arg AcquireBlogLockParams) (BlogLock, error)
 	CountAllRole(ctx context.Context, lower string) (int64, error)
-	CountAllTag(ctx context.Context, lower string) (int64, error)
+	CountAllTag(ctx context.Context, arg CountAllTagParams) (int64, error)
+	CountBlog(ctx context.Context, arg CountBlogParams) (int64, error)
+	CountBlogRevisionByBlogId(ctx context.Context, blogID int64) (int64, error)
+	CountDeletedBlog(ctx context.Context) (int64, error)
+	CountDeletedRole(ctx context.Context) (int64, error)
+	CountDeletedTag(ctx context.Context) (int64, error)
+	CountDeletedUser(ctx context.Context) (int64, error)
+	// query is matched literally, its LIKE wildcards are escaped by the caller
+	CountMedia(ctx context.Context, arg CountMediaParams) (int64, error)
 	CountUser(ctx context.Context) (int64, error)
 	CountUserForGenerateCode(ctx context.Context) (int64, error)
-	CreateBlog(ctx context.Context, arg CreateBlogParams) (Blog, error)
+	CreateBlog(ctx context.Context, arg CreateBlogParams) (CreateBlogRow, error)
+	CreateBlogReview(ctx context.Context, arg CreateBlogReviewParams) (BlogReview, error)
+	CreateBlogRevision(ctx context.Context, arg CreateBlogRevisionParams) (BlogRevision, error)
 	CreateBlogTag(ctx context.Context, arg CreateBlogTagParams) error
+	// No row is returned when a media item with the same checksum exists already
+	CreateMedia(ctx context.Context, arg CreateMediaParams) (Media, error)
 	CreateResetPassword(ctx context.Context, arg CreateResetPasswordParams) (ResetPassword, error)
 	CreateRole(ctx context.Context, name string) (Role, error)
-	CreateRolePermission(ctx context.Context, arg CreateRolePermissionParams) (RolePermission, error)
+	CreateRolePermission(ctx context.Context, arg CreateRolePermissionParams) (CreateRolePermissionRow, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTag(ctx context.Context, name string) (Tag, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateUserRole(ctx context.Context, arg CreateUserRoleParams) error
-	DeleteBlog(ctx context.Context, id int64) error
+	DeleteBlog(ctx context.Context, arg DeleteBlogParams) error
+	DeleteBlogRedirectByOldUrl(ctx context.Context, oldUrl string) error
 	DeleteBlogTag(ctx context.Context, arg DeleteBlogTagParams) error
-	DeleteBlogTagByBlogId(ctx context.Context, blogID int64) error
-	DeleteRole(ctx context.Context, id int64) error
+	DeleteBlogTagByBlogId(ctx context.Context, arg DeleteBlogTagByBlogIdParams) error
+	// Media used by a blog, including blogs in the trash, is kept. No row is returned then.
+	DeleteMedia(ctx context.Context, id int64) (DeleteMediaRow, error)
+	DeleteRole(ctx context.Context, arg DeleteRoleParams) error
 	DeleteRolePermission(ctx context.Context, arg DeleteRolePermissionParams) error
-	DeleteRolePermissionByRoleId(ctx context.Context, roleID int64) error
+	DeleteRolePermissionByRoleId(ctx context.Context, arg DeleteRolePermissionByRoleIdParams) error
 	DeleteTag(ctx context.Context, id int64) error
-	DeleteUser(ctx context.Context, id int64) error
-	DeleteUserRoleByUserId(ctx context.Context, userID int64) error
+	DeleteUser(ctx context.Context, arg DeleteUserParams) error
+	DeleteUserRoleByUserId(ctx context.Context, arg DeleteUserRoleByUserIdParams) error
+	ExistsBlogUrl(ctx context.Context, arg ExistsBlogUrlParams) (bool, error)
+	ExistsDeletedBlogById(ctx context.Context, id int64) (bool, error)
+	ExistsDeletedBlogByUrl(ctx context.Context, arg ExistsDeletedBlogByUrlParams) (bool, error)
 	// Queries to fix import errors in querier.go file
 	// Please not use this queries for other purpose
 	FixErrorImportPGType(ctx context.Context, dollar_1 pgtype.Timestamp) (interface{}, error)
 	// Queries to fix import errors in querier.go file
 	// Please not use this queries for other purpose
 	FixErrorImportTime(ctx context.Context, createdAt time.Time) (int64, error)
-	GetAllBlog(ctx context.Context, arg GetAllBlogParams) ([]GetAllBlogRow, error)
-	GetAllBlogWithTag(ctx context.Context, arg GetAllBlogWithTagParams) ([]GetAllBlogWithTagRow, error)
 	GetAllPermissionGroup(ctx context.Context) ([]PermissionGroup, error)
 	GetAllRole(ctx context.Context, arg GetAllRoleParams) ([]GetAllRoleRow, error)
 	GetAllTag(ctx context.Context, arg GetAllTagParams) ([]GetAllTagRow, error)
 	GetBlogById(ctx context.Context, id int64) (GetBlogByIdRow, error)
-	GetBlogByUrl(ctx context.Context, url string) (GetBlogByUrlRow, error)
+	GetBlogByUrl(ctx context.Context, arg GetBlogByUrlParams) (GetBlogByUrlRow, error)
+	// An expired lock is treated as no lock at all
+	GetBlogLock(ctx context.Context, blogID int64) (GetBlogLockRow, error)
+	// Search versions are fingerprints rather than counters, any version but the current one is stale
+	GetBlogPendingSearchIndex(ctx context.Context, arg GetBlogPendingSearchIndexParams) ([]GetBlogPendingSearchIndexRow, error)
+	GetBlogRedirect(ctx context.Context, arg GetBlogRedirectParams) (GetBlogRedirectRow, error)
+	GetBlogReviewByBlogId(ctx context.Context, blogID int64) ([]GetBlogReviewByBlogIdRow, error)
+	GetBlogRevisionByBlogId(ctx context.Context, arg GetBlogRevisionByBlogIdParams) ([]GetBlogRevisionByBlogIdRow, error)
+	GetBlogRevisionById(ctx context.Context, id int64) (GetBlogRevisionByIdRow, error)
 	GetBlogTagByBlogId(ctx context.Context, blogID int64) ([]GetBlogTagByBlogIdRow, error)
-	GetBlogTagByBlogIdAndTagId(ctx context.Context, arg GetBlogTagByBlogIdAndTagIdParams) (BlogTag, error)
+	GetBlogTagByBlogIdAndTagId(ctx context.Context, arg GetBlogTagByBlogIdAndTagIdParams) (GetBlogTagByBlogIdAndTagIdRow, error)
+	GetBlogTagByBlogIds(ctx context.Context, blogIds []int64) ([]GetBlogTagByBlogIdsRow, error)
+	GetBlogTagFacet(ctx context.Context, arg GetBlogTagFacetParams) ([]GetBlogTagFacetRow, error)
+	GetDeletedBlogById(ctx context.Context, id int64) (GetDeletedBlogByIdRow, error)
+	GetDeletedUserById(ctx context.Context, id int64) (GetDeletedUserByIdRow, error)
+	GetLatestBlogRevisionIdByBlogId(ctx context.Context, blogID int64) (int64, error)
+	GetMediaByChecksum(ctx context.Context, checksum pgtype.Text) (Media, error)
+	GetMediaById(ctx context.Context, id int64) (GetMediaByIdRow, error)
+	GetMediaByUrls(ctx context.Context, urls []string) ([]GetMediaByUrlsRow, error)
 	GetPermissionByPermissionGroupId(ctx context.Context, permissionGroupID int64) ([]GetPermissionByPermissionGroupIdRow, error)
 	GetPermissionByPermissionGroupIdAndRoleId(ctx context.Context, arg GetPermissionByPermissionGroupIdAndRoleIdParams) ([]GetPermissionByPermissionGroupIdAndRoleIdRow, error)
 	GetPermissionByUserId(ctx context.Context, id int64) ([]string, error)
@@ -59,7 +92,8 @@ type Querier interface {
 	GetRoleById(ctx context.Context, id int64) (GetRoleByIdRow, error)
 	GetRoleByUserId(ctx context.Context, userID int64) ([]GetRoleByUserIdRow, error)
 	GetRoleForDropDownList(ctx context.Context) ([]GetRoleForDropDownListRow, error)
-	GetRolePermissionByRoleIdAndPermissionId(ctx context.Context, arg GetRolePermissionByRoleIdAndPermissionIdParams) (RolePermission, error)
+	GetRolePermissionByRoleIdAndPermissionId(ctx context.Context, arg GetRolePermissionByRoleIdAndPermissionIdParams) (GetRolePermissionByRoleIdAndPermissionIdRow, error)
+	GetSearchSuggestion(ctx context.Context, arg GetSearchSuggestionParams) (string, error)
 	GetSession(ctx context.Context, id uuid.UUID) (GetSessionRow, error)
 	GetTagByCreatedAt(ctx context.Context, createdAt time.Time) ([]GetTagByCreatedAtRow, error)
 	GetTagById(ctx context.Context, id int64) (GetTagByIdRow, error)
@@ -67,12 +101,57 @@ type Querier interface {
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
 	GetUserHashedPassword(ctx context.Context, id int64) (string, error)
+	IncrementBlogViewCount(ctx context.Context, id int64) error
+	// The snippet is cut from the text of the post without its markup, search.HighlightSnippet escapes it.
+	// The body is left empty unless with_content is set, listings send the excerpt instead.
+	ListBlog(ctx context.Context, arg ListBlogParams) ([]ListBlogRow, error)
+	// The snippet is cut from the text of the post without its markup, search.HighlightSnippet escapes it.
+	// The body is left empty unless with_content is set, listings send the excerpt instead.
+	// Posts are listed by publish date, posts that were never published by their creation date.
+	ListBlogByCursor(ctx context.Context, arg ListBlogByCursorParams) ([]ListBlogByCursorRow, error)
+	ListBlogByMediaId(ctx context.Context, mediaID int64) ([]ListBlogByMediaIdRow, error)
+	ListDeletedBlog(ctx context.Context, arg ListDeletedBlogParams) ([]ListDeletedBlogRow, error)
+	ListDeletedRole(ctx context.Context, arg ListDeletedRoleParams) ([]ListDeletedRoleRow, error)
+	ListDeletedTag(ctx context.Context, arg ListDeletedTagParams) ([]ListDeletedTagRow, error)
+	ListDeletedUser(ctx context.Context, arg ListDeletedUserParams) ([]ListDeletedUserRow, error)
+	// query is matched literally, its LIKE wildcards are escaped by the caller
+	ListMedia(ctx context.Context, arg ListMediaParams) ([]ListMediaRow, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
-	UpdateBlog(ctx context.Context, arg UpdateBlogParams) error
-	UpdateRole(ctx context.Context, arg UpdateRoleParams) error
+	PublishScheduledBlog(ctx context.Context, dueAt time.Time) ([]int64, error)
+	// Permanently removes blogs in the trash, by id or deleted before a time, with everything that belongs to them
+	PurgeBlog(ctx context.Context, arg PurgeBlogParams) (int64, error)
+	// Permanently removes roles in the trash, by id or deleted before a time, and takes them away from every user
+	PurgeRole(ctx context.Context, arg PurgeRoleParams) (int64, error)
+	// Permanently removes tags in the trash, by id or deleted before a time, and detaches them from every blog
+	PurgeTag(ctx context.Context, arg PurgeTagParams) (int64, error)
+	// Permanently removes users in the trash, by id or deleted before a time. Users who still author
+	// blogs or reviews are kept, their content has to be handed over or purged first.
+	PurgeUser(ctx context.Context, arg PurgeUserParams) (int64, error)
+	// Releases the lock of the user, with force it is broken whoever holds it
+	ReleaseBlogLock(ctx context.Context, arg ReleaseBlogLockParams) (int64, error)
+	// Tags removed together with the blog come back with it, tags removed earlier stay removed
+	RestoreBlog(ctx context.Context, arg RestoreBlogParams) (RestoreBlogRow, error)
+	// Permissions removed together with the role come back with it
+	RestoreRole(ctx context.Context, id int64) (RestoreRoleRow, error)
+	RestoreTag(ctx context.Context, id int64) (RestoreTagRow, error)
+	// Roles removed together with the user come back with it
+	RestoreUser(ctx context.Context, id int64) (RestoreUserRow, error)
+	// Replaces the media shown inline in a blog
+	SetBlogMedia(ctx context.Context, arg SetBlogMediaParams) error
+	SuggestBlog(ctx context.Context, arg SuggestBlogParams) ([]SuggestBlogRow, error)
+	SuggestTag(ctx context.Context, arg SuggestTagParams) ([]SuggestTagRow, error)
+	// No row is updated when the blog was saved by someone else since the caller loaded version
+	UpdateBlog(ctx context.Context, arg UpdateBlogParams) (int64, error)
+	UpdateBlogSearchIndex(ctx context.Context, arg UpdateBlogSearchIndexParams) error
+	UpdateBlogStatus(ctx context.Context, arg UpdateBlogStatusParams) (int64, error)
+	UpdateMedia(ctx context.Context, arg UpdateMediaParams) (Media, error)
+	// No row is returned when the role was saved by someone else since the caller loaded version
+	UpdateRole(ctx context.Context, arg UpdateRoleParams) (UpdateRoleRow, error)
 	UpdateTag(ctx context.Context, arg UpdateTagParams) error
+	// No row is returned when the user was saved by someone else since the caller loaded version
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpsertBlogRedirect(ctx context.Context, arg UpsertBlogRedirectParams) error
 	UseResetPassword(ctx context.Context, token string) error
 }
 
@@ -7,6 +7,9 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const countAllRole = `-- name: CountAllRole :one
@@ -24,10 +27,23 @@ func (q *Queries) CountAllRole(ctx context.Context, lower string) (int64, error)
 	return count, err
 }
 
+const countDeletedRole = `-- name: CountDeletedRole :one
+SELECT COUNT(1) AS count
+FROM "role"
+WHERE deleted IS TRUE
+`
+
+func (q *Queries) CountDeletedRole(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeletedRole)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createRole = `-- name: CreateRole :one
 INSERT INTO "role" (name, created_at)
 VALUES ($1, NOW()::TIMESTAMP)
-RETURNING id, name, created_at, updated_at, deleted
+RETURNING id, name, created_at, updated_at, deleted, deleted_at, version
 `
 
 func (q *Queries) CreateRole(ctx context.Context, name string) (Role, error) {
@@ -39,6 +55,8 @@ func (q *Queries) CreateRole(ctx context.Context, name string) (Role, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Deleted,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -46,13 +64,19 @@ func (q *Queries) CreateRole(ctx context.Context, name string) (Role, error) {
 const deleteRole = `-- name: DeleteRole :exec
 UPDATE "role"
 SET updated_at = NOW()::TIMESTAMP,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = $1::TIMESTAMPTZ
 WHERE deleted IS False 
-AND id = $1
+AND id = $2
 `
 
-func (q *Queries) DeleteRole(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteRole, id)
+type DeleteRoleParams struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	ID        int64     `json:"id"`
+}
+
+func (q *Queries) DeleteRole(ctx context.Context, arg DeleteRoleParams) error {
+	_, err := q.db.Exec(ctx, deleteRole, arg.DeletedAt, arg.ID)
 	return err
 }
 
@@ -100,7 +124,7 @@ func (q *Queries) GetAllRole(ctx context.Context, arg GetAllRoleParams) ([]GetAl
 
 const getRoleById = `-- name: GetRoleById :one
 SELECT
-id, name
+id, name, version
 FROM "role"
 WHERE deleted IS False 
 AND id = $1
@@ -108,14 +132,15 @@ LIMIT 1
 `
 
 type GetRoleByIdRow struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Version int32  `json:"version"`
 }
 
 func (q *Queries) GetRoleById(ctx context.Context, id int64) (GetRoleByIdRow, error) {
 	row := q.db.QueryRow(ctx, getRoleById, id)
 	var i GetRoleByIdRow
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(&i.ID, &i.Name, &i.Version)
 	return i, err
 }
 
@@ -187,20 +212,140 @@ func (q *Queries) GetRoleForDropDownList(ctx context.Context) ([]GetRoleForDropD
 	return items, nil
 }
 
-const updateRole = `-- name: UpdateRole :exec
+const listDeletedRole = `-- name: ListDeletedRole :many
+SELECT id, name, deleted_at
+FROM "role"
+WHERE deleted IS TRUE
+ORDER BY deleted_at DESC NULLS LAST, id DESC
+OFFSET $1
+LIMIT $2
+`
+
+type ListDeletedRoleParams struct {
+	OffsetRows int32 `json:"offset_rows"`
+	LimitRows  int32 `json:"limit_rows"`
+}
+
+type ListDeletedRoleRow struct {
+	ID        int64              `json:"id"`
+	Name      string             `json:"name"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedRole(ctx context.Context, arg ListDeletedRoleParams) ([]ListDeletedRoleRow, error) {
+	rows, err := q.db.Query(ctx, listDeletedRole, arg.OffsetRows, arg.LimitRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedRoleRow{}
+	for rows.Next() {
+		var i ListDeletedRoleRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeRole = `-- name: PurgeRole :execrows
+WITH target AS (
+    SELECT id
+    FROM "role"
+    WHERE deleted IS TRUE
+    AND ($1::bigint IS NULL OR id = $1)
+    AND ($2::timestamptz IS NULL OR deleted_at < $2)
+), purged_permissions AS (
+    DELETE FROM "role_permission" WHERE role_id IN (SELECT id FROM target)
+), purged_user_roles AS (
+    DELETE FROM user_role WHERE role_id IN (SELECT id FROM target)
+)
+DELETE FROM "role"
+WHERE id IN (SELECT id FROM target)
+`
+
+type PurgeRoleParams struct {
+	ID            pgtype.Int8        `json:"id"`
+	DeletedBefore pgtype.Timestamptz `json:"deleted_before"`
+}
+
+// Permanently removes roles in the trash, by id or deleted before a time, and takes them away from every user
+func (q *Queries) PurgeRole(ctx context.Context, arg PurgeRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeRole, arg.ID, arg.DeletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreRole = `-- name: RestoreRole :one
+WITH target AS (
+    SELECT dr.id, dr.deleted_at
+    FROM "role" dr
+    WHERE dr.deleted IS TRUE
+    AND dr.id = $1
+), relinked_permissions AS (
+    UPDATE "role_permission" rp
+    SET deleted = FALSE,
+    deleted_at = NULL,
+    updated_at = NOW()::TIMESTAMP
+    FROM target
+    WHERE rp.role_id = target.id
+    AND rp.deleted_at = target.deleted_at
+)
+UPDATE "role" r
+SET deleted = FALSE,
+deleted_at = NULL,
+updated_at = NOW()::TIMESTAMP
+FROM target
+WHERE r.id = target.id
+RETURNING r.id, r.name
+`
+
+type RestoreRoleRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Permissions removed together with the role come back with it
+func (q *Queries) RestoreRole(ctx context.Context, id int64) (RestoreRoleRow, error) {
+	row := q.db.QueryRow(ctx, restoreRole, id)
+	var i RestoreRoleRow
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
+const updateRole = `-- name: UpdateRole :one
 UPDATE "role"
 SET name = $2,
+version = version + 1,
 updated_at = NOW()::TIMESTAMP
 WHERE deleted IS False 
 AND id = $1
+AND version = $3
+RETURNING id, name, version
 `
 
 type UpdateRoleParams struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Version int32  `json:"version"`
 }
 
-func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) error {
-	_, err := q.db.Exec(ctx, updateRole, arg.ID, arg.Name)
-	return err
+type UpdateRoleRow struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Version int32  `json:"version"`
+}
+
+// No row is returned when the role was saved by someone else since the caller loaded version
+func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (UpdateRoleRow, error) {
+	row := q.db.QueryRow(ctx, updateRole, arg.ID, arg.Name, arg.Version)
+	var i UpdateRoleRow
+	err := row.Scan(&i.ID, &i.Name, &i.Version)
+	return i, err
 }
@@ -7,6 +7,9 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createRolePermission = `-- name: CreateRolePermission :one
@@ -20,9 +23,18 @@ type CreateRolePermissionParams struct {
 	PermissionID int64 `json:"permission_id"`
 }
 
-func (q *Queries) CreateRolePermission(ctx context.Context, arg CreateRolePermissionParams) (RolePermission, error) {
+type CreateRolePermissionRow struct {
+	ID           int64              `json:"id"`
+	RoleID       int64              `json:"role_id"`
+	PermissionID int64              `json:"permission_id"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Deleted      bool               `json:"deleted"`
+}
+
+func (q *Queries) CreateRolePermission(ctx context.Context, arg CreateRolePermissionParams) (CreateRolePermissionRow, error) {
 	row := q.db.QueryRow(ctx, createRolePermission, arg.RoleID, arg.PermissionID)
-	var i RolePermission
+	var i CreateRolePermissionRow
 	err := row.Scan(
 		&i.ID,
 		&i.RoleID,
@@ -37,7 +49,8 @@ func (q *Queries) CreateRolePermission(ctx context.Context, arg CreateRolePermis
 const deleteRolePermission = `-- name: DeleteRolePermission :exec
 UPDATE "role_permission"
 SET updated_at = NOW()::TIMESTAMP,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = NOW()::TIMESTAMPTZ
 WHERE deleted IS False
 AND role_id = $1
 AND permission_id = $2
@@ -56,12 +69,19 @@ func (q *Queries) DeleteRolePermission(ctx context.Context, arg DeleteRolePermis
 const deleteRolePermissionByRoleId = `-- name: DeleteRolePermissionByRoleId :exec
 UPDATE "role_permission"
 SET updated_at = NOW()::TIMESTAMP,
-deleted = TRUE
-WHERE role_id = $1
+deleted = TRUE,
+deleted_at = $1::TIMESTAMPTZ
+WHERE role_id = $2
+AND deleted IS False
 `
 
-func (q *Queries) DeleteRolePermissionByRoleId(ctx context.Context, roleID int64) error {
-	_, err := q.db.Exec(ctx, deleteRolePermissionByRoleId, roleID)
+type DeleteRolePermissionByRoleIdParams struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	RoleID    int64     `json:"role_id"`
+}
+
+func (q *Queries) DeleteRolePermissionByRoleId(ctx context.Context, arg DeleteRolePermissionByRoleIdParams) error {
+	_, err := q.db.Exec(ctx, deleteRolePermissionByRoleId, arg.DeletedAt, arg.RoleID)
 	return err
 }
 
@@ -80,9 +100,18 @@ type GetRolePermissionByRoleIdAndPermissionIdParams struct {
 	PermissionID int64 `json:"permission_id"`
 }
 
-func (q *Queries) GetRolePermissionByRoleIdAndPermissionId(ctx context.Context, arg GetRolePermissionByRoleIdAndPermissionIdParams) (RolePermission, error) {
+type GetRolePermissionByRoleIdAndPermissionIdRow struct {
+	ID           int64              `json:"id"`
+	RoleID       int64              `json:"role_id"`
+	PermissionID int64              `json:"permission_id"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Deleted      bool               `json:"deleted"`
+}
+
+func (q *Queries) GetRolePermissionByRoleIdAndPermissionId(ctx context.Context, arg GetRolePermissionByRoleIdAndPermissionIdParams) (GetRolePermissionByRoleIdAndPermissionIdRow, error) {
 	row := q.db.QueryRow(ctx, getRolePermissionByRoleIdAndPermissionId, arg.RoleID, arg.PermissionID)
-	var i RolePermission
+	var i GetRolePermissionByRoleIdAndPermissionIdRow
 	err := row.Scan(
 		&i.ID,
 		&i.RoleID,
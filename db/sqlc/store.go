@@ -1,12 +1,26 @@
 package db
 
 import (
+	"context"
+	"time"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Store defines all functions to execute db queries and transactions
 type Store interface {
 	Querier
+	CreateBlogTx(ctx context.Context, arg CreateBlogTxParams) (CreateBlogTxResult, error)
+	UpdateBlogTx(ctx context.Context, arg UpdateBlogTxParams) (UpdateBlogTxResult, error)
+	DeleteBlogTx(ctx context.Context, blogID int64, deletedAt time.Time) error
+	ChangeBlogStatusTx(ctx context.Context, arg ChangeBlogStatusTxParams) (BlogReview, error)
+	CreateBlogReviewTx(ctx context.Context, arg CreateBlogReviewParams) (BlogReview, error)
+	CreateRoleTx(ctx context.Context, name string, permissions []RolePermissionChange) (Role, error)
+	UpdateRoleTx(ctx context.Context, arg UpdateRoleParams, permissions []RolePermissionChange) (UpdateRoleRow, error)
+	DeleteRoleTx(ctx context.Context, roleID int64, deletedAt time.Time) error
+	CreateUserTx(ctx context.Context, arg CreateUserParams, roleIDs []int64) (User, error)
+	UpdateUserWithRolesTx(ctx context.Context, arg UpdateUserParams, roleIDs []int64) (User, error)
+	DeleteUserTx(ctx context.Context, userID int64, deletedAt time.Time) error
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
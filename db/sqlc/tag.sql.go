@@ -8,18 +8,41 @@ package db
 import (
 	"context"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const countAllTag = `-- name: CountAllTag :one
 SELECT COUNT(1) AS count
 FROM tag
 WHERE deleted IS FALSE
-AND LOWER(name) LIKE '%' || LOWER($1) || '%' 
+AND (
+    LOWER(name) LIKE '%' || LOWER($1::text) || '%' ESCAPE '\'
+    OR LOWER($2::text) <% LOWER(name)
+)
 LIMIT 1
 `
 
-func (q *Queries) CountAllTag(ctx context.Context, lower string) (int64, error) {
-	row := q.db.QueryRow(ctx, countAllTag, lower)
+type CountAllTagParams struct {
+	NamePattern string `json:"name_pattern"`
+	Name        string `json:"name"`
+}
+
+func (q *Queries) CountAllTag(ctx context.Context, arg CountAllTagParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAllTag, arg.NamePattern, arg.Name)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countDeletedTag = `-- name: CountDeletedTag :one
+SELECT COUNT(1) AS count
+FROM tag
+WHERE deleted IS TRUE
+`
+
+func (q *Queries) CountDeletedTag(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeletedTag)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -29,7 +52,7 @@ const createTag = `-- name: CreateTag :one
 INSERT INTO tag
 (name, created_at)
 VALUES ($1, NOW()::TIMESTAMPTZ)
-RETURNING id, name, created_at, updated_at, deleted
+RETURNING id, name, created_at, updated_at, deleted, deleted_at
 `
 
 func (q *Queries) CreateTag(ctx context.Context, name string) (Tag, error) {
@@ -41,6 +64,7 @@ func (q *Queries) CreateTag(ctx context.Context, name string) (Tag, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Deleted,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -48,7 +72,8 @@ func (q *Queries) CreateTag(ctx context.Context, name string) (Tag, error) {
 const deleteTag = `-- name: DeleteTag :exec
 UPDATE tag
 SET updated_at = NOW()::TIMESTAMPTZ,
-deleted = TRUE
+deleted = TRUE,
+deleted_at = NOW()::TIMESTAMPTZ
 WHERE deleted IS FALSE
 AND id = $1
 `
@@ -63,16 +88,20 @@ SELECT
 id, name
 FROM tag
 WHERE deleted IS FALSE
-AND LOWER(name) LIKE '%' || LOWER($1) || '%'
-ORDER BY name ASC
-OFFSET $2
-LIMIT $3
+AND (
+    LOWER(name) LIKE '%' || LOWER($1::text) || '%' ESCAPE '\'
+    OR LOWER($2::text) <% LOWER(name)
+)
+ORDER BY word_similarity(LOWER($2::text), LOWER(name)) DESC, name ASC
+OFFSET $3
+LIMIT $4
 `
 
 type GetAllTagParams struct {
-	Lower  string `json:"lower"`
-	Offset int32  `json:"offset"`
-	Limit  int32  `json:"limit"`
+	NamePattern string `json:"name_pattern"`
+	Name        string `json:"name"`
+	OffsetRows  int32  `json:"offset_rows"`
+	LimitRows   int32  `json:"limit_rows"`
 }
 
 type GetAllTagRow struct {
@@ -81,7 +110,12 @@ type GetAllTagRow struct {
 }
 
 func (q *Queries) GetAllTag(ctx context.Context, arg GetAllTagParams) ([]GetAllTagRow, error) {
-	rows, err := q.db.Query(ctx, getAllTag, arg.Lower, arg.Offset, arg.Limit)
+	rows, err := q.db.Query(ctx, getAllTag,
+		arg.NamePattern,
+		arg.Name,
+		arg.OffsetRows,
+		arg.LimitRows,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -155,6 +189,142 @@ func (q *Queries) GetTagById(ctx context.Context, id int64) (GetTagByIdRow, erro
 	return i, err
 }
 
+const listDeletedTag = `-- name: ListDeletedTag :many
+SELECT id, name, deleted_at
+FROM tag
+WHERE deleted IS TRUE
+ORDER BY deleted_at DESC NULLS LAST, id DESC
+OFFSET $1
+LIMIT $2
+`
+
+type ListDeletedTagParams struct {
+	OffsetRows int32 `json:"offset_rows"`
+	LimitRows  int32 `json:"limit_rows"`
+}
+
+type ListDeletedTagRow struct {
+	ID        int64              `json:"id"`
+	Name      string             `json:"name"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedTag(ctx context.Context, arg ListDeletedTagParams) ([]ListDeletedTagRow, error) {
+	rows, err := q.db.Query(ctx, listDeletedTag, arg.OffsetRows, arg.LimitRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedTagRow{}
+	for rows.Next() {
+		var i ListDeletedTagRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeTag = `-- name: PurgeTag :execrows
+WITH target AS (
+    SELECT id
+    FROM tag
+    WHERE deleted IS TRUE
+    AND ($1::bigint IS NULL OR id = $1)
+    AND ($2::timestamptz IS NULL OR deleted_at < $2)
+), purged_blog_tags AS (
+    DELETE FROM blog_tag WHERE tag_id IN (SELECT id FROM target)
+)
+DELETE FROM tag
+WHERE id IN (SELECT id FROM target)
+`
+
+type PurgeTagParams struct {
+	ID            pgtype.Int8        `json:"id"`
+	DeletedBefore pgtype.Timestamptz `json:"deleted_before"`
+}
+
+// Permanently removes tags in the trash, by id or deleted before a time, and detaches them from every blog
+func (q *Queries) PurgeTag(ctx context.Context, arg PurgeTagParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeTag, arg.ID, arg.DeletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreTag = `-- name: RestoreTag :one
+UPDATE tag
+SET deleted = FALSE,
+deleted_at = NULL,
+updated_at = NOW()::TIMESTAMPTZ
+WHERE deleted IS TRUE
+AND id = $1
+RETURNING id, name
+`
+
+type RestoreTagRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) RestoreTag(ctx context.Context, id int64) (RestoreTagRow, error) {
+	row := q.db.QueryRow(ctx, restoreTag, id)
+	var i RestoreTagRow
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
+const suggestTag = `-- name: SuggestTag :many
+SELECT
+id, name
+FROM tag
+WHERE deleted IS FALSE
+AND (
+    LOWER(name) LIKE LOWER($1::text) || '%' ESCAPE '\'
+    OR LOWER($2::text) <% LOWER(name)
+)
+ORDER BY LOWER(name) LIKE LOWER($1::text) || '%' ESCAPE '\' DESC,
+word_similarity(LOWER($2::text), LOWER(name)) DESC,
+name ASC
+LIMIT $3
+`
+
+type SuggestTagParams struct {
+	QueryPattern string `json:"query_pattern"`
+	Query        string `json:"query"`
+	LimitRows    int32  `json:"limit_rows"`
+}
+
+type SuggestTagRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) SuggestTag(ctx context.Context, arg SuggestTagParams) ([]SuggestTagRow, error) {
+	rows, err := q.db.Query(ctx, suggestTag, arg.QueryPattern, arg.Query, arg.LimitRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SuggestTagRow{}
+	for rows.Next() {
+		var i SuggestTagRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTag = `-- name: UpdateTag :exec
 UPDATE tag
 SET name = $2,
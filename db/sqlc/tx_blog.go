@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BlogTagChange attaches a tag to a blog, or detaches it when Deleted is set
+type BlogTagChange struct {
+	TagID   int64
+	Deleted bool
+}
+
+// CreateBlogTxParams contains the input parameters of the create blog transaction
+type CreateBlogTxParams struct {
+	CreateBlogParams
+	TagIDs []int64
+	// MediaIDs are the media items shown inline in the content
+	MediaIDs []int64
+	// EditedBy is recorded on the first revision
+	EditedBy pgtype.Int8
+}
+
+// CreateBlogTxResult is the result of the create blog transaction
+type CreateBlogTxResult struct {
+	Blog     CreateBlogRow
+	BlogTags []GetBlogTagByBlogIdRow
+}
+
+// CreateBlogTx creates a blog with its tags and first revision.
+// A new blog may take over the old URL of another blog, so a redirect from its URL is dropped.
+func (store *SQLStore) CreateBlogTx(ctx context.Context, arg CreateBlogTxParams) (CreateBlogTxResult, error) {
+	var result CreateBlogTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Blog, err = q.CreateBlog(ctx, arg.CreateBlogParams)
+		if err != nil {
+			return err
+		}
+
+		for _, tagID := range arg.TagIDs {
+			err = q.CreateBlogTag(ctx, CreateBlogTagParams{
+				BlogID: result.Blog.ID,
+				TagID:  tagID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = q.DeleteBlogRedirectByOldUrl(ctx, result.Blog.Url)
+		if err != nil {
+			return err
+		}
+
+		err = replaceBlogMedia(ctx, q, result.Blog.ID, arg.MediaIDs)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateBlogRevision(ctx, CreateBlogRevisionParams{
+			BlogID:        result.Blog.ID,
+			Title:         result.Blog.Title,
+			Content:       result.Blog.Content,
+			ContentFormat: result.Blog.ContentFormat,
+			Image:         result.Blog.Image,
+			Url:           result.Blog.Url,
+			EditedBy:      arg.EditedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.BlogTags, err = q.GetBlogTagByBlogId(ctx, result.Blog.ID)
+		return err
+	})
+
+	return result, err
+}
+
+// UpdateBlogTxParams contains the input parameters of the update blog transaction
+type UpdateBlogTxParams struct {
+	UpdateBlogParams
+	// OldUrl is kept as a redirect when the blog moves to a new URL
+	OldUrl string
+	Tags   []BlogTagChange
+	// MediaIDs are the media items shown inline in the content, they replace the previous ones
+	MediaIDs []int64
+	// EditedBy is recorded on the revision of the saved content
+	EditedBy pgtype.Int8
+}
+
+// UpdateBlogTxResult is the result of the update blog transaction
+type UpdateBlogTxResult struct {
+	Blog     GetBlogByIdRow
+	BlogTags []GetBlogTagByBlogIdRow
+}
+
+// UpdateBlogTx saves a blog, applies the tag changes, redirects its old URL and snapshots the saved content as a revision.
+// It returns ErrVersionConflict when the blog is no longer at arg.Version.
+func (store *SQLStore) UpdateBlogTx(ctx context.Context, arg UpdateBlogTxParams) (UpdateBlogTxResult, error) {
+	var result UpdateBlogTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		updated, err := q.UpdateBlog(ctx, arg.UpdateBlogParams)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrVersionConflict
+		}
+
+		if arg.OldUrl != "" && arg.OldUrl != arg.Url {
+			err = q.UpsertBlogRedirect(ctx, UpsertBlogRedirectParams{
+				OldUrl: arg.OldUrl,
+				BlogID: arg.ID,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = q.DeleteBlogRedirectByOldUrl(ctx, arg.Url)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, tag := range arg.Tags {
+			existing, err := q.GetBlogTagByBlogIdAndTagId(ctx, GetBlogTagByBlogIdAndTagIdParams{
+				BlogID: arg.ID,
+				TagID:  tag.TagID,
+			})
+			if err != nil && !errors.Is(err, ErrRecordNotFound) {
+				return err
+			}
+
+			if existing.ID == 0 && !tag.Deleted {
+				err = q.CreateBlogTag(ctx, CreateBlogTagParams{
+					BlogID: arg.ID,
+					TagID:  tag.TagID,
+				})
+			} else if existing.ID != 0 && tag.Deleted {
+				err = q.DeleteBlogTag(ctx, DeleteBlogTagParams{
+					BlogID: arg.ID,
+					TagID:  tag.TagID,
+				})
+			} else {
+				err = nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		err = replaceBlogMedia(ctx, q, arg.ID, arg.MediaIDs)
+		if err != nil {
+			return err
+		}
+
+		result.Blog, err = q.GetBlogById(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateBlogRevision(ctx, CreateBlogRevisionParams{
+			BlogID:        result.Blog.ID,
+			Title:         result.Blog.Title,
+			Content:       result.Blog.Content,
+			ContentFormat: result.Blog.ContentFormat,
+			Image:         result.Blog.Image,
+			Url:           result.Blog.Url,
+			EditedBy:      arg.EditedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.BlogTags, err = q.GetBlogTagByBlogId(ctx, result.Blog.ID)
+		return err
+	})
+
+	return result, err
+}
+
+// DeleteBlogTx moves a blog and its tags to the trash. Both share deletedAt so a restore brings the tags back.
+func (store *SQLStore) DeleteBlogTx(ctx context.Context, blogID int64, deletedAt time.Time) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteBlog(ctx, DeleteBlogParams{
+			ID:        blogID,
+			DeletedAt: deletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteBlogTagByBlogId(ctx, DeleteBlogTagByBlogIdParams{
+			BlogID:    blogID,
+			DeletedAt: deletedAt,
+		})
+	})
+}
+
+// ChangeBlogStatusTxParams contains the input parameters of the change blog status transaction
+type ChangeBlogStatusTxParams struct {
+	UpdateBlogStatusParams
+	// The review action recorded against the latest revision of the blog
+	UserID int64
+	Action string
+	Note   string
+}
+
+// ChangeBlogStatusTx moves a blog to a new status and records the review action against its latest revision,
+// so a status never changes without its review record.
+// It returns ErrRecordNotFound when the blog is no longer in arg.CurrentStatus.
+func (store *SQLStore) ChangeBlogStatusTx(ctx context.Context, arg ChangeBlogStatusTxParams) (BlogReview, error) {
+	var review BlogReview
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.UpdateBlogStatus(ctx, arg.UpdateBlogStatusParams)
+		if err != nil {
+			return err
+		}
+
+		review, err = recordBlogReview(ctx, q, CreateBlogReviewParams{
+			BlogID: arg.ID,
+			UserID: arg.UserID,
+			Action: arg.Action,
+			Note:   arg.Note,
+		})
+		return err
+	})
+
+	return review, err
+}
+
+// CreateBlogReviewTx records a review action that leaves the status alone, such as a comment,
+// against the latest revision of the blog. arg.BlogRevisionID is filled in.
+func (store *SQLStore) CreateBlogReviewTx(ctx context.Context, arg CreateBlogReviewParams) (BlogReview, error) {
+	var review BlogReview
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		review, err = recordBlogReview(ctx, q, arg)
+		return err
+	})
+
+	return review, err
+}
+
+// recordBlogReview records a review action against the latest revision of the blog, if it has one
+func recordBlogReview(ctx context.Context, q *Queries, arg CreateBlogReviewParams) (BlogReview, error) {
+	latestRevisionID, err := q.GetLatestBlogRevisionIdByBlogId(ctx, arg.BlogID)
+	if err != nil && !errors.Is(err, ErrRecordNotFound) {
+		return BlogReview{}, err
+	}
+	if err == nil {
+		arg.BlogRevisionID = pgtype.Int8{Int64: latestRevisionID, Valid: true}
+	}
+
+	return q.CreateBlogReview(ctx, arg)
+}
+
+func replaceBlogMedia(ctx context.Context, q *Queries, blogID int64, mediaIDs []int64) error {
+	// A nil slice is sent as NULL, which would keep every previous link
+	if mediaIDs == nil {
+		mediaIDs = []int64{}
+	}
+
+	return q.SetBlogMedia(ctx, SetBlogMediaParams{
+		BlogID:   blogID,
+		MediaIds: mediaIDs,
+	})
+}
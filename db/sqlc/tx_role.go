@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RolePermissionChange grants a permission to a role, or revokes it when Assigned is false
+type RolePermissionChange struct {
+	PermissionID int64
+	Assigned     bool
+}
+
+// CreateRoleTx creates a role with the permissions assigned to it
+func (store *SQLStore) CreateRoleTx(ctx context.Context, name string, permissions []RolePermissionChange) (Role, error) {
+	var role Role
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		role, err = q.CreateRole(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		for _, permission := range permissions {
+			if !permission.Assigned {
+				continue
+			}
+
+			_, err = q.CreateRolePermission(ctx, CreateRolePermissionParams{
+				RoleID:       role.ID,
+				PermissionID: permission.PermissionID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return role, err
+}
+
+// UpdateRoleTx renames a role and grants or revokes its permissions.
+// It returns ErrVersionConflict when the role is no longer at arg.Version.
+func (store *SQLStore) UpdateRoleTx(ctx context.Context, arg UpdateRoleParams, permissions []RolePermissionChange) (UpdateRoleRow, error) {
+	var role UpdateRoleRow
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		role, err = q.UpdateRole(ctx, arg)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrVersionConflict
+			}
+			return err
+		}
+
+		for _, permission := range permissions {
+			existing, err := q.GetRolePermissionByRoleIdAndPermissionId(ctx, GetRolePermissionByRoleIdAndPermissionIdParams{
+				RoleID:       arg.ID,
+				PermissionID: permission.PermissionID,
+			})
+			if err != nil && !errors.Is(err, ErrRecordNotFound) {
+				return err
+			}
+
+			if existing.ID == 0 && permission.Assigned {
+				_, err = q.CreateRolePermission(ctx, CreateRolePermissionParams{
+					RoleID:       arg.ID,
+					PermissionID: permission.PermissionID,
+				})
+			} else if existing.ID != 0 && !permission.Assigned {
+				err = q.DeleteRolePermission(ctx, DeleteRolePermissionParams{
+					RoleID:       arg.ID,
+					PermissionID: permission.PermissionID,
+				})
+			} else {
+				err = nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return role, err
+}
+
+// DeleteRoleTx moves a role and its permissions to the trash. Both share deletedAt so a restore brings the permissions back.
+func (store *SQLStore) DeleteRoleTx(ctx context.Context, roleID int64, deletedAt time.Time) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteRole(ctx, DeleteRoleParams{
+			ID:        roleID,
+			DeletedAt: deletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteRolePermissionByRoleId(ctx, DeleteRolePermissionByRoleIdParams{
+			RoleID:    roleID,
+			DeletedAt: deletedAt,
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CreateUserTx creates a user with their roles
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams, roleIDs []int64) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return createUserRoles(ctx, q, user.ID, roleIDs)
+	})
+
+	return user, err
+}
+
+// UpdateUserWithRolesTx updates a user and replaces their roles, so a failure never leaves the user without roles.
+// It returns ErrVersionConflict when the user is no longer at arg.Version.
+func (store *SQLStore) UpdateUserWithRolesTx(ctx context.Context, arg UpdateUserParams, roleIDs []int64) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.UpdateUser(ctx, arg)
+		if err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrVersionConflict
+			}
+			return err
+		}
+
+		err = q.DeleteUserRoleByUserId(ctx, DeleteUserRoleByUserIdParams{
+			UserID:    user.ID,
+			DeletedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return createUserRoles(ctx, q, user.ID, roleIDs)
+	})
+
+	return user, err
+}
+
+// DeleteUserTx moves a user and their roles to the trash. Both share deletedAt so a restore brings the roles back.
+func (store *SQLStore) DeleteUserTx(ctx context.Context, userID int64, deletedAt time.Time) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteUser(ctx, DeleteUserParams{
+			ID:        userID,
+			DeletedAt: deletedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUserRoleByUserId(ctx, DeleteUserRoleByUserIdParams{
+			UserID:    userID,
+			DeletedAt: deletedAt,
+		})
+	})
+}
+
+func createUserRoles(ctx context.Context, q *Queries, userID int64, roleIDs []int64) error {
+	for _, roleID := range roleIDs {
+		err := q.CreateUserRole(ctx, CreateUserRoleParams{
+			UserID: userID,
+			RoleID: roleID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countDeletedUser = `-- name: CountDeletedUser :one
+SELECT COUNT(1) AS count
+FROM users
+WHERE deleted IS TRUE
+`
+
+func (q *Queries) CountDeletedUser(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeletedUser)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUser = `-- name: CountUser :one
 SELECT COUNT(1) AS "UserCount" 
 FROM users 
@@ -51,7 +64,7 @@ INSERT INTO users (
   hashed_password
 ) VALUES (
   $1, $2, $3, $4, $5, $6, $7, $8
-) RETURNING id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted
+) RETURNING id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted, deleted_at, version
 `
 
 type CreateUserParams struct {
@@ -91,6 +104,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Deleted,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -98,16 +113,55 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 const deleteUser = `-- name: DeleteUser :exec
 UPDATE users
 SET deleted = True,
+deleted_at = $1::TIMESTAMPTZ,
 updated_at = NOW()
-WHERE id = $1
+WHERE id = $2
 AND deleted IS False
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
+type DeleteUserParams struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	ID        int64     `json:"id"`
+}
+
+func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) error {
+	_, err := q.db.Exec(ctx, deleteUser, arg.DeletedAt, arg.ID)
 	return err
 }
 
+const getDeletedUserById = `-- name: GetDeletedUserById :one
+SELECT id, code, username, first_name, last_name, email, deleted_at
+FROM users
+WHERE deleted IS TRUE
+AND id = $1
+LIMIT 1
+`
+
+type GetDeletedUserByIdRow struct {
+	ID        int64              `json:"id"`
+	Code      string             `json:"code"`
+	Username  string             `json:"username"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Email     string             `json:"email"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) GetDeletedUserById(ctx context.Context, id int64) (GetDeletedUserByIdRow, error) {
+	row := q.db.QueryRow(ctx, getDeletedUserById, id)
+	var i GetDeletedUserByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Username,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const getUser = `-- name: GetUser :one
 SELECT id,
 code,
@@ -116,7 +170,8 @@ last_name,
 username,
 email,
 phone,
-description
+description,
+version
 FROM users
 WHERE id = $1 
 AND deleted IS False
@@ -132,6 +187,7 @@ type GetUserRow struct {
 	Email       string      `json:"email"`
 	Phone       string      `json:"phone"`
 	Description pgtype.Text `json:"description"`
+	Version     int32       `json:"version"`
 }
 
 func (q *Queries) GetUser(ctx context.Context, id int64) (GetUserRow, error) {
@@ -146,12 +202,13 @@ func (q *Queries) GetUser(ctx context.Context, id int64) (GetUserRow, error) {
 		&i.Email,
 		&i.Phone,
 		&i.Description,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted FROM users
+SELECT id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted, deleted_at, version FROM users
 WHERE email = $1
 AND deleted IS False
 `
@@ -173,12 +230,14 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Deleted,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted FROM users
+SELECT id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted, deleted_at, version FROM users
 WHERE username = $1
 AND deleted IS False
 LIMIT 1
@@ -201,6 +260,8 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Deleted,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -219,6 +280,58 @@ func (q *Queries) GetUserHashedPassword(ctx context.Context, id int64) (string,
 	return hashed_password, err
 }
 
+const listDeletedUser = `-- name: ListDeletedUser :many
+SELECT id, code, username, first_name, last_name, email, deleted_at
+FROM users
+WHERE deleted IS TRUE
+ORDER BY deleted_at DESC NULLS LAST, id DESC
+OFFSET $1
+LIMIT $2
+`
+
+type ListDeletedUserParams struct {
+	OffsetRows int32 `json:"offset_rows"`
+	LimitRows  int32 `json:"limit_rows"`
+}
+
+type ListDeletedUserRow struct {
+	ID        int64              `json:"id"`
+	Code      string             `json:"code"`
+	Username  string             `json:"username"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Email     string             `json:"email"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedUser(ctx context.Context, arg ListDeletedUserParams) ([]ListDeletedUserRow, error) {
+	rows, err := q.db.Query(ctx, listDeletedUser, arg.OffsetRows, arg.LimitRows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedUserRow{}
+	for rows.Next() {
+		var i ListDeletedUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Username,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT id,
 code,
@@ -283,6 +396,97 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUse
 	return items, nil
 }
 
+const purgeUser = `-- name: PurgeUser :execrows
+WITH target AS (
+    SELECT u.id
+    FROM users u
+    WHERE u.deleted IS TRUE
+    AND ($1::bigint IS NULL OR u.id = $1)
+    AND ($2::timestamptz IS NULL OR u.deleted_at < $2)
+    AND NOT EXISTS (SELECT 1 FROM blog b WHERE b.author_id = u.id)
+    AND NOT EXISTS (SELECT 1 FROM blog_review br WHERE br.user_id = u.id)
+), purged_roles AS (
+    DELETE FROM user_role WHERE user_id IN (SELECT id FROM target)
+), purged_sessions AS (
+    DELETE FROM sessions WHERE user_id IN (SELECT id FROM target)
+), purged_reset_passwords AS (
+    DELETE FROM reset_password WHERE user_id IN (SELECT id FROM target)
+), purged_blog_locks AS (
+    DELETE FROM blog_lock WHERE user_id IN (SELECT id FROM target)
+), detached_blogs AS (
+    UPDATE blog SET updated_by = NULL WHERE updated_by IN (SELECT id FROM target)
+), detached_revisions AS (
+    UPDATE blog_revision SET edited_by = NULL WHERE edited_by IN (SELECT id FROM target)
+), detached_media AS (
+    UPDATE media SET uploaded_by = NULL WHERE uploaded_by IN (SELECT id FROM target)
+)
+DELETE FROM users
+WHERE id IN (SELECT id FROM target)
+`
+
+type PurgeUserParams struct {
+	ID            pgtype.Int8        `json:"id"`
+	DeletedBefore pgtype.Timestamptz `json:"deleted_before"`
+}
+
+// Permanently removes users in the trash, by id or deleted before a time. Users who still author
+// blogs or reviews are kept, their content has to be handed over or purged first.
+func (q *Queries) PurgeUser(ctx context.Context, arg PurgeUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeUser, arg.ID, arg.DeletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+WITH target AS (
+    SELECT du.id, du.deleted_at
+    FROM users du
+    WHERE du.deleted IS TRUE
+    AND du.id = $1
+), relinked_roles AS (
+    UPDATE user_role ur
+    SET deleted = FALSE,
+    deleted_at = NULL,
+    updated_at = NOW()::TIMESTAMP
+    FROM target
+    WHERE ur.user_id = target.id
+    AND ur.deleted_at = target.deleted_at
+)
+UPDATE users u
+SET deleted = FALSE,
+deleted_at = NULL,
+updated_at = NOW()
+FROM target
+WHERE u.id = target.id
+RETURNING u.id, u.code, u.username, u.first_name, u.last_name, u.email
+`
+
+type RestoreUserRow struct {
+	ID        int64  `json:"id"`
+	Code      string `json:"code"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+// Roles removed together with the user come back with it
+func (q *Queries) RestoreUser(ctx context.Context, id int64) (RestoreUserRow, error) {
+	row := q.db.QueryRow(ctx, restoreUser, id)
+	var i RestoreUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Username,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
@@ -293,10 +497,12 @@ SET
   description = COALESCE($5, description),
   -- hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password),
   -- password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at),
+  version = version + 1,
   updated_at = NOW()::TIMESTAMP
 WHERE
   id = $6 AND deleted IS False
-RETURNING id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted
+  AND version = $7
+RETURNING id, code, username, first_name, last_name, email, phone, description, hashed_password, password_changed_at, created_at, updated_at, deleted, deleted_at, version
 `
 
 type UpdateUserParams struct {
@@ -306,8 +512,10 @@ type UpdateUserParams struct {
 	Phone       pgtype.Text `json:"phone"`
 	Description pgtype.Text `json:"description"`
 	UserID      int64       `json:"user_id"`
+	Version     int32       `json:"version"`
 }
 
+// No row is returned when the user was saved by someone else since the caller loaded version
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
 	row := q.db.QueryRow(ctx, updateUser,
 		arg.FirstName,
@@ -316,6 +524,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Phone,
 		arg.Description,
 		arg.UserID,
+		arg.Version,
 	)
 	var i User
 	err := row.Scan(
@@ -332,6 +541,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Deleted,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createUserRole = `-- name: CreateUserRole :exec
@@ -29,11 +30,18 @@ func (q *Queries) CreateUserRole(ctx context.Context, arg CreateUserRoleParams)
 const deleteUserRoleByUserId = `-- name: DeleteUserRoleByUserId :exec
 UPDATE user_role
 SET deleted = True,
+deleted_at = $1::TIMESTAMPTZ,
 updated_at = NOW()::TIMESTAMP
-WHERE user_id = $1
+WHERE user_id = $2
+AND deleted IS False
 `
 
-func (q *Queries) DeleteUserRoleByUserId(ctx context.Context, userID int64) error {
-	_, err := q.db.Exec(ctx, deleteUserRoleByUserId, userID)
+type DeleteUserRoleByUserIdParams struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	UserID    int64     `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserRoleByUserId(ctx context.Context, arg DeleteUserRoleByUserIdParams) error {
+	_, err := q.db.Exec(ctx, deleteUserRoleByUserId, arg.DeletedAt, arg.UserID)
 	return err
 }
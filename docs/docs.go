@@ -25,7 +25,7 @@ const docTemplate = `{
     "paths": {
         "/api/blog": {
             "get": {
-                "description": "Get All Blog",
+                "description": "List blogs with search, filters and sorting. facets.tags counts the matching blogs per tag.\nSend cursor instead of page_id to page by publish date with an opaque cursor, empty for the first page: the response carries\nnext_cursor (empty on the last page) and total only when with_count is set, see jsonResponseWithCursorFacets.\nPosts that were never published are ordered by their creation date.",
                 "consumes": [
                     "application/json"
                 ],
@@ -39,30 +39,122 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Blog Name",
+                        "description": "Search title and content: \\",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Blog Name (deprecated, use q)",
                         "name": "name",
                         "in": "query"
                     },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Tag Names, repeated or comma separated",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Match any (default) or all of the tags",
+                        "name": "tag_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author Code",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Blog Status (requires view_blog for anything but published)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or after (YYYY-MM-DD or RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created on or before (YYYY-MM-DD or RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Published on or after (YYYY-MM-DD or RFC 3339)",
+                        "name": "published_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Published on or before (YYYY-MM-DD or RFC 3339)",
+                        "name": "published_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "relevance",
+                            "newest",
+                            "oldest",
+                            "title",
+                            "most_viewed"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, relevance by default when searching and newest otherwise",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Page ID",
+                        "description": "Page ID, required unless cursor is sent",
                         "name": "page_id",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Page Size",
+                        "description": "Page Size (max 50)",
                         "name": "page_size",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, ordered by publish date",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include total when paging with cursor",
+                        "name": "with_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields to return per blog, e.g. id,title,url,excerpt,reading_time_minutes. Every field but content by default, content is only sent when listed",
+                        "name": "fields",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
+                            "$ref": "#/definitions/api.jsonResponseWithFacets"
                         }
                     }
                 }
@@ -93,11 +185,35 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.UpdateBlogRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version the changes are based on, instead of version in the body",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the saved blog"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "The blog was changed by someone else, data holds the current copy",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Neither version nor If-Match was sent",
                         "schema": {
                             "$ref": "#/definitions/api.jsonResponse"
                         }
@@ -172,6 +288,24 @@ const docTemplate = `{
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the blog, send it back as If-Match when saving"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "The blog has been deleted",
                         "schema": {
                             "$ref": "#/definitions/api.jsonResponse"
                         }
@@ -181,7 +315,7 @@ const docTemplate = `{
         },
         "/api/blog/tag": {
             "get": {
-                "description": "Get All Blog With Tag",
+                "description": "Deprecated: use GET /api/blog, which accepts the same parameters including multiple tags",
                 "consumes": [
                     "application/json"
                 ],
@@ -192,10 +326,11 @@ const docTemplate = `{
                     "Blog"
                 ],
                 "summary": "Get All Blog With Tag",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Blog Title",
+                        "description": "Blog Title (deprecated, use q)",
                         "name": "title",
                         "in": "query"
                     },
@@ -224,7 +359,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
+                            "$ref": "#/definitions/api.jsonResponseWithFacets"
                         }
                     }
                 }
@@ -267,9 +402,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/blog/{url}": {
-            "get": {
-                "description": "Get Blog By URL",
+        "/api/blog/{id}/lock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lock a blog for editing, or renew the caller's lock as a heartbeat. The lock expires when the heartbeats stop.",
                 "consumes": [
                     "application/json"
                 ],
@@ -277,16 +417,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Blog"
+                    "Blog Lock"
                 ],
-                "summary": "Get Blog By URL",
+                "summary": "Acquire Blog Lock",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Blog URL",
-                        "name": "url",
+                        "type": "integer",
+                        "description": "Blog ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Take over the lock from another user, needs edit_blog",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -295,13 +441,22 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.jsonResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Someone else is editing the blog, data holds their lock",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/api/forgot-password": {
-            "post": {
-                "description": "Forgot password",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Release the caller's lock on a blog. With force the lock of another user is broken, which needs edit_blog.",
                 "consumes": [
                     "application/json"
                 ],
@@ -309,31 +464,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Blog Lock"
                 ],
-                "summary": "Forgot Password",
+                "summary": "Release Blog Lock",
                 "parameters": [
                     {
-                        "description": "Email information",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.forgotPasswordRequest"
-                        }
+                        "type": "integer",
+                        "description": "Blog ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Break the lock of another user, needs edit_blog",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Someone else holds the lock, data holds their lock",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/login": {
-            "post": {
-                "description": "Login to the system",
+        "/api/blog/{url}": {
+            "get": {
+                "description": "Get Blog By URL",
                 "consumes": [
                     "application/json"
                 ],
@@ -341,38 +508,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Blog"
                 ],
-                "summary": "Login",
+                "summary": "Get Blog By URL",
                 "parameters": [
                     {
-                        "description": "Login information",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.loginUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Blog URL",
+                        "name": "url",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.loginUserResponse"
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "301": {
+                        "description": "The blog moved to the URL in data.url, also sent as Location",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "The blog has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
                         }
                     }
                 }
             }
         },
-        "/api/permission_group": {
+        "/api/blog_review": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get All Permission Group",
+                "description": "Get the review history and reviewer notes of a blog, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -380,15 +563,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Permission Group"
+                    "Blog Review"
                 ],
-                "summary": "Get All Permission Group",
+                "summary": "Get All Blog Review",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role ID",
-                        "name": "role_id",
-                        "in": "query"
+                        "description": "Blog ID",
+                        "name": "blog_id",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -401,14 +585,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/profile": {
-            "get": {
+        "/api/blog_review/approve": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get Profile",
+                "description": "Approve a blog in review so it can be published. Authors cannot approve their own posts.",
                 "consumes": [
                     "application/json"
                 ],
@@ -416,25 +600,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Blog Review"
+                ],
+                "summary": "Approve Blog",
+                "parameters": [
+                    {
+                        "description": "Blog and optional reviewer note",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BlogReviewRequest"
+                        }
+                    }
                 ],
-                "summary": "Get Profile",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.userResponse"
+                            "$ref": "#/definitions/api.jsonResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/blog_review/comment": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update Profile",
+                "description": "Attach a note to the current version of a blog without changing its status",
                 "consumes": [
                     "application/json"
                 ],
@@ -442,17 +639,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Blog Review"
                 ],
-                "summary": "Update Profile",
+                "summary": "Comment On Blog",
                 "parameters": [
                     {
-                        "description": "Update information",
+                        "description": "Blog and note",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.updateUserRequest"
+                            "$ref": "#/definitions/api.RequestChangesBlogReviewRequest"
                         }
                     }
                 ],
@@ -460,20 +657,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.userResponse"
+                            "$ref": "#/definitions/api.jsonResponse"
                         }
                     }
                 }
             }
         },
-        "/api/profile/change_password": {
-            "put": {
+        "/api/blog_review/publish": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Change Password",
+                "description": "Publish an approved blog now, or schedule it when publish_at (from the request or saved on the blog) is in the future",
                 "consumes": [
                     "application/json"
                 ],
@@ -481,31 +678,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Blog Review"
                 ],
-                "summary": "Change Password",
+                "summary": "Publish Blog",
                 "parameters": [
                     {
-                        "description": "Change Password",
+                        "description": "Blog and optional publish time",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.ChangePasswordRequest"
+                            "$ref": "#/definitions/api.PublishBlogReviewRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/reset_password": {
-            "get": {
-                "description": "Get reset password detail",
+        "/api/blog_review/request_changes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a blog in review back to its author as a draft together with the reviewer notes",
                 "consumes": [
                     "application/json"
                 ],
@@ -513,66 +717,1277 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Blog Review"
                 ],
-                "summary": "Reset Password Detail",
+                "summary": "Request Changes On Blog",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Token",
-                        "name": "token",
-                        "in": "query",
-                        "required": true
+                        "description": "Blog and reviewer notes",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RequestChangesBlogReviewRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.resetPasswordDetailResponse"
+                            "$ref": "#/definitions/api.jsonResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/blog_review/submit": {
             "post": {
-                "description": "Reset password",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move a draft to in_review so a reviewer can approve it or request changes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Review"
+                ],
+                "summary": "Submit Blog For Review",
+                "parameters": [
+                    {
+                        "description": "Blog and optional note for the reviewer",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.BlogReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/blog_revision": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the revision history of a blog, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Revision"
+                ],
+                "summary": "Get All Blog Revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Blog ID",
+                        "name": "blog_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page ID",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/blog_revision/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Line-level diff of the content of two revisions of the same blog. HTML is compared with every block on a line of its own. Only for those who may edit the blog.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Revision"
+                ],
+                "summary": "Diff Blog Revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Old Blog Revision ID",
+                        "name": "from_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "New Blog Revision ID",
+                        "name": "to_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/blog_revision/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make a revision the current version of its blog. The restore itself is recorded as a new revision. An approved or scheduled blog goes back to review, restoring onto a published blog needs publish_blog.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Revision"
+                ],
+                "summary": "Restore Blog Revision",
+                "parameters": [
+                    {
+                        "description": "Revision to restore",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RestoreBlogRevisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/blog_revision/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a single blog revision including its content",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blog Revision"
+                ],
+                "summary": "Get Blog Revision By ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Blog Revision ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/forgot-password": {
+            "post": {
+                "description": "Forgot password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Forgot Password",
+                "parameters": [
+                    {
+                        "description": "Email information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.forgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/login": {
+            "post": {
+                "description": "Login to the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "description": "Login information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/media": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Search the media library by file name, alt text and caption, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Get All Media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type, e.g. image/png",
+                        "name": "content_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page ID",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the alt text and caption of a media item",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Update Media",
+                "parameters": [
+                    {
+                        "description": "Media information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateMediaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a JPEG, PNG, WebP, GIF, AVIF or SVG image as multipart/form-data. The type is detected from the content and SVGs are sanitized. The file is streamed to object storage and its id can be sent as image_media_id when saving a blog.\nUploading a file that is already in the library returns the existing media item.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Upload Media",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "The file is over the size limit of its type",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "The file is not a JPEG, PNG, WebP, GIF, AVIF or SVG image",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "The image is damaged or over the pixel limit of its type",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/media/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a media item and the blogs using it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Get Media By ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a media item and its file. Media used by a blog, including blogs in the trash, cannot be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Delete Media",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The media is in use, data lists the blogs using it",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/permission_group": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get All Permission Group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permission Group"
+                ],
+                "summary": "Get All Permission Group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "role_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get Profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Get Profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the profile, send it back as If-Match when saving"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update Profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Update Profile",
+                "parameters": [
+                    {
+                        "description": "Update information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateProfileRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version the changes are based on, instead of version in the body",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the saved profile"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "The profile was changed in the meantime, data holds the current copy",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Neither version nor If-Match was sent",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/change_password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change Password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Change Password",
+                "parameters": [
+                    {
+                        "description": "Change Password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/reset_password": {
+            "get": {
+                "description": "Get reset password detail",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset Password Detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.resetPasswordDetailResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Reset password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset Password",
+                "parameters": [
+                    {
+                        "description": "Reset password information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.resetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/role": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get All Role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Get All Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role Name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page ID",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update Role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Update Role",
+                "parameters": [
+                    {
+                        "description": "Role Information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateRoleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version the changes are based on, instead of version in the body",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the saved role"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The role was changed by someone else, data holds the current copy",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Neither version nor If-Match was sent",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create Role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Create Role",
+                "parameters": [
+                    {
+                        "description": "Role Information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/role/dropdownlist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get Role For Drop Down List",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Get Role For Drop Down List",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/role/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get Role By ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Get Role By ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the role, send it back as If-Match when saving"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete Role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Delete Role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/search/suggest": {
+            "get": {
+                "description": "Autocomplete for the search box: blog titles and tag names starting with or similar to q, tolerant of typos",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Search Suggest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Suggestions per kind (default 5, max 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/signup": {
+            "post": {
+                "description": "Create a new user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Sign up",
+                "parameters": [
+                    {
+                        "description": "User information",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/storage/{key}": {
+            "get": {
+                "description": "Serve an uploaded file from the storage backend, used when the backend is not reachable by clients itself (filesystem and memory)",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Storage"
+                ],
+                "summary": "Get Stored File",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Object key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tag": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get All Tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tag"
+                ],
+                "summary": "Get All Tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag Name (typo tolerant)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page ID",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update Tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Tag"
                 ],
-                "summary": "Reset Password",
+                "summary": "Update Tag",
                 "parameters": [
                     {
-                        "description": "Reset password information",
+                        "description": "Update Tag",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.resetPasswordRequest"
+                            "$ref": "#/definitions/api.UpdateTagRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
-                        "schema": {}
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create Tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tag"
+                ],
+                "summary": "Create Tag",
+                "parameters": [
+                    {
+                        "description": "Create Tag",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/role": {
+        "/api/tag/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get All Role",
+                "description": "Get Tag By ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -580,28 +1995,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Tag"
                 ],
-                "summary": "Get All Role",
+                "summary": "Get Tag By ID",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Role Name",
-                        "name": "name",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
-                        "description": "Page ID",
-                        "name": "page_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page Size",
-                        "name": "page_size",
-                        "in": "query",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -609,18 +2011,18 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
+                            "$ref": "#/definitions/api.jsonResponse"
                         }
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update Role",
+                "description": "Delete Tag",
                 "consumes": [
                     "application/json"
                 ],
@@ -628,17 +2030,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Tag"
                 ],
-                "summary": "Update Role",
+                "summary": "Delete Tag",
                 "parameters": [
                     {
-                        "description": "Role Information",
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tokens/renew_access": {
+            "post": {
+                "description": "Renew Access Token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Renew Access Token",
+                "parameters": [
+                    {
+                        "description": "Refresh Token",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.UpdateRoleRequest"
+                            "$ref": "#/definitions/api.renewAccessTokenRequest"
                         }
                     }
                 ],
@@ -646,18 +2080,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.jsonResponse"
+                            "$ref": "#/definitions/api.renewAccessTokenResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/trash/blog": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create Role",
+                "description": "List deleted blogs, most recently deleted first",
                 "consumes": [
                     "application/json"
                 ],
@@ -665,38 +2101,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Trash"
                 ],
-                "summary": "Create Role",
+                "summary": "Get All Trash Blog",
                 "parameters": [
                     {
-                        "description": "Role Information",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.CreateRoleRequest"
-                        }
+                        "type": "integer",
+                        "description": "Page ID",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.jsonResponse"
+                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
                         }
                     }
                 }
             }
         },
-        "/api/role/dropdownlist": {
-            "get": {
+        "/api/trash/blog/restore": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get Role For Drop Down List",
+                "description": "Restore a deleted blog together with the tags removed along with it. If another blog took its URL in the meantime, a new URL is generated from the title.",
                 "consumes": [
                     "application/json"
                 ],
@@ -704,9 +2145,20 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Trash"
+                ],
+                "summary": "Restore Trash Blog",
+                "parameters": [
+                    {
+                        "description": "Blog to restore",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RestoreTrashRequest"
+                        }
+                    }
                 ],
-                "summary": "Get Role For Drop Down List",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -717,14 +2169,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/role/{id}": {
-            "get": {
+        "/api/trash/blog/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get Role By ID",
+                "description": "Permanently delete a blog in the trash with its tags, revisions, reviews and redirects",
                 "consumes": [
                     "application/json"
                 ],
@@ -732,13 +2184,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Trash"
                 ],
-                "summary": "Get Role By ID",
+                "summary": "Purge Trash Blog",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role ID",
+                        "description": "Blog ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -752,14 +2204,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/trash/role": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete Role",
+                "description": "List deleted roles, most recently deleted first",
                 "consumes": [
                     "application/json"
                 ],
@@ -767,15 +2221,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Trash"
                 ],
-                "summary": "Delete Role",
+                "summary": "Get All Trash Role",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Page ID",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "page_size",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -783,15 +2244,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.jsonResponse"
+                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
                         }
                     }
                 }
             }
         },
-        "/api/signup": {
+        "/api/trash/role/restore": {
             "post": {
-                "description": "Create a new user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore a deleted role together with the permissions removed along with it",
                 "consumes": [
                     "application/json"
                 ],
@@ -799,17 +2265,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Trash"
                 ],
-                "summary": "Sign up",
+                "summary": "Restore Trash Role",
                 "parameters": [
                     {
-                        "description": "User information",
+                        "description": "Role to restore",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.createUserRequest"
+                            "$ref": "#/definitions/api.RestoreTrashRequest"
                         }
                     }
                 ],
@@ -823,14 +2289,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tag": {
-            "get": {
+        "/api/trash/role/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get All Tag",
+                "description": "Permanently delete a role in the trash and take it away from every user",
                 "consumes": [
                     "application/json"
                 ],
@@ -838,16 +2304,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tag"
+                    "Trash"
                 ],
-                "summary": "Get All Tag",
+                "summary": "Purge Trash Role",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Tag Name",
-                        "name": "name",
-                        "in": "query"
-                    },
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trash/tag": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List deleted tags, most recently deleted first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Get All Trash Tag",
+                "parameters": [
                     {
                         "type": "integer",
                         "description": "Page ID",
@@ -871,14 +2368,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/trash/tag/restore": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update Tag",
+                "description": "Restore a deleted tag. Blogs that still carry the tag show it again.",
                 "consumes": [
                     "application/json"
                 ],
@@ -886,17 +2385,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tag"
+                    "Trash"
                 ],
-                "summary": "Update Tag",
+                "summary": "Restore Trash Tag",
                 "parameters": [
                     {
-                        "description": "Update Tag",
+                        "description": "Tag to restore",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.UpdateTagRequest"
+                            "$ref": "#/definitions/api.RestoreTrashRequest"
                         }
                     }
                 ],
@@ -908,14 +2407,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/trash/tag/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create Tag",
+                "description": "Permanently delete a tag in the trash and detach it from every blog",
                 "consumes": [
                     "application/json"
                 ],
@@ -923,18 +2424,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tag"
+                    "Trash"
                 ],
-                "summary": "Create Tag",
+                "summary": "Purge Trash Tag",
                 "parameters": [
                     {
-                        "description": "Create Tag",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.CreateTagRequest"
-                        }
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -947,14 +2446,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tag/{id}": {
+        "/api/trash/user": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get Tag By ID",
+                "description": "List deleted users, most recently deleted first",
                 "consumes": [
                     "application/json"
                 ],
@@ -962,15 +2461,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tag"
+                    "Trash"
                 ],
-                "summary": "Get Tag By ID",
+                "summary": "Get All Trash User",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Page ID",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "page_size",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -978,18 +2484,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.jsonResponse"
+                            "$ref": "#/definitions/api.jsonResponseWithPaginate"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/trash/user/restore": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete Tag",
+                "description": "Restore a deleted user together with the roles removed along with them",
                 "consumes": [
                     "application/json"
                 ],
@@ -997,16 +2505,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tag"
+                    "Trash"
                 ],
-                "summary": "Delete Tag",
+                "summary": "Restore Trash User",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Tag ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "User to restore",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RestoreTrashRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1019,9 +2529,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tokens/renew_access": {
-            "post": {
-                "description": "Renew Access Token",
+        "/api/trash/user/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently delete a user in the trash with their roles, sessions and password resets. Users who still author blogs or reviews cannot be purged.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1029,25 +2544,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Trash"
                 ],
-                "summary": "Renew Access Token",
+                "summary": "Purge Trash User",
                 "parameters": [
                     {
-                        "description": "Refresh Token",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.renewAccessTokenRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.renewAccessTokenResponse"
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The user still authors blogs or reviews",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
                         }
                     }
                 }
@@ -1122,11 +2641,41 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.updateUserRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version the changes are based on, instead of version in the body",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the saved user"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The user was changed by someone else, data holds the current copy",
+                        "schema": {
+                            "$ref": "#/definitions/api.jsonResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Neither version nor If-Match was sent",
                         "schema": {
                             "$ref": "#/definitions/api.jsonResponse"
                         }
@@ -1166,6 +2715,12 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/api.jsonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user, send it back as If-Match when saving"
+                            }
                         }
                     }
                 }
@@ -1232,6 +2787,21 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "api.BlogReviewRequest": {
+            "type": "object",
+            "required": [
+                "blog_id"
+            ],
+            "properties": {
+                "blog_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
         "api.BlogTagRequest": {
             "type": "object",
             "required": [
@@ -1278,9 +2848,7 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "content",
-                "image",
-                "title",
-                "url"
+                "title"
             ],
             "properties": {
                 "blog_tags": {
@@ -1292,14 +2860,43 @@ const docTemplate = `{
                 "content": {
                     "type": "string"
                 },
+                "content_format": {
+                    "description": "html when empty",
+                    "type": "string",
+                    "enum": [
+                        "markdown",
+                        "html"
+                    ]
+                },
+                "excerpt": {
+                    "type": "string",
+                    "maxLength": 500
+                },
                 "image": {
+                    "description": "base64 data URL, prefer image_media_id",
+                    "type": "string"
+                },
+                "image_media_id": {
+                    "description": "uploaded through POST /api/media",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "publish_at": {
                     "type": "string"
                 },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft"
+                    ]
+                },
                 "title": {
                     "type": "string"
                 },
                 "url": {
-                    "type": "string"
+                    "description": "generated from the title when empty",
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
@@ -1331,14 +2928,70 @@ const docTemplate = `{
                 }
             }
         },
+        "api.PublishBlogReviewRequest": {
+            "type": "object",
+            "required": [
+                "blog_id"
+            ],
+            "properties": {
+                "blog_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "note": {
+                    "type": "string"
+                },
+                "publish_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RequestChangesBlogReviewRequest": {
+            "type": "object",
+            "required": [
+                "blog_id",
+                "note"
+            ],
+            "properties": {
+                "blog_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RestoreBlogRevisionRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "api.RestoreTrashRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
         "api.UpdateBlogRequest": {
             "type": "object",
             "required": [
                 "content",
                 "id",
-                "image",
-                "title",
-                "url"
+                "title"
             ],
             "properties": {
                 "blog_tags": {
@@ -1350,18 +3003,76 @@ const docTemplate = `{
                 "content": {
                     "type": "string"
                 },
+                "content_format": {
+                    "description": "current format when empty",
+                    "type": "string",
+                    "enum": [
+                        "markdown",
+                        "html"
+                    ]
+                },
+                "excerpt": {
+                    "type": "string",
+                    "maxLength": 500
+                },
                 "id": {
                     "type": "integer",
                     "minimum": 1
                 },
                 "image": {
+                    "description": "image URL or base64 data URL, prefer image_media_id",
+                    "type": "string"
+                },
+                "image_media_id": {
+                    "description": "uploaded through POST /api/media",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "publish_at": {
                     "type": "string"
                 },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "in_review",
+                        "approved",
+                        "scheduled",
+                        "published",
+                        "archived"
+                    ]
+                },
                 "title": {
                     "type": "string"
                 },
                 "url": {
+                    "description": "current URL when empty, an old URL redirects to the new one",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "version": {
+                    "description": "the version the changes are based on, required unless sent as If-Match",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "api.UpdateMediaRequest": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "alt_text": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "caption": {
                     "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
@@ -1384,6 +3095,11 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/api.getAllPermissionGroupData"
                     }
+                },
+                "version": {
+                    "description": "the version the changes are based on, required unless sent as If-Match",
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
@@ -1476,6 +3192,25 @@ const docTemplate = `{
                 }
             }
         },
+        "api.jsonResponseWithFacets": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "did_you_mean": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "facets": {},
+                "message": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "api.jsonResponseWithPaginate": {
             "type": "object",
             "properties": {
@@ -1585,6 +3320,31 @@ const docTemplate = `{
                 }
             }
         },
+        "api.updateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "the version the changes are based on, required unless sent as If-Match",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
         "api.updateUserRequest": {
             "type": "object",
             "required": [
@@ -1615,6 +3375,11 @@ const docTemplate = `{
                     "items": {
                         "type": "integer"
                     }
+                },
+                "version": {
+                    "description": "the version the changes are based on, required unless sent as If-Match",
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
@@ -3,9 +3,13 @@ package main
 import (
 	"context"
 	"os"
+	"time"
 
 	"blog-go-api/api"
 	db "blog-go-api/db/sqlc"
+	"blog-go-api/scheduler"
+	"blog-go-api/search"
+	"blog-go-api/storage"
 
 	"blog-go-api/util"
 
@@ -20,7 +24,7 @@ import (
 
 // main is the entry point of the application.
 // It loads the configuration, establishes a connection to the database,
-// runs database migrations, creates a new store, and starts the Gin server.
+// runs database migrations, creates a new store and the storage backend, starts the background scheduler and the Gin server.
 
 // @title Blog Go API
 // @version 1.0
@@ -45,6 +49,13 @@ func main() {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
+	// Load the full Thai lexicon for search segmentation when one is configured.
+	if config.SearchThaiDictionary != "" {
+		if err := search.LoadThaiDictionary(config.SearchThaiDictionary); err != nil {
+			log.Fatal().Err(err).Msg("cannot load thai dictionary")
+		}
+	}
+
 	// Establish a connection pool to the database.
 	connPool, err := pgxpool.New(context.Background(), config.DBSource)
 	if err != nil {
@@ -57,8 +68,18 @@ func main() {
 	// Create a new store using the connection pool.
 	store := db.NewStore(connPool)
 
-	// Start the Gin server with the given configuration and store.
-	runGinServer(config, store)
+	// Start the background scheduler that publishes posts when their publish_at arrives,
+	// keeps the blog search index up to date and empties the trash.
+	go runScheduler(context.Background(), config, store)
+
+	// Create the storage backend for uploaded files selected by STORAGE_BACKEND.
+	backend, err := storage.New(config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create storage backend")
+	}
+
+	// Start the Gin server with the given configuration, store and storage backend.
+	runGinServer(config, store, backend)
 }
 
 func runDBMigration(migrationURL string, dbSource string) {
@@ -74,8 +95,20 @@ func runDBMigration(migrationURL string, dbSource string) {
 	log.Info().Msg("db migrated successfully")
 }
 
-func runGinServer(config util.Config, store db.Store) {
-	server, err := api.NewServer(config, store)
+func runScheduler(ctx context.Context, config util.Config, store db.Store) {
+	interval := config.SchedulerInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	// Deleted content stays in the trash for TRASH_RETENTION_DAYS, 0 keeps it forever
+	trashRetention := time.Duration(config.TrashRetentionDays) * 24 * time.Hour
+
+	scheduler.NewScheduler(store, interval, trashRetention, time.Now).Start(ctx)
+}
+
+func runGinServer(config util.Config, store db.Store, backend storage.Backend) {
+	server, err := api.NewServer(config, store, backend)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create server")
 	}
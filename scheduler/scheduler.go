@@ -0,0 +1,181 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"blog-go-api/content"
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/search"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// Clock returns the current time. The scheduler reads time only through its clock
+// so callers can drive it with a fixed or simulated time.
+type Clock func() time.Time
+
+// searchReindexBatchSize caps how many posts are re-indexed on a single tick
+const searchReindexBatchSize = 100
+
+// Scheduler runs the background jobs of the API on a fixed interval.
+// Every job is driven by database state, so a restart simply picks up
+// whatever became due while the process was down.
+type Scheduler struct {
+	store          db.Store
+	interval       time.Duration
+	trashRetention time.Duration
+	clock          Clock
+}
+
+// NewScheduler creates a new scheduler. Items stay in the trash for trashRetention,
+// zero or less keeps them until they are purged by hand. A nil clock falls back to time.Now.
+func NewScheduler(store db.Store, interval time.Duration, trashRetention time.Duration, clock Clock) *Scheduler {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &Scheduler{
+		store:          store,
+		interval:       interval,
+		trashRetention: trashRetention,
+		clock:          clock,
+	}
+}
+
+// Start runs every job once immediately and then on each tick until ctx is cancelled.
+func (scheduler *Scheduler) Start(ctx context.Context) {
+	log.Info().Dur("interval", scheduler.interval).Msg("scheduler started")
+
+	ticker := time.NewTicker(scheduler.interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce executes a single pass of every job.
+func (scheduler *Scheduler) RunOnce(ctx context.Context) {
+	if _, err := scheduler.PublishScheduledBlogs(ctx); err != nil {
+		log.Error().Err(err).Msg("cannot publish scheduled blogs")
+	}
+
+	if _, err := scheduler.ReindexBlogSearch(ctx); err != nil {
+		log.Error().Err(err).Msg("cannot reindex blog search")
+	}
+
+	if _, err := scheduler.PurgeExpiredTrash(ctx); err != nil {
+		log.Error().Err(err).Msg("cannot purge expired trash")
+	}
+}
+
+// PublishScheduledBlogs flips every scheduled post whose publish_at has passed to published
+// and returns the IDs of the posts that went live.
+func (scheduler *Scheduler) PublishScheduledBlogs(ctx context.Context) ([]int64, error) {
+	ids, err := scheduler.store.PublishScheduledBlog(ctx, scheduler.clock())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		log.Info().Int64("blog_id", id).Msg("scheduled blog published")
+	}
+
+	return ids, nil
+}
+
+// ReindexBlogSearch rebuilds the rendered content, search vector, excerpt and reading stats of posts indexed with another search.IndexVersion,
+// a batch per tick, and returns how many posts were re-indexed.
+// Posts saved in the meantime already carry the current version and are left alone.
+func (scheduler *Scheduler) ReindexBlogSearch(ctx context.Context) (int, error) {
+	version := search.IndexVersion()
+
+	blogs, err := scheduler.store.GetBlogPendingSearchIndex(ctx, db.GetBlogPendingSearchIndexParams{
+		SearchVersion: version,
+		LimitRows:     searchReindexBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, blog := range blogs {
+		contentHTML, err := content.Render(blog.Content, blog.ContentFormat)
+		if err != nil {
+			return 0, err
+		}
+
+		summary := content.Summarize(contentHTML)
+
+		err = scheduler.store.UpdateBlogSearchIndex(ctx, db.UpdateBlogSearchIndexParams{
+			ID:                 blog.ID,
+			ContentHtml:        contentHTML,
+			AutoExcerpt:        summary.Excerpt,
+			WordCount:          summary.WordCount,
+			ReadingTimeMinutes: summary.ReadingTimeMinutes,
+			SearchTitle:        search.IndexText(blog.Title),
+			SearchContent:      search.IndexText(contentHTML),
+			SearchVersion:      version,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(blogs) > 0 {
+		log.Info().Int("count", len(blogs)).Msg("blog search reindexed")
+	}
+
+	return len(blogs), nil
+}
+
+// PurgeExpiredTrash permanently deletes blogs, tags, roles and users that have been in the trash
+// longer than the retention and returns how many were purged.
+// Blogs go first so users whose posts were all purged can follow on the same tick.
+func (scheduler *Scheduler) PurgeExpiredTrash(ctx context.Context) (int64, error) {
+	if scheduler.trashRetention <= 0 {
+		return 0, nil
+	}
+
+	deletedBefore := pgtype.Timestamptz{Time: scheduler.clock().Add(-scheduler.trashRetention), Valid: true}
+
+	blogs, err := scheduler.store.PurgeBlog(ctx, db.PurgeBlogParams{DeletedBefore: deletedBefore})
+	if err != nil {
+		return 0, err
+	}
+
+	tags, err := scheduler.store.PurgeTag(ctx, db.PurgeTagParams{DeletedBefore: deletedBefore})
+	if err != nil {
+		return 0, err
+	}
+
+	roles, err := scheduler.store.PurgeRole(ctx, db.PurgeRoleParams{DeletedBefore: deletedBefore})
+	if err != nil {
+		return 0, err
+	}
+
+	users, err := scheduler.store.PurgeUser(ctx, db.PurgeUserParams{DeletedBefore: deletedBefore})
+	if err != nil {
+		return 0, err
+	}
+
+	total := blogs + tags + roles + users
+	if total > 0 {
+		log.Info().
+			Int64("blogs", blogs).
+			Int64("tags", tags).
+			Int64("roles", roles).
+			Int64("users", users).
+			Msg("expired trash purged")
+	}
+
+	return total, nil
+}
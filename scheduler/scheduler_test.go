@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	db "blog-go-api/db/sqlc"
+	"blog-go-api/search"
+)
+
+// fakeBlog is the part of a blog the scheduler jobs look at
+type fakeBlog struct {
+	status        string
+	publishAt     time.Time
+	deletedAt     time.Time // zero while the blog is not in the trash
+	content       string
+	searchContent string
+	searchVersion int32
+}
+
+// fakeStore keeps blogs in memory and answers the queries of the scheduler the way the database does.
+// Any other query panics on the nil embedded Store.
+type fakeStore struct {
+	db.Store
+	blogs map[int64]*fakeBlog
+}
+
+func (store *fakeStore) PublishScheduledBlog(ctx context.Context, dueAt time.Time) ([]int64, error) {
+	ids := []int64{}
+	for id, blog := range store.blogs {
+		if blog.status == "scheduled" && blog.deletedAt.IsZero() && !blog.publishAt.After(dueAt) {
+			blog.status = "published"
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (store *fakeStore) GetBlogPendingSearchIndex(ctx context.Context, arg db.GetBlogPendingSearchIndexParams) ([]db.GetBlogPendingSearchIndexRow, error) {
+	rows := []db.GetBlogPendingSearchIndexRow{}
+	for id, blog := range store.blogs {
+		if blog.searchVersion != arg.SearchVersion {
+			rows = append(rows, db.GetBlogPendingSearchIndexRow{ID: id, Content: blog.content, ContentFormat: "html"})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	if len(rows) > int(arg.LimitRows) {
+		rows = rows[:arg.LimitRows]
+	}
+	return rows, nil
+}
+
+func (store *fakeStore) UpdateBlogSearchIndex(ctx context.Context, arg db.UpdateBlogSearchIndexParams) error {
+	if blog := store.blogs[arg.ID]; blog != nil && blog.searchVersion != arg.SearchVersion {
+		blog.searchContent = arg.SearchContent
+		blog.searchVersion = arg.SearchVersion
+	}
+	return nil
+}
+
+func (store *fakeStore) PurgeBlog(ctx context.Context, arg db.PurgeBlogParams) (int64, error) {
+	var purged int64
+	for id, blog := range store.blogs {
+		if !blog.deletedAt.IsZero() && blog.deletedAt.Before(arg.DeletedBefore.Time) {
+			delete(store.blogs, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (store *fakeStore) PurgeTag(ctx context.Context, arg db.PurgeTagParams) (int64, error) {
+	return 0, nil
+}
+
+func (store *fakeStore) PurgeRole(ctx context.Context, arg db.PurgeRoleParams) (int64, error) {
+	return 0, nil
+}
+
+func (store *fakeStore) PurgeUser(ctx context.Context, arg db.PurgeUserParams) (int64, error) {
+	return 0, nil
+}
+
+// fakeClock is a clock the test moves by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+var launch = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func TestRunOncePublishesDueBlogs(t *testing.T) {
+	store := &fakeStore{blogs: map[int64]*fakeBlog{
+		1: {status: "scheduled", publishAt: launch},
+		2: {status: "scheduled", publishAt: launch.Add(time.Hour)},
+		3: {status: "approved", publishAt: launch.Add(-time.Hour)},
+	}}
+	clock := &fakeClock{now: launch.Add(-time.Minute)}
+	scheduler := NewScheduler(store, time.Minute, 0, clock.Now)
+
+	steps := []struct {
+		name string
+		now  time.Time
+		want map[int64]string
+	}{
+		{"before publish_at", launch.Add(-time.Minute), map[int64]string{1: "scheduled", 2: "scheduled", 3: "approved"}},
+		{"at publish_at", launch, map[int64]string{1: "published", 2: "scheduled", 3: "approved"}},
+		{"after the second publish_at", launch.Add(2 * time.Hour), map[int64]string{1: "published", 2: "published", 3: "approved"}},
+	}
+
+	for _, step := range steps {
+		clock.now = step.now
+		scheduler.RunOnce(context.Background())
+
+		for id, want := range step.want {
+			if got := store.blogs[id].status; got != want {
+				t.Errorf("%s: blog %d is %s, want %s", step.name, id, got, want)
+			}
+		}
+	}
+}
+
+func TestRestartPublishesMissedBlogs(t *testing.T) {
+	store := &fakeStore{blogs: map[int64]*fakeBlog{
+		1: {status: "scheduled", publishAt: launch},
+		2: {status: "scheduled", publishAt: launch.Add(30 * time.Minute)},
+		3: {status: "scheduled", publishAt: launch.Add(3 * time.Hour)},
+		4: {status: "scheduled", publishAt: launch, deletedAt: launch.Add(-time.Hour)},
+	}}
+
+	// The process was down while the first two posts came due, the first tick after the restart catches up
+	clock := &fakeClock{now: launch.Add(2 * time.Hour)}
+	ids, err := NewScheduler(store, time.Minute, 0, clock.Now).PublishScheduledBlogs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("published %v, want [1 2]", ids)
+	}
+	if got := store.blogs[3].status; got != "scheduled" {
+		t.Errorf("blog 3 is %s before its publish_at, want scheduled", got)
+	}
+	if got := store.blogs[4].status; got != "scheduled" {
+		t.Errorf("blog 4 in the trash is %s, want scheduled", got)
+	}
+}
+
+func TestPurgeExpiredTrashUsesClock(t *testing.T) {
+	store := &fakeStore{blogs: map[int64]*fakeBlog{
+		1: {status: "draft", deletedAt: launch},
+		2: {status: "draft", deletedAt: launch.Add(48 * time.Hour)},
+		3: {status: "draft"},
+	}}
+	clock := &fakeClock{now: launch.Add(30*24*time.Hour + time.Hour)}
+
+	purged, err := NewScheduler(store, time.Minute, 30*24*time.Hour, clock.Now).PurgeExpiredTrash(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if purged != 1 {
+		t.Fatalf("purged %d, want 1", purged)
+	}
+	if _, ok := store.blogs[1]; ok {
+		t.Error("blog 1 is still in the trash after the retention")
+	}
+	if _, ok := store.blogs[2]; !ok {
+		t.Error("blog 2 was purged before the retention passed")
+	}
+}
+
+func TestLoadingThaiDictionaryReindexesBlogs(t *testing.T) {
+	store := &fakeStore{blogs: map[int64]*fakeBlog{
+		1: {status: "published", content: "<p>กระทะตะหลิว</p>", searchVersion: search.IndexVersion()},
+	}}
+	scheduler := NewScheduler(store, time.Minute, 0, time.Now)
+
+	reindexed, err := scheduler.ReindexBlogSearch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reindexed != 0 {
+		t.Fatalf("reindexed %d blogs already at the current version, want 0", reindexed)
+	}
+
+	path := filepath.Join(t.TempDir(), "lexicon.txt")
+	if err := os.WriteFile(path, []byte("กระทะ\nตะหลิว\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := search.LoadThaiDictionary(path); err != nil {
+		t.Fatal(err)
+	}
+
+	reindexed, err = scheduler.ReindexBlogSearch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reindexed != 1 {
+		t.Fatalf("reindexed %d blogs after a new dictionary was loaded, want 1", reindexed)
+	}
+	if got, want := store.blogs[1].searchContent, "กระทะ ตะหลิว"; got != want {
+		t.Errorf("blog 1 is indexed as %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// The markers ts_headline puts around the matched words of a search snippet
+const (
+	snippetStartSel = "<mark>"
+	snippetStopSel  = "</mark>"
+)
+
+// indexRevision identifies how search vectors and the derived fields
+// (rendered HTML, excerpt, word count, reading time) are built.
+// Bump it whenever the segmenter or package content changes so the reindex job rebuilds stored posts.
+const indexRevision = 3
+
+// IndexVersion is the version stored with every indexed post. It combines indexRevision with a fingerprint
+// of the Thai dictionary in use, so posts indexed with another word list are rebuilt as well.
+// Versions are not ordered: any stored version other than the current one is stale.
+func IndexVersion() int32 {
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%d:%d", indexRevision, defaultThaiSegmenter.fingerprint)
+
+	// Posts that were never indexed carry 0
+	version := int32(hash.Sum32() >> 1)
+	if version == 0 {
+		version = 1
+	}
+	return version
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// IndexText prepares text for to_tsvector('simple', ...): markup is removed and the words
+// are lower-cased, segmented and joined with spaces, exactly as queries are tokenized.
+func IndexText(text string) string {
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+	return strings.Join(Words(text), " ")
+}
+
+// HighlightSnippet turns a search snippet, which ts_headline cut from the text of a post with its markup removed,
+// into safe HTML. The text is escaped so the <mark> around the matched words is the only markup left.
+// The text still holds the entities of the HTML it came from, and the only < in it are the markers.
+func HighlightSnippet(snippet string) string {
+	var out strings.Builder
+
+	for snippet != "" {
+		text, rest, found := strings.Cut(snippet, snippetStartSel)
+		out.WriteString(html.EscapeString(html.UnescapeString(text)))
+		if !found {
+			break
+		}
+
+		match, rest, _ := strings.Cut(rest, snippetStopSel)
+		out.WriteString(snippetStartSel + html.EscapeString(html.UnescapeString(match)) + snippetStopSel)
+		snippet = rest
+	}
+
+	return out.String()
+}
@@ -0,0 +1,210 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// term is a single search term: one word or a quoted phrase
+type term struct {
+	words  []string
+	prefix bool
+	negate bool
+}
+
+// BuildQuery converts user search input into a PostgreSQL tsquery expression for to_tsquery.
+//
+// Supported syntax:
+//
+//	go api          both words (AND)
+//	"go api"        the exact phrase
+//	go*             words starting with go
+//	-draft          posts without the word draft
+//	go OR rust      either word
+//
+// Characters that are not letters or digits never reach the tsquery, so arbitrary input is safe
+// to pass to to_tsquery. An empty string is returned when the input contains no searchable words.
+func BuildQuery(input string) string {
+	var groups [][]term
+	orNext := false
+
+	for _, token := range tokenize(input) {
+		if token == "OR" {
+			orNext = len(groups) > 0
+			continue
+		}
+
+		t, ok := parseTerm(token)
+		if !ok {
+			continue
+		}
+
+		if orNext {
+			groups[len(groups)-1] = append(groups[len(groups)-1], t)
+			orNext = false
+			continue
+		}
+
+		groups = append(groups, []term{t})
+	}
+
+	clauses := make([]string, 0, len(groups))
+	for _, group := range groups {
+		alternatives := make([]string, 0, len(group))
+		for _, t := range group {
+			alternatives = append(alternatives, t.String())
+		}
+
+		if len(alternatives) == 1 {
+			clauses = append(clauses, alternatives[0])
+		} else {
+			clauses = append(clauses, "("+strings.Join(alternatives, " | ")+")")
+		}
+	}
+
+	return strings.Join(clauses, " & ")
+}
+
+func (t term) String() string {
+	words := make([]string, len(t.words))
+	copy(words, t.words)
+	if t.prefix {
+		words[len(words)-1] += ":*"
+	}
+
+	expr := words[0]
+	if len(words) > 1 {
+		expr = "(" + strings.Join(words, " <-> ") + ")"
+	}
+
+	if t.negate {
+		return "!" + expr
+	}
+	return expr
+}
+
+// tokenize splits the input on whitespace, keeping quoted phrases together including their quotes
+func tokenize(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuote := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			current.WriteRune(r)
+			if inQuote {
+				flush()
+			}
+			inQuote = !inQuote
+		case unicode.IsSpace(r) && !inQuote:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// parseTerm turns a token into a term, returning false when it has no searchable words
+func parseTerm(token string) (term, bool) {
+	var t term
+
+	if strings.HasPrefix(token, "-") {
+		t.negate = true
+		token = token[1:]
+	}
+
+	if strings.HasPrefix(token, `"`) {
+		token = strings.Trim(token, `"`)
+	} else if strings.HasSuffix(token, "*") {
+		t.prefix = true
+		token = strings.TrimRight(token, "*")
+	}
+
+	t.words = Words(token)
+	if len(t.words) == 0 {
+		return term{}, false
+	}
+
+	return t, true
+}
+
+// Words splits text into lower-case words made of letters, combining marks and digits.
+// Runs of Thai script are further split with the Thai segmenter.
+func Words(text string) []string {
+	var words []string
+
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+
+	for _, field := range fields {
+		words = append(words, splitScripts(field)...)
+	}
+
+	return words
+}
+
+// splitScripts separates Thai from other scripts inside a word and segments the Thai parts
+func splitScripts(field string) []string {
+	var words []string
+	runes := []rune(field)
+
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && isThai(runes[i]) == isThai(runes[start]) {
+			continue
+		}
+
+		part := string(runes[start:i])
+		if isThai(runes[start]) {
+			words = append(words, SegmentThai(part)...)
+		} else {
+			words = append(words, part)
+		}
+		start = i
+	}
+
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+// FuzzyText reduces search input to the plain words used for trigram matching.
+// Operators, quotes and excluded terms are dropped and Thai is left unsegmented
+// because trigrams work on characters, not words.
+func FuzzyText(input string) string {
+	var words []string
+
+	for _, token := range tokenize(input) {
+		if token == "OR" || strings.HasPrefix(token, "-") {
+			continue
+		}
+
+		words = append(words, strings.FieldsFunc(strings.ToLower(token), func(r rune) bool {
+			return !isWordRune(r)
+		})...)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with the default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike makes text match itself literally inside a LIKE or ILIKE pattern
+func EscapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
@@ -0,0 +1,215 @@
+package search
+
+import (
+	_ "embed"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//go:embed dict/thai.txt
+var thaiDictionary string
+
+// defaultThaiSegmenter is built from the dictionary embedded in the binary,
+// LoadThaiDictionary replaces it at startup when a full lexicon is configured
+var defaultThaiSegmenter = NewThaiSegmenter(strings.Split(thaiDictionary, "\n"))
+
+type trieNode struct {
+	children map[rune]*trieNode
+	word     bool
+}
+
+// ThaiSegmenter splits Thai text, which has no spaces between words, into words
+// using maximal matching against a dictionary.
+type ThaiSegmenter struct {
+	root *trieNode
+	// fingerprint identifies the set of words, whatever their order or duplicates in the word list
+	fingerprint uint32
+}
+
+// NewThaiSegmenter creates a segmenter from a list of words.
+// Blank entries and lines starting with # are ignored.
+func NewThaiSegmenter(words []string) *ThaiSegmenter {
+	root := &trieNode{children: map[rune]*trieNode{}}
+	var added []string
+
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+
+		node := root
+		for _, r := range word {
+			child, ok := node.children[r]
+			if !ok {
+				child = &trieNode{children: map[rune]*trieNode{}}
+				node.children[r] = child
+			}
+			node = child
+		}
+		if !node.word {
+			node.word = true
+			added = append(added, word)
+		}
+	}
+
+	sort.Strings(added)
+	hash := fnv.New32a()
+	for _, word := range added {
+		hash.Write([]byte(word + "\n"))
+	}
+
+	return &ThaiSegmenter{root: root, fingerprint: hash.Sum32()}
+}
+
+// segmentCost ranks segmentations: fewer characters outside the dictionary first, then fewer words
+type segmentCost struct {
+	unknown int
+	words   int
+}
+
+func (cost segmentCost) less(other segmentCost) bool {
+	if cost.unknown != other.unknown {
+		return cost.unknown < other.unknown
+	}
+	return cost.words < other.words
+}
+
+// Segment splits a run of Thai text into words.
+// Characters not covered by the dictionary are kept together as a single word,
+// and words never end in the middle of a character cluster.
+func (segmenter *ThaiSegmenter) Segment(text string) []string {
+	runes := []rune(text)
+	n := len(runes)
+	if n == 0 {
+		return nil
+	}
+
+	// best[i] is the cheapest way to segment runes[:i], reached from prev[i]
+	best := make([]segmentCost, n+1)
+	prev := make([]int, n+1)
+	known := make([]bool, n+1)
+	reached := make([]bool, n+1)
+	reached[0] = true
+
+	relax := func(from int, to int, isWord bool) {
+		cost := best[from]
+		cost.words++
+		if !isWord {
+			cost.unknown += to - from
+		}
+
+		if !reached[to] || cost.less(best[to]) {
+			best[to] = cost
+			prev[to] = from
+			known[to] = isWord
+			reached[to] = true
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if !reached[i] {
+			continue
+		}
+
+		// Every dictionary word starting here
+		node := segmenter.root
+		for j := i; j < n; j++ {
+			node = node.children[runes[j]]
+			if node == nil {
+				break
+			}
+			if node.word && IsClusterBoundary(runes, j+1) {
+				relax(i, j+1, true)
+			}
+		}
+
+		// Or skip a single character cluster as unknown text
+		relax(i, nextThaiBoundary(runes, i), false)
+	}
+
+	// Walk back from the end, merging neighbouring unknown pieces into one word
+	var words []string
+	for end := n; end > 0; {
+		start := prev[end]
+		if !known[end] {
+			for start > 0 && !known[start] {
+				start = prev[start]
+			}
+		}
+
+		words = append(words, string(runes[start:end]))
+		end = start
+	}
+
+	for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+		words[i], words[j] = words[j], words[i]
+	}
+
+	return words
+}
+
+// LoadThaiDictionary adds the words of a UTF-8 word list, one word per line, to the embedded dictionary.
+// The embedded list only covers common words, so real Thai search needs a full lexicon such as the LibThai
+// word list loaded through SEARCH_THAI_DICTIONARY. Call it once at startup, before any text is segmented.
+// The new words change IndexVersion, so the reindex job rebuilds the posts indexed with the previous list.
+func LoadThaiDictionary(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read thai dictionary: %w", err)
+	}
+
+	words := strings.Split(thaiDictionary, "\n")
+	words = append(words, strings.Split(string(data), "\n")...)
+
+	defaultThaiSegmenter = NewThaiSegmenter(words)
+	return nil
+}
+
+// SegmentThai splits Thai text into words using the loaded dictionary
+func SegmentThai(text string) []string {
+	return defaultThaiSegmenter.Segment(text)
+}
+
+// isThai reports whether r belongs to the Thai block
+func isThai(r rune) bool {
+	return r >= 0x0E00 && r <= 0x0E7F
+}
+
+// isThaiLeadingVowel reports whether r is a vowel written before the consonant it follows in speech
+func isThaiLeadingVowel(r rune) bool {
+	return r >= 'เ' && r <= 'ไ'
+}
+
+// isThaiFollowingVowel reports whether r is a vowel that cannot start a word
+func isThaiFollowingVowel(r rune) bool {
+	return r == 'ะ' || r == 'า' || r == 'ำ' || r == 'ๅ'
+}
+
+// IsClusterBoundary reports whether text may be cut right before runes[i]
+// without separating a Thai vowel or tone mark from its consonant.
+func IsClusterBoundary(runes []rune, i int) bool {
+	if i <= 0 || i >= len(runes) {
+		return true
+	}
+
+	next := runes[i]
+	if unicode.IsMark(next) || isThaiFollowingVowel(next) {
+		return false
+	}
+
+	return !isThaiLeadingVowel(runes[i-1])
+}
+
+// nextThaiBoundary returns the end of the character cluster starting at runes[i]
+func nextThaiBoundary(runes []rune, i int) int {
+	j := i + 1
+	for j < len(runes) && !IsClusterBoundary(runes, j) {
+		j++
+	}
+	return j
+}
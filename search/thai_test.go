@@ -0,0 +1,56 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestThaiSegmenterFingerprint(t *testing.T) {
+	a := NewThaiSegmenter([]string{"# comment", "ภาษา", "ไทย", ""})
+	b := NewThaiSegmenter([]string{"ไทย", " ภาษา ", "ไทย"})
+	c := NewThaiSegmenter([]string{"ภาษา", "ไทย", "ค้นหา"})
+
+	if a.fingerprint != b.fingerprint {
+		t.Error("the same words in another order have another fingerprint")
+	}
+	if a.fingerprint == c.fingerprint {
+		t.Error("an added word keeps the fingerprint")
+	}
+}
+
+func TestLoadThaiDictionaryChangesIndexVersion(t *testing.T) {
+	embedded := defaultThaiSegmenter
+	t.Cleanup(func() { defaultThaiSegmenter = embedded })
+
+	before := IndexVersion()
+
+	// Words already in the embedded list change nothing
+	path := filepath.Join(t.TempDir(), "known.txt")
+	if err := os.WriteFile(path, []byte("ภาษา\nไทย\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadThaiDictionary(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := IndexVersion(); got != before {
+		t.Errorf("IndexVersion() = %d after loading known words, want %d", got, before)
+	}
+
+	path = filepath.Join(t.TempDir(), "lexicon.txt")
+	if err := os.WriteFile(path, []byte("กระทะ\nตะหลิว\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadThaiDictionary(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := IndexVersion(); got == before || got == 0 {
+		t.Errorf("IndexVersion() = %d after loading new words, want a version other than %d", got, before)
+	}
+}
+
+func TestLoadThaiDictionaryMissingFile(t *testing.T) {
+	if err := LoadThaiDictionary(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadThaiDictionary of a missing file succeeded")
+	}
+}
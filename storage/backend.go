@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"blog-go-api/util"
+)
+
+// Names of the backends STORAGE_BACKEND can select
+const (
+	BackendMinio      = "minio"
+	BackendFilesystem = "filesystem"
+	BackendMemory     = "memory"
+)
+
+// ServeRoute is where the API serves objects from when the backend cannot serve them itself
+const ServeRoute = "/api/storage"
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrInvalidKey     = errors.New("invalid object key")
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	ModTime     time.Time `json:"mod_time"`
+}
+
+// Backend stores the uploaded files of the API. Keys are slash separated paths relative to the root of the backend.
+type Backend interface {
+	// Put stores the content of r under key, size is -1 when the length is not known up front
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (ObjectInfo, error)
+	// Get opens an object for reading, the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// PresignGet returns a URL that gives read access to an object until expiry passes
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// URL returns the public URL of an object, URL("") the prefix the URLs of all objects share
+	URL(key string) string
+}
+
+// New creates the backend selected by STORAGE_BACKEND, MinIO when it is not set
+func New(config util.Config) (Backend, error) {
+	switch config.StorageBackend {
+	case "", BackendMinio:
+		publicURL := config.StoragePublicURL
+		if publicURL == "" {
+			publicURL = config.MINIO_URL_RESULT
+		}
+		return NewMinioBackend(config.MINIO_ENDPOINT, config.MINIO_ACCESS_KEY_ID, config.MINIO_SECRET_ACCESS_KEY,
+			config.MINIO_USE_SSL, config.MINIO_BUCKET_NAME, publicURL)
+	case BackendFilesystem:
+		return NewFilesystemBackend(config.StoragePath, servedURL(config))
+	case BackendMemory:
+		return NewMemoryBackend(servedURL(config)), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", config.StorageBackend)
+	}
+}
+
+// servedURL is the public URL of a backend whose objects are served by the API itself
+func servedURL(config util.Config) string {
+	if config.StoragePublicURL != "" {
+		return config.StoragePublicURL
+	}
+	return ServeRoute + "/"
+}
+
+// objectURL joins the public base URL of a backend and an object key
+func objectURL(publicURL string, key string) string {
+	return strings.TrimSuffix(publicURL, "/") + "/" + key
+}
+
+// cleanKey rejects keys that are empty or would escape the root of a backend
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// localBackends returns the backends that run without any service
+func localBackends(t *testing.T) map[string]Backend {
+	filesystem, err := NewFilesystemBackend(t.TempDir(), "/api/storage/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]Backend{
+		BackendFilesystem: filesystem,
+		BackendMemory:     NewMemoryBackend("/api/storage/"),
+	}
+}
+
+func TestBackendRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	for name, backend := range localBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, size := range []int64{5, -1} {
+				info, err := backend.Put(ctx, "2026/cover.png", strings.NewReader("hello"), size, "image/png")
+				if err != nil {
+					t.Fatalf("Put with size %d: %v", size, err)
+				}
+				if info.Key != "2026/cover.png" || info.Size != 5 || info.ContentType != "image/png" {
+					t.Fatalf("Put returned %+v", info)
+				}
+			}
+
+			object, info, err := backend.Get(ctx, "2026/cover.png")
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(object)
+			object.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "hello" || info.Size != 5 || info.ContentType != "image/png" {
+				t.Fatalf("Get returned %q, %+v", data, info)
+			}
+
+			stat, err := backend.Stat(ctx, "2026/cover.png")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stat.Size != 5 || stat.ContentType != "image/png" {
+				t.Fatalf("Stat returned %+v", stat)
+			}
+
+			if url := backend.URL("2026/cover.png"); url != "/api/storage/2026/cover.png" {
+				t.Fatalf("URL is %s", url)
+			}
+			if url, err := backend.PresignGet(ctx, "2026/cover.png", time.Minute); err != nil || url != "/api/storage/2026/cover.png" {
+				t.Fatalf("PresignGet returned %s, %v", url, err)
+			}
+
+			if err := backend.Delete(ctx, "2026/cover.png"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := backend.Stat(ctx, "2026/cover.png"); !errors.Is(err, ErrObjectNotFound) {
+				t.Fatalf("Stat after Delete returned %v, want ErrObjectNotFound", err)
+			}
+
+			// Deleting again is not an error, like S3
+			if err := backend.Delete(ctx, "2026/cover.png"); err != nil {
+				t.Fatalf("second Delete returned %v", err)
+			}
+		})
+	}
+}
+
+func TestBackendNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	for name, backend := range localBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := backend.Get(ctx, "missing.png"); !errors.Is(err, ErrObjectNotFound) {
+				t.Errorf("Get returned %v, want ErrObjectNotFound", err)
+			}
+			if _, err := backend.Stat(ctx, "missing.png"); !errors.Is(err, ErrObjectNotFound) {
+				t.Errorf("Stat returned %v, want ErrObjectNotFound", err)
+			}
+			if _, err := backend.PresignGet(ctx, "missing.png", time.Minute); !errors.Is(err, ErrObjectNotFound) {
+				t.Errorf("PresignGet returned %v, want ErrObjectNotFound", err)
+			}
+		})
+	}
+}
+
+func TestBackendRejectsInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	keys := []string{"", "/", "../secret", "a/../../secret", "a/./b", "a//b", "a/", `..\secret`, ".."}
+
+	for name, backend := range localBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range keys {
+				if _, err := backend.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); !errors.Is(err, ErrInvalidKey) {
+					t.Errorf("Put(%q) returned %v, want ErrInvalidKey", key, err)
+				}
+				if _, _, err := backend.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+					t.Errorf("Get(%q) returned %v, want ErrInvalidKey", key, err)
+				}
+				if err := backend.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+					t.Errorf("Delete(%q) returned %v, want ErrInvalidKey", key, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+		err  error
+	}{
+		{"cover.png", "cover.png", nil},
+		{"/2026/cover.png", "2026/cover.png", nil},
+		{"a/b/c.txt", "a/b/c.txt", nil},
+		{"..", "", ErrInvalidKey},
+		{"../cover.png", "", ErrInvalidKey},
+		{"a/../../cover.png", "", ErrInvalidKey},
+		{`a\..\cover.png`, "", ErrInvalidKey},
+		{"", "", ErrInvalidKey},
+	}
+
+	for _, test := range tests {
+		got, err := cleanKey(test.key)
+		if got != test.want || !errors.Is(err, test.err) {
+			t.Errorf("cleanKey(%q) = %q, %v, want %q, %v", test.key, got, err, test.want, test.err)
+		}
+	}
+}
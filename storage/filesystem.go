@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// FilesystemBackend stores objects as files below a local directory, for running without MinIO.
+// The content type of an object is derived from the extension of its key.
+type FilesystemBackend struct {
+	root      string
+	publicURL string
+}
+
+// NewFilesystemBackend stores objects below root, which is created when it does not exist.
+// Objects are linked to below publicURL.
+func NewFilesystemBackend(root string, publicURL string) (*FilesystemBackend, error) {
+	if root == "" {
+		return nil, errors.New("STORAGE_PATH is required for the filesystem storage backend")
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create storage directory: %w", err)
+	}
+
+	return &FilesystemBackend{
+		root:      root,
+		publicURL: publicURL,
+	}, nil
+}
+
+// path returns the file an object is stored in
+func (backend *FilesystemBackend) path(key string) (string, string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	return key, filepath.Join(backend.root, filepath.FromSlash(key)), nil
+}
+
+func (backend *FilesystemBackend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (ObjectInfo, error) {
+	key, name, err := backend.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	// Written to a temporary file first so a failed upload never leaves a partial object behind
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer os.Remove(file.Name())
+
+	written, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	if err := os.Rename(file.Name(), name); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{
+		Key:         key,
+		Size:        written,
+		ContentType: contentType,
+		ModTime:     time.Now(),
+	}, nil
+}
+
+func (backend *FilesystemBackend) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	key, name, err := backend.path(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, ObjectInfo{}, filesystemError(err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, ObjectInfo{}, filesystemError(err)
+	}
+	if stat.IsDir() {
+		file.Close()
+		return nil, ObjectInfo{}, ErrObjectNotFound
+	}
+
+	return file, fileObjectInfo(key, stat), nil
+}
+
+func (backend *FilesystemBackend) Delete(ctx context.Context, key string) error {
+	_, name, err := backend.path(key)
+	if err != nil {
+		return err
+	}
+
+	// Like S3, deleting an object that does not exist succeeds
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (backend *FilesystemBackend) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	key, name, err := backend.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	stat, err := os.Stat(name)
+	if err != nil {
+		return ObjectInfo{}, filesystemError(err)
+	}
+	if stat.IsDir() {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+
+	return fileObjectInfo(key, stat), nil
+}
+
+// PresignGet returns the public URL of the object, files are served by the API without signing
+func (backend *FilesystemBackend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := backend.Stat(ctx, key); err != nil {
+		return "", err
+	}
+	return backend.URL(key), nil
+}
+
+func (backend *FilesystemBackend) URL(key string) string {
+	return objectURL(backend.publicURL, key)
+}
+
+func fileObjectInfo(key string, stat fs.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: contentTypeByKey(key),
+		ModTime:     stat.ModTime(),
+	}
+}
+
+// contentTypeByKey guesses the content type of an object from the extension of its key
+func contentTypeByKey(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+func filesystemError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrObjectNotFound
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps objects in memory. Nothing survives a restart, it is meant for tests and local runs.
+type MemoryBackend struct {
+	mu        sync.RWMutex
+	objects   map[string]memoryObject
+	publicURL string
+}
+
+type memoryObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+// NewMemoryBackend creates an empty in-memory backend. Objects are linked to below publicURL.
+func NewMemoryBackend(publicURL string) *MemoryBackend {
+	return &MemoryBackend{
+		objects:   make(map[string]memoryObject),
+		publicURL: publicURL,
+	}
+}
+
+func (backend *MemoryBackend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info := ObjectInfo{
+		Key:         key,
+		Size:        int64(len(data)),
+		ContentType: contentType,
+		ModTime:     time.Now(),
+	}
+
+	backend.mu.Lock()
+	backend.objects[key] = memoryObject{data: data, info: info}
+	backend.mu.Unlock()
+
+	return info, nil
+}
+
+func (backend *MemoryBackend) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	object, err := backend.object(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	return io.NopCloser(bytes.NewReader(object.data)), object.info, nil
+}
+
+func (backend *MemoryBackend) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	backend.mu.Lock()
+	delete(backend.objects, key)
+	backend.mu.Unlock()
+
+	return nil
+}
+
+func (backend *MemoryBackend) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	object, err := backend.object(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return object.info, nil
+}
+
+// PresignGet returns the public URL of the object, objects are served by the API without signing
+func (backend *MemoryBackend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := backend.object(key); err != nil {
+		return "", err
+	}
+	return backend.URL(key), nil
+}
+
+func (backend *MemoryBackend) URL(key string) string {
+	return objectURL(backend.publicURL, key)
+}
+
+func (backend *MemoryBackend) object(key string) (memoryObject, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return memoryObject{}, err
+	}
+
+	backend.mu.RLock()
+	object, ok := backend.objects[key]
+	backend.mu.RUnlock()
+
+	if !ok {
+		return memoryObject{}, ErrObjectNotFound
+	}
+	return object, nil
+}
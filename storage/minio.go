@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// minioPartSize is the size of the parts an upload of unknown length is sent in,
+// it bounds the memory one upload holds to a single part
+const minioPartSize = 5 * 1024 * 1024
+
+// MinioBackend stores objects in a bucket of MinIO or any other S3 compatible service
+type MinioBackend struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewMinioBackend connects to bucket on endpoint. Objects are linked to below publicURL.
+func NewMinioBackend(endpoint, accessKeyID, secretAccessKey string, useSSL bool, bucket string, publicURL string) (*MinioBackend, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create minio client: %w", err)
+	}
+
+	return &MinioBackend{
+		client:    client,
+		bucket:    bucket,
+		publicURL: publicURL,
+	}, nil
+}
+
+func (backend *MinioBackend) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	options := minio.PutObjectOptions{ContentType: contentType}
+	if size < 0 {
+		options.PartSize = minioPartSize
+	}
+
+	info, err := backend.client.PutObject(ctx, backend.bucket, key, r, size, options)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return ObjectInfo{
+		Key:         key,
+		Size:        info.Size,
+		ContentType: contentType,
+		ModTime:     info.LastModified,
+	}, nil
+}
+
+func (backend *MinioBackend) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	object, err := backend.client.GetObject(ctx, backend.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, minioError(err)
+	}
+
+	// GetObject does not reach the server until the object is read, Stat finds out whether it exists
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, ObjectInfo{}, minioError(err)
+	}
+
+	return object, minioObjectInfo(stat), nil
+}
+
+func (backend *MinioBackend) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	return minioError(backend.client.RemoveObject(ctx, backend.bucket, key, minio.RemoveObjectOptions{}))
+}
+
+func (backend *MinioBackend) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	stat, err := backend.client.StatObject(ctx, backend.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, minioError(err)
+	}
+
+	return minioObjectInfo(stat), nil
+}
+
+func (backend *MinioBackend) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := backend.client.PresignedGetObject(ctx, backend.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return url.String(), nil
+}
+
+func (backend *MinioBackend) URL(key string) string {
+	return objectURL(backend.publicURL, key)
+}
+
+func minioObjectInfo(stat minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         stat.Key,
+		Size:        stat.Size,
+		ContentType: stat.ContentType,
+		ModTime:     stat.LastModified,
+	}
+}
+
+// minioError maps the error for a missing object to ErrObjectNotFound
+func minioError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchObject":
+		return ErrObjectNotFound
+	}
+	return err
+}
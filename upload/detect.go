@@ -0,0 +1,200 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+var (
+	ErrUnsupportedType = errors.New("only JPEG, PNG, WebP, GIF, AVIF and SVG images can be uploaded")
+	ErrInvalidImage    = errors.New("the image is damaged or its size cannot be read")
+	ErrTooLarge        = errors.New("the upload is too large")
+	ErrTooManyPixels   = errors.New("the image has too many pixels")
+)
+
+// Image is what the first bytes of an upload tell about it
+type Image struct {
+	Type   Type
+	Width  int // zero when not known, as for most SVGs
+	Height int
+}
+
+// Inspect identifies an upload from its first bytes, whatever type the client declared, and checks it against
+// the limits of its type. The header has to cover the start of the file up to the dimensions of the image.
+func Inspect(header []byte) (Image, error) {
+	t, ok := detect(header)
+	if !ok {
+		return Image{}, ErrUnsupportedType
+	}
+
+	img := Image{Type: t}
+	if t.MaxPixels == 0 {
+		return img, nil
+	}
+
+	width, height, err := dimensions(t, header)
+	if err != nil || width <= 0 || height <= 0 {
+		return img, ErrInvalidImage
+	}
+	img.Width, img.Height = width, height
+
+	if width > maxImageSide || height > maxImageSide || int64(width)*int64(height) > t.MaxPixels {
+		return img, fmt.Errorf("%w: %s images can have at most %d pixels and %d on a side, this one is %dx%d",
+			ErrTooManyPixels, t.ContentType, t.MaxPixels, maxImageSide, width, height)
+	}
+
+	return img, nil
+}
+
+// detect sniffs the type of a file from its magic bytes
+func detect(header []byte) (Type, bool) {
+	switch {
+	case bytes.HasPrefix(header, []byte("\xff\xd8\xff")):
+		return JPEG, true
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return PNG, true
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return GIF, true
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return WebP, true
+	case isAVIF(header):
+		return AVIF, true
+	case isSVG(header):
+		return SVG, true
+	}
+	return Type{}, false
+}
+
+// isAVIF looks for the avif brand in the ftyp box an AVIF file starts with
+func isAVIF(header []byte) bool {
+	if len(header) < 16 || string(header[4:8]) != "ftyp" {
+		return false
+	}
+
+	size := int(binary.BigEndian.Uint32(header[:4]))
+	if size < 16 || size > len(header) {
+		return false
+	}
+
+	// The major brand, then the compatible brands after the minor version
+	brands := append([]byte{}, header[8:12]...)
+	brands = append(brands, header[16:size]...)
+	for i := 0; i+4 <= len(brands); i += 4 {
+		if brand := string(brands[i : i+4]); brand == "avif" || brand == "avis" {
+			return true
+		}
+	}
+	return false
+}
+
+// isSVG reports whether the first element of an XML document is svg
+func isSVG(header []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(header))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return false
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			return token.Name.Local == "svg"
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				return false
+			}
+		}
+	}
+}
+
+// dimensions reads the width and height of a raster image from its header
+func dimensions(t Type, header []byte) (int, int, error) {
+	switch t {
+	case WebP:
+		return webpDimensions(header)
+	case AVIF:
+		return avifDimensions(header)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(header))
+	if err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+// webpDimensions reads the size from the first chunk of a WebP file, which is lossy, lossless or extended
+func webpDimensions(header []byte) (int, int, error) {
+	if len(header) < 30 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+
+	chunk := header[20:]
+	switch string(header[12:16]) {
+	case "VP8 ":
+		if !bytes.Equal(chunk[3:6], []byte{0x9d, 0x01, 0x2a}) {
+			return 0, 0, ErrInvalidImage
+		}
+		return int(binary.LittleEndian.Uint16(chunk[6:8]) & 0x3fff), int(binary.LittleEndian.Uint16(chunk[8:10]) & 0x3fff), nil
+	case "VP8L":
+		if chunk[0] != 0x2f {
+			return 0, 0, ErrInvalidImage
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:5])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, nil
+	case "VP8X":
+		return int(uint24(chunk[4:7])) + 1, int(uint24(chunk[7:10])) + 1, nil
+	}
+	return 0, 0, ErrInvalidImage
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
+// avifDimensions reads the size from the first image spatial extents (ispe) property of an AVIF file
+func avifDimensions(header []byte) (int, int, error) {
+	i := bytes.Index(header, []byte("ispe"))
+	if i < 0 || i+16 > len(header) {
+		return 0, 0, ErrInvalidImage
+	}
+
+	// The box type is followed by a version and flags, then the width and the height
+	property := header[i+8:]
+	return int(binary.BigEndian.Uint32(property[:4])), int(binary.BigEndian.Uint32(property[4:8])), nil
+}
+
+// LimitReader reads from r and fails with ErrTooLarge once more than max bytes come through
+func LimitReader(r io.Reader, max int64) io.Reader {
+	return &limitReader{r: r, left: max}
+}
+
+type limitReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, ErrTooLarge
+	}
+
+	// Read one byte past the limit so an upload of exactly max bytes still ends cleanly
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
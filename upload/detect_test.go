@@ -0,0 +1,154 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+func encode(t *testing.T, encoder func(io.Writer, image.Image) error, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := encoder(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) }
+func encodeGIF(w io.Writer, img image.Image) error  { return gif.Encode(w, img, nil) }
+
+// webp builds the header of a WebP file whose first chunk is fourcc with data
+func webp(fourcc string, data []byte) []byte {
+	header := []byte("RIFF\x00\x00\x00\x00WEBP" + fourcc)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(data)))
+	return append(header, append(data, make([]byte, 16)...)...)
+}
+
+func webpLossy(width, height int) []byte {
+	data := []byte{0x10, 0x02, 0x00, 0x9d, 0x01, 0x2a}
+	data = binary.LittleEndian.AppendUint16(data, uint16(width))
+	data = binary.LittleEndian.AppendUint16(data, uint16(height))
+	return webp("VP8 ", data)
+}
+
+func webpLossless(width, height int) []byte {
+	bits := uint32(width-1) | uint32(height-1)<<14
+	return webp("VP8L", binary.LittleEndian.AppendUint32([]byte{0x2f}, bits))
+}
+
+func webpExtended(width, height int) []byte {
+	data := []byte{0, 0, 0, 0}
+	data = append(data, byte(width-1), byte((width-1)>>8), byte((width-1)>>16))
+	data = append(data, byte(height-1), byte((height-1)>>8), byte((height-1)>>16))
+	return webp("VP8X", data)
+}
+
+// avif builds the header of an AVIF file with brand as major brand and an ispe property
+func avif(brand string, width, height int) []byte {
+	header := []byte("\x00\x00\x00\x1cftyp" + brand + "\x00\x00\x00\x00mif1miafMA1B")
+	header = append(header, "\x00\x00\x00\x14ispe\x00\x00\x00\x00"...)
+	header = binary.BigEndian.AppendUint32(header, uint32(width))
+	return binary.BigEndian.AppendUint32(header, uint32(height))
+}
+
+func TestInspect(t *testing.T) {
+	pngHeader := encode(t, png.Encode, 640, 480)
+	jpegHeader := encode(t, encodeJPEG, 640, 480)
+	gifHeader := encode(t, encodeGIF, 640, 480)
+
+	tests := []struct {
+		name   string
+		header []byte
+		want   Type
+		width  int
+		height int
+		err    error
+	}{
+		{"jpeg", jpegHeader, JPEG, 640, 480, nil},
+		{"png", pngHeader, PNG, 640, 480, nil},
+		{"gif", gifHeader, GIF, 640, 480, nil},
+		{"webp lossy", webpLossy(640, 480), WebP, 640, 480, nil},
+		{"webp lossless", webpLossless(640, 480), WebP, 640, 480, nil},
+		{"webp extended", webpExtended(640, 480), WebP, 640, 480, nil},
+		{"avif", avif("avif", 640, 480), AVIF, 640, 480, nil},
+		{"avif sequence", avif("avis", 640, 480), AVIF, 640, 480, nil},
+		{"avif as compatible brand", bytes.Replace(avif("mif1", 640, 480), []byte("MA1B"), []byte("avif"), 1), AVIF, 640, 480, nil},
+		{"heic", avif("heic", 640, 480), Type{}, 0, 0, ErrUnsupportedType},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), SVG, 0, 0, nil},
+		{"svg with prolog", []byte("\n<?xml version=\"1.0\"?>\n<!DOCTYPE svg>\n<!-- logo -->\n<svg></svg>"), SVG, 0, 0, nil},
+
+		{"truncated jpeg", jpegHeader[:20], JPEG, 0, 0, ErrInvalidImage},
+		{"truncated png", pngHeader[:16], PNG, 0, 0, ErrInvalidImage},
+		{"truncated gif", gifHeader[:8], GIF, 0, 0, ErrInvalidImage},
+		{"truncated webp", webpLossy(640, 480)[:24], WebP, 0, 0, ErrInvalidImage},
+		{"webp with a bad frame", webp("VP8 ", make([]byte, 10)), WebP, 0, 0, ErrInvalidImage},
+		{"webp with an unknown chunk", webp("ALPH", make([]byte, 10)), WebP, 0, 0, ErrInvalidImage},
+		{"avif without ispe", avif("avif", 640, 480)[:28], AVIF, 0, 0, ErrInvalidImage},
+		{"truncated avif", avif("avif", 640, 480)[:12], Type{}, 0, 0, ErrUnsupportedType},
+		{"truncated svg", []byte("<sv"), Type{}, 0, 0, ErrUnsupportedType},
+		{"empty", nil, Type{}, 0, 0, ErrUnsupportedType},
+
+		{"pdf sent as image/jpeg", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n1 0 obj"), Type{}, 0, 0, ErrUnsupportedType},
+		{"html", []byte("<!DOCTYPE html><html><body><svg></svg></body></html>"), Type{}, 0, 0, ErrUnsupportedType},
+		{"text before svg", []byte("hello <svg></svg>"), Type{}, 0, 0, ErrUnsupportedType},
+		{"bmp", []byte("BM\x36\x00\x0c\x00\x00\x00\x00\x00\x36\x00\x00\x00"), Type{}, 0, 0, ErrUnsupportedType},
+		{"zero sized webp", webpLossy(0, 0), WebP, 0, 0, ErrInvalidImage},
+
+		{"png over the pixel limit", encode(t, png.Encode, 8000, 5001), PNG, 8000, 5001, ErrTooManyPixels},
+		{"png wider than any side may be", encode(t, png.Encode, maxImageSide+1, 1), PNG, maxImageSide + 1, 1, ErrTooManyPixels},
+		{"png at the pixel limit", encode(t, png.Encode, 8000, 5000), PNG, 8000, 5000, nil},
+		{"gif over its smaller limit", encode(t, encodeGIF, 4000, 2501), GIF, 4000, 2501, ErrTooManyPixels},
+		{"webp over the pixel limit", webpExtended(16000, 16000), WebP, 16000, 16000, ErrTooManyPixels},
+		{"avif over the pixel limit", avif("avif", 100000, 100000), AVIF, 100000, 100000, ErrTooManyPixels},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img, err := Inspect(test.header)
+			if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+				t.Fatalf("Inspect returned error %v, want %v", err, test.err)
+			}
+			if img.Type != test.want {
+				t.Errorf("type is %q, want %q", img.Type.ContentType, test.want.ContentType)
+			}
+			if err == nil || errors.Is(err, ErrTooManyPixels) {
+				if img.Width != test.width || img.Height != test.height {
+					t.Errorf("size is %dx%d, want %dx%d", img.Width, img.Height, test.width, test.height)
+				}
+			}
+		})
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	tests := []struct {
+		size int
+		max  int64
+		err  error
+	}{
+		{10, 10, nil},
+		{9, 10, nil},
+		{11, 10, ErrTooLarge},
+		{0, 0, nil},
+		{1, 0, ErrTooLarge},
+	}
+
+	for _, test := range tests {
+		data, err := io.ReadAll(LimitReader(strings.NewReader(strings.Repeat("x", test.size)), test.max))
+		if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+			t.Errorf("reading %d bytes with a limit of %d returned %v, want %v", test.size, test.max, err, test.err)
+		}
+		if test.err == nil && len(data) != test.size {
+			t.Errorf("read %d bytes, want %d", len(data), test.size)
+		}
+	}
+}
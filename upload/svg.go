@@ -0,0 +1,200 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var errInvalidSVG = fmt.Errorf("%w: the svg is not a well formed document", ErrInvalidImage)
+
+// svgBlockedElements can run script or pull in other documents, they are removed with everything inside them
+var svgBlockedElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+	"set":           true,
+}
+
+// svgSafeDataURL matches the data URLs an svg may keep in a link, raster images only
+var svgSafeDataURL = regexp.MustCompile(`(?i)^data:image/(png|jpeg|gif|webp|avif)[;,]`)
+
+// svgExternalURL matches a CSS url() that does not point at a fragment of the document itself
+var svgExternalURL = regexp.MustCompile(`(?i)url\(\s*['"]?\s*[^#'"\s)]`)
+
+// SanitizeSVG rewrites an svg document keeping only markup that cannot run script or load anything from outside:
+// blocked elements, event handler attributes, links to other documents and external CSS are dropped, as are
+// comments, processing instructions and the doctype.
+func SanitizeSVG(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	var open []string // names of the elements written and not yet closed
+	rootDone := false
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidSVG, err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if rootDone || (len(open) == 0 && token.Name.Local != "svg") {
+				return nil, errInvalidSVG
+			}
+
+			local := strings.ToLower(token.Name.Local)
+			if svgBlockedElements[local] || (strings.HasPrefix(local, "animate") && animatesLink(token)) {
+				if err := skipElement(decoder); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if local == "style" {
+				css, err := elementText(decoder)
+				if err != nil {
+					return nil, err
+				}
+				if safeCSS(css) {
+					writeStart(&out, token)
+					xml.EscapeText(&out, []byte(css))
+					out.WriteString("</" + qualifiedName(token.Name) + ">")
+				}
+				continue
+			}
+
+			writeStart(&out, token)
+			open = append(open, qualifiedName(token.Name))
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != qualifiedName(token.Name) {
+				return nil, errInvalidSVG
+			}
+			open = open[:len(open)-1]
+			out.WriteString("</" + qualifiedName(token.Name) + ">")
+			if len(open) == 0 {
+				rootDone = true
+			}
+		case xml.CharData:
+			if len(open) == 0 {
+				if len(bytes.TrimSpace(token)) > 0 {
+					return nil, errInvalidSVG
+				}
+				continue
+			}
+			xml.EscapeText(&out, token)
+		}
+	}
+
+	if !rootDone || len(open) > 0 {
+		return nil, errInvalidSVG
+	}
+
+	return out.Bytes(), nil
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// writeStart writes a start tag with only its safe attributes
+func writeStart(out *bytes.Buffer, element xml.StartElement) {
+	out.WriteString("<" + qualifiedName(element.Name))
+	for _, attr := range element.Attr {
+		if !safeAttr(attr) {
+			continue
+		}
+		out.WriteString(" " + qualifiedName(attr.Name) + `="`)
+		xml.EscapeText(out, []byte(attr.Value))
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+func safeAttr(attr xml.Attr) bool {
+	local := strings.ToLower(attr.Name.Local)
+	value := strings.TrimSpace(attr.Value)
+
+	if strings.HasPrefix(local, "on") {
+		return false
+	}
+	if local == "href" || local == "src" {
+		return strings.HasPrefix(value, "#") || svgSafeDataURL.MatchString(value)
+	}
+	return safeCSS(value)
+}
+
+// safeCSS rejects style that can run script or fetch from outside the document
+func safeCSS(css string) bool {
+	lower := strings.ToLower(css)
+	return !strings.Contains(lower, "javascript:") &&
+		!strings.Contains(lower, "expression(") &&
+		!strings.Contains(lower, "@import") &&
+		!svgExternalURL.MatchString(css)
+}
+
+// animatesLink reports whether an animation element changes a link or an event handler, which could inject script
+func animatesLink(element xml.StartElement) bool {
+	for _, attr := range element.Attr {
+		if strings.ToLower(attr.Name.Local) != "attributename" {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(attr.Value))
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			name = name[i+1:]
+		}
+		return name == "href" || name == "src" || strings.HasPrefix(name, "on")
+	}
+	return false
+}
+
+// skipElement consumes the tokens up to the end of the element whose start was just read
+func skipElement(decoder *xml.Decoder) error {
+	for depth := 1; depth > 0; {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidSVG, err)
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil
+}
+
+// elementText reads the text of an element that may not hold other elements, up to its end
+func elementText(decoder *xml.Decoder) (string, error) {
+	var text strings.Builder
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", errInvalidSVG, err)
+		}
+		switch token := token.(type) {
+		case xml.CharData:
+			text.Write(token)
+		case xml.StartElement:
+			return "", errInvalidSVG
+		case xml.EndElement:
+			return text.String(), nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package upload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const svgOpen = `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">`
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", `<rect width="10" height="5"/>`, `<rect width="10" height="5"></rect>`},
+		{"script", `<script>alert(1)</script><circle r="1"/>`, `<circle r="1"></circle>`},
+		{"script in another case", `<SCRIPT>alert(1)</SCRIPT>`, ``},
+		{"nested script", `<g><script><![CDATA[alert(1)]]></script></g>`, `<g></g>`},
+		{"onload", `<g onload="alert(1)" ONCLICK="alert(2)" id="a"/>`, `<g id="a"></g>`},
+		{"javascript xlink:href", `<a xlink:href="javascript:alert(1)"><text>x</text></a>`, `<a><text>x</text></a>`},
+		{"javascript href", `<a href=" javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"external href", `<image href="https://evil.example/x.png"/>`, `<image></image>`},
+		{"svg data url", `<image href="data:image/svg+xml;base64,PHN2Zz4="/>`, `<image></image>`},
+		{"raster data url", `<image href="data:image/png;base64,iVBO"/>`, `<image href="data:image/png;base64,iVBO"></image>`},
+		{"fragment href", `<use xlink:href="#icon"/>`, `<use xlink:href="#icon"></use>`},
+		{"animate href", `<a><animate attributeName="href" to="javascript:alert(1)"/>x</a>`, `<a>x</a>`},
+		{"animate xlink:href", `<a><animate attributeName="xlink:href" values="javascript:alert(1)"/>x</a>`, `<a>x</a>`},
+		{"animate event handler", `<g><animate attributeName="onbegin" to="alert(1)"/></g>`, `<g></g>`},
+		{"animate opacity", `<g><animate attributeName="opacity" to="0"/></g>`, `<g><animate attributeName="opacity" to="0"></animate></g>`},
+		{"set", `<a><set attributeName="href" to="javascript:alert(1)"/>x</a>`, `<a>x</a>`},
+		{"foreignObject", `<foreignObject><div xmlns="http://www.w3.org/1999/xhtml"><img src="x" onerror="alert(1)"/></div></foreignObject>`, ``},
+		{"iframe and embed", `<iframe src="https://evil.example"/><embed src="x.swf"/>`, ``},
+		{"style with @import", `<style>@import url(https://evil.example/x.css);</style><g/>`, `<g></g>`},
+		{"style with external url", `<style>rect { fill: url("https://evil.example/x.svg#p") }</style>`, ``},
+		{"style with javascript", `<style>g { background: javascript:alert(1) }</style>`, ``},
+		{"safe style", `<style>.a { fill: url(#grad) } .b > .c { stroke: red }</style>`, `<style>.a { fill: url(#grad) } .b &gt; .c { stroke: red }</style>`},
+		{"external url attribute", `<rect fill="url(https://evil.example/p.svg#g)" style="filter: url( 'http://evil.example' )" stroke="red"/>`, `<rect stroke="red"></rect>`},
+		{"fragment url attribute", `<rect fill="url(#grad)" style="fill: url( '#grad' )"/>`, `<rect fill="url(#grad)" style="fill: url( &#39;#grad&#39; )"></rect>`},
+		{"expression", `<rect style="width: expression(alert(1))"/>`, `<rect></rect>`},
+		{"comment and processing instruction", `<!-- note --><?xml-stylesheet href="https://evil.example/x.css"?><g/>`, `<g></g>`},
+		{"text is escaped", `<text>a &lt; b &amp; <![CDATA[<c>]]></text>`, `<text>a &lt; b &amp; &lt;c&gt;</text>`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := SanitizeSVG([]byte(svgOpen + test.input + "</svg>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := svgOpen + test.want + "</svg>"
+			if string(got) != want {
+				t.Errorf("got  %s\nwant %s", got, want)
+			}
+
+			lower := strings.ToLower(string(got))
+			for _, unsafe := range []string{"<script", "javascript:", "onload", "onerror", "foreignobject", "@import", "evil.example"} {
+				if strings.Contains(lower, unsafe) {
+					t.Errorf("%s is still in %s", unsafe, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeSVGRejectsInvalidDocuments(t *testing.T) {
+	tests := map[string]string{
+		"not svg":             `<html><svg></svg></html>`,
+		"unclosed":            `<svg><g></svg>`,
+		"mismatched":          `<svg><g></h></svg>`,
+		"two roots":           `<svg></svg><svg></svg>`,
+		"text after the root": `<svg></svg>alert(1)`,
+		"custom entity":       `<!DOCTYPE svg [<!ENTITY lol "lol">]><svg>&lol;</svg>`,
+		"unclosed script":     `<svg><script>alert(1)`,
+		"element in style":    `<svg><style><script>alert(1)</script></style></svg>`,
+		"empty":               ``,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := SanitizeSVG([]byte(input)); !errors.Is(err, ErrInvalidImage) {
+				t.Fatalf("SanitizeSVG returned %v, want ErrInvalidImage", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package upload
+
+// maxImageSide caps the width and the height of every raster image
+const maxImageSide = 16384
+
+// Type is a kind of file that may be uploaded, with the limits that apply to it
+type Type struct {
+	ContentType string
+	Extension   string
+	MaxSize     int64 // bytes
+	MaxPixels   int64 // width times height, zero for vector images which have no pixel size
+}
+
+// The allowlist of uploads. Animated GIFs decode every frame at full size, so they get a smaller budget.
+var (
+	JPEG = Type{ContentType: "image/jpeg", Extension: ".jpeg", MaxSize: 20 << 20, MaxPixels: 40_000_000}
+	PNG  = Type{ContentType: "image/png", Extension: ".png", MaxSize: 20 << 20, MaxPixels: 40_000_000}
+	WebP = Type{ContentType: "image/webp", Extension: ".webp", MaxSize: 20 << 20, MaxPixels: 40_000_000}
+	AVIF = Type{ContentType: "image/avif", Extension: ".avif", MaxSize: 20 << 20, MaxPixels: 40_000_000}
+	GIF  = Type{ContentType: "image/gif", Extension: ".gif", MaxSize: 10 << 20, MaxPixels: 10_000_000}
+	SVG  = Type{ContentType: "image/svg+xml", Extension: ".svg", MaxSize: 1 << 20}
+)
+
+// Types lists every type that may be uploaded
+var Types = []Type{JPEG, PNG, WebP, AVIF, GIF, SVG}
+
+// MaxSize is the largest size any type allows
+func MaxSize() int64 {
+	var max int64
+	for _, t := range Types {
+		if t.MaxSize > max {
+			max = t.MaxSize
+		}
+	}
+	return max
+}
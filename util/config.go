@@ -31,6 +31,13 @@ type Config struct {
 	EmailSenderName         string        `mapstructure:"EMAIL_SENDER_NAME"`
 	EmailSenderAddress      string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
 	EmailSenderPassword     string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	SchedulerInterval       time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	TrashRetentionDays      int           `mapstructure:"TRASH_RETENTION_DAYS"`
+	BlogLockDuration        time.Duration `mapstructure:"BLOG_LOCK_DURATION"`
+	StorageBackend          string        `mapstructure:"STORAGE_BACKEND"`        // minio, filesystem or memory
+	StoragePath             string        `mapstructure:"STORAGE_PATH"`           // directory of the filesystem backend
+	StoragePublicURL        string        `mapstructure:"STORAGE_PUBLIC_URL"`     // base URL of stored files, defaults to MINIO_URL_RESULT or /api/storage/
+	SearchThaiDictionary    string        `mapstructure:"SEARCH_THAI_DICTIONARY"` // word list added to the embedded thai dictionary
 }
 
 // LoadConfig reads configuration from file or environment variables.
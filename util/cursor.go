@@ -0,0 +1,32 @@
+package util
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor builds an opaque pagination cursor pointing just after the row with the given time and ID.
+// Time is kept to the microsecond, the precision PostgreSQL stores.
+func EncodeCursor(t time.Time, id int64) string {
+	raw := fmt.Sprintf("%d:%d", t.UnixMicro(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor reads a cursor built by EncodeCursor
+func DecodeCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	var micro, id int64
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &micro, &id); err != nil || id <= 0 {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	return time.UnixMicro(micro), id, nil
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	times := []time.Time{
+		time.Date(2026, 3, 1, 9, 30, 15, 123456000, time.UTC),
+		time.Date(1999, 12, 31, 23, 59, 59, 0, time.FixedZone("ICT", 7*60*60)),
+		time.UnixMicro(0),
+	}
+
+	for _, want := range times {
+		for _, wantID := range []int64{1, 42, 1 << 40} {
+			got, gotID, err := DecodeCursor(EncodeCursor(want, wantID))
+			if err != nil {
+				t.Fatalf("DecodeCursor(EncodeCursor(%v, %d)): %v", want, wantID, err)
+			}
+			if !got.Equal(want) || gotID != wantID {
+				t.Errorf("round trip of (%v, %d) = (%v, %d)", want, wantID, got, gotID)
+			}
+		}
+	}
+}
+
+func TestCursorKeepsMicroseconds(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 123456789, time.UTC)
+
+	got, _, err := DecodeCursor(EncodeCursor(at, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := at.Truncate(time.Microsecond); !got.Equal(want) {
+		t.Errorf("decoded %v, want %v", got, want)
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	for _, cursor := range []string{
+		"",
+		"not base64!",
+		"aGVsbG8",                   // hello
+		EncodeCursor(time.Now(), 0), // ids start at 1
+		EncodeCursor(time.Now(), -5),
+	} {
+		if _, _, err := DecodeCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package util
+
+import "strings"
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine is a single line of a line-level diff.
+// OldLine and NewLine are 1-based line numbers, 0 when the line does not exist on that side.
+type DiffLine struct {
+	Type    string `json:"type"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line"`
+	NewLine int    `json:"new_line"`
+}
+
+// maxDiffEdits bounds the edit script DiffLines searches for. The trace it keeps grows with the square of the
+// number of edits, texts further apart than this are shown as replaced as a whole instead.
+const maxDiffEdits = 1000
+
+// DiffLines computes a line-level diff from oldText to newText using Myers' algorithm.
+func DiffLines(oldText string, newText string) []DiffLine {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+	n, m := len(a), len(b)
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	// Find the shortest edit script. For every edit distance d only the diagonals -d-1..d+1
+	// of v are kept, those are all the walk back reads.
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		if d > maxDiffEdits {
+			return replaceLines(a, b)
+		}
+
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var lines []DiffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		// The snapshot of d starts at diagonal -d-1
+		v := trace[d]
+		base := d + 1
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[base+k-1] < v[base+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[base+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, DiffLine{Type: DiffEqual, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, DiffLine{Type: DiffInsert, Text: b[y-1], NewLine: y})
+			} else {
+				lines = append(lines, DiffLine{Type: DiffDelete, Text: a[x-1], OldLine: x})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	return lines
+}
+
+// replaceLines is the diff that deletes every old line and inserts every new one
+func replaceLines(a []string, b []string) []DiffLine {
+	lines := make([]DiffLine, 0, len(a)+len(b))
+	for i, text := range a {
+		lines = append(lines, DiffLine{Type: DiffDelete, Text: text, OldLine: i + 1})
+	}
+	for i, text := range b {
+		lines = append(lines, DiffLine{Type: DiffInsert, Text: text, NewLine: i + 1})
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
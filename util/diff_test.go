@@ -0,0 +1,87 @@
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    []DiffLine
+	}{
+		{"both empty", "", "", nil},
+		{"from empty", "", "a\nb", []DiffLine{
+			{Type: DiffInsert, Text: "a", NewLine: 1},
+			{Type: DiffInsert, Text: "b", NewLine: 2},
+		}},
+		{"to empty", "a\nb\n", "", []DiffLine{
+			{Type: DiffDelete, Text: "a", OldLine: 1},
+			{Type: DiffDelete, Text: "b", OldLine: 2},
+		}},
+		{"identical", "a\r\nb\r\n", "a\nb", []DiffLine{
+			{Type: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+			{Type: DiffEqual, Text: "b", OldLine: 2, NewLine: 2},
+		}},
+		{"insert only", "a\nc", "a\nb\nc\nd", []DiffLine{
+			{Type: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+			{Type: DiffInsert, Text: "b", NewLine: 2},
+			{Type: DiffEqual, Text: "c", OldLine: 2, NewLine: 3},
+			{Type: DiffInsert, Text: "d", NewLine: 4},
+		}},
+		{"replace a line", "a\nb\nc", "a\nx\nc", []DiffLine{
+			{Type: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+			{Type: DiffDelete, Text: "b", OldLine: 2},
+			{Type: DiffInsert, Text: "x", NewLine: 2},
+			{Type: DiffEqual, Text: "c", OldLine: 3, NewLine: 3},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := DiffLines(test.oldText, test.newText)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DiffLines(%q, %q) = %+v, want %+v", test.oldText, test.newText, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesOverEditLimit(t *testing.T) {
+	oldLines := make([]string, maxDiffEdits)
+	newLines := make([]string, maxDiffEdits)
+	for i := range oldLines {
+		oldLines[i] = fmt.Sprintf("old %d", i)
+		newLines[i] = fmt.Sprintf("new %d", i)
+	}
+
+	got := DiffLines(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+	want := replaceLines(oldLines, newLines)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("texts %d edits apart are not replaced as a whole", 2*maxDiffEdits)
+	}
+}
+
+// Texts within the limit keep their common lines however long they are
+func TestDiffLinesUnderEditLimit(t *testing.T) {
+	lines := make([]string, 3*maxDiffEdits)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	changed := append([]string(nil), lines...)
+	changed[len(changed)/2] = "changed"
+
+	equal := 0
+	for _, line := range DiffLines(strings.Join(lines, "\n"), strings.Join(changed, "\n")) {
+		if line.Type == DiffEqual {
+			equal++
+		}
+	}
+	if want := len(lines) - 1; equal != want {
+		t.Errorf("%d equal lines, want %d", equal, want)
+	}
+}